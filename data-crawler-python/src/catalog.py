@@ -0,0 +1,75 @@
+"""
+Query library over crawled places.
+Lets other smart-dine services match user-submitted restaurants against
+crawled records without knowing the storage layout.
+
+Usage:
+    catalog = PlacesCatalog(mongodb_client)
+    place = catalog.find_by_cid('7563837329588982612')
+    matches = catalog.find_by_name('Rich Table', near=(37.7743, -122.4213))
+"""
+
+import logging
+import re
+from datetime import datetime
+from typing import Dict, List, Optional, Tuple
+
+from src.database.mongodb import MongoDBClient
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_MATCH_DISTANCE_KM = 1.0
+
+def normalize_name(name: str) -> str:
+    """Lowercase a name and strip punctuation and extra whitespace."""
+    name = re.sub(r'[^\w\s]', ' ', name.lower())
+    return re.sub(r'\s+', ' ', name).strip()
+
+class PlacesCatalog:
+    """Lookups of crawled places by CID or by name and location."""
+
+    def __init__(self, mongodb_client: MongoDBClient):
+        self.restaurants = mongodb_client.restaurants
+
+    def find_by_cid(self, cid: str) -> Optional[Dict]:
+        """Return the place with the given Google Maps CID."""
+        return self.restaurants.find_one({"cid": str(cid)})
+
+    def find_by_name(self, name: str, near: Optional[Tuple[float, float]] = None,
+                     max_distance_km: float = DEFAULT_MATCH_DISTANCE_KM, limit: int = 10) -> List[Dict]:
+        """Return places whose normalized name contains `name`, closest first when `near` (lat, lng) is given."""
+        words = normalize_name(name).split()
+        if not words:
+            return []
+        # Match every word in order, ignoring punctuation between them
+        pattern = r'[\W_]*'.join(re.escape(word) for word in words)
+        query = {"name": {"$regex": pattern, "$options": "i"}}
+        if near:
+            lat, lng = near
+            query["location.coordinates"] = {
+                "$near": {
+                    "$geometry": {"type": "Point", "coordinates": [lng, lat]},
+                    "$maxDistance": max_distance_km * 1000
+                }
+            }
+        return list(self.restaurants.find(query).limit(limit))
+
+    def find_nearby(self, near: Tuple[float, float], max_distance_km: float,
+                    query: Optional[Dict] = None, limit: int = 100) -> List[Dict]:
+        """Return places matching `query` within `max_distance_km` of `near` (lat, lng), closest first."""
+        lat, lng = near
+        query = dict(query or {})
+        query["location.coordinates"] = {
+            "$near": {
+                "$geometry": {"type": "Point", "coordinates": [lng, lat]},
+                "$maxDistance": max_distance_km * 1000
+            }
+        }
+        return list(self.restaurants.find(query).limit(limit))
+
+    def last_updated(self, urls: List[str]) -> Dict[str, Optional[datetime]]:
+        """Return when each of `urls` was last saved, None for places never saved or saved before tracking."""
+        updated = {url: None for url in urls}
+        for place in self.restaurants.find({"url": {"$in": list(urls)}}, {"url": 1, "updated_at": 1}):
+            updated[place['url']] = place.get('updated_at')
+        return updated
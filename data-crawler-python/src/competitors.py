@@ -0,0 +1,181 @@
+"""
+Competitor set builder.
+Given a seed place, collects the places of the same cuisine and a similar
+price level within a radius, from the catalog plus a targeted search around
+the seed, and writes a comparison report for restaurant owners.
+
+Usage:
+    python -m src.competitors --cid 7563837329588982612 --radius-km 2
+"""
+
+import argparse
+import csv
+import logging
+import os
+import sys
+from typing import Dict, List, Optional
+from urllib.parse import quote_plus
+
+from src.catalog import PlacesCatalog
+from src.config.settings import settings
+from src.crawler.google_maps_crawler import GoogleMapsScraper
+from src.database.mongodb import MongoDBClient
+from src.enrichment.cuisine import is_generic
+from src.enrichment.geo import haversine_m, lat_lng
+from src.main import build_storage, configure_logging
+from src.models.job_context import JobContext
+from src.storage.idempotency import extract_cid
+from src.storage.ids import build_id_strategy
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_RADIUS_KM = 2.0
+PRICE_LEVEL_TOLERANCE = 1
+SEARCH_ZOOM = 15
+
+REPORT_COLUMNS = [
+    'name', 'cid', 'distance_km', 'cuisine', 'price_level',
+    'overall_rating', 'total_reviews', 'rating_delta', 'url',
+]
+
+def cuisine_of(place: Dict) -> Optional[str]:
+    """Return the cuisine of a place, preferring the inferred one over a generic category."""
+    attributes = place.get('attributes') or {}
+    categories = attributes.get('cuisine_type') or []
+    if attributes.get('inferred_cuisine'):
+        return attributes['inferred_cuisine']
+    if not is_generic(categories):
+        return categories[0]
+    return None
+
+def similar_price(seed: Dict, place: Dict) -> bool:
+    """Return True if the price levels are within tolerance, or either is unknown."""
+    seed_level = (seed.get('attributes') or {}).get('price_level')
+    level = (place.get('attributes') or {}).get('price_level')
+    if seed_level is None or level is None:
+        return True
+    return abs(seed_level - level) <= PRICE_LEVEL_TOLERANCE
+
+def search_url(cuisine: str, lat: float, lng: float) -> str:
+    """Build a Maps search URL for a cuisine centred on a point."""
+    return f"https://www.google.com/maps/search/{quote_plus(f'{cuisine} restaurant')}/@{lat},{lng},{SEARCH_ZOOM}z"
+
+def crawl_area(scraper: GoogleMapsScraper, storage, cuisine: str, near, known: set, max_results: int) -> int:
+    """Crawl search results around the seed that are not in the catalog yet."""
+    crawled = 0
+    for card in scraper.iter_search_cards(search_url(cuisine, *near), max_results):
+        key = card['cid'] or card['url']
+        if key in known:
+            continue
+        scraper.cards[key] = card
+        restaurant = scraper.get_account(card['url']).get('restaurant', {})
+        if restaurant.get('_id'):
+            storage.upsert_restaurant(restaurant)
+            known.add(key)
+            crawled += 1
+    logger.info(f"Crawled {crawled} places missing from the catalog")
+    return crawled
+
+def build_competitor_set(catalog: PlacesCatalog, seed: Dict, radius_km: float) -> List[Dict]:
+    """Return report rows for the seed's competitors, closest first."""
+    near = lat_lng(seed)
+    cuisine = cuisine_of(seed)
+    rows = []
+    for place in catalog.find_nearby(near, radius_km):
+        if place.get('_id') == seed.get('_id') or cuisine_of(place) != cuisine or not similar_price(seed, place):
+            continue
+        location = lat_lng(place)
+        rating = place.get('overall_rating')
+        rows.append({
+            'name': place.get('name'),
+            'cid': place.get('cid'),
+            'distance_km': round(haversine_m(*near, *location) / 1000, 2) if location else None,
+            'cuisine': cuisine,
+            'price_level': (place.get('attributes') or {}).get('price_level'),
+            'overall_rating': rating,
+            'total_reviews': place.get('total_reviews'),
+            'rating_delta': round(rating - seed['overall_rating'], 2)
+                            if rating is not None and seed.get('overall_rating') is not None else None,
+            'url': place.get('url'),
+        })
+    return rows
+
+def write_report(path: str, seed: Dict, rows: List[Dict]):
+    """Write the comparison report with the seed as the first row."""
+    seed_row = {
+        'name': seed.get('name'),
+        'cid': seed.get('cid'),
+        'distance_km': 0,
+        'cuisine': cuisine_of(seed),
+        'price_level': (seed.get('attributes') or {}).get('price_level'),
+        'overall_rating': seed.get('overall_rating'),
+        'total_reviews': seed.get('total_reviews'),
+        'rating_delta': 0,
+        'url': seed.get('url'),
+    }
+    with open(path, 'w', newline='', encoding='utf-8') as f:
+        writer = csv.DictWriter(f, fieldnames=REPORT_COLUMNS)
+        writer.writeheader()
+        writer.writerow(seed_row)
+        writer.writerows(rows)
+
+def main():
+    configure_logging()
+    parser = argparse.ArgumentParser(description='Build the competitor set of a restaurant.')
+    seed_group = parser.add_mutually_exclusive_group(required=True)
+    seed_group.add_argument('--cid', help='CID of the seed place')
+    seed_group.add_argument('--url', help='Google Maps URL of the seed place')
+    parser.add_argument('--radius-km', type=float, default=DEFAULT_RADIUS_KM, help='Search radius around the seed')
+    parser.add_argument('--max-results', type=int, default=40, help='Maximum search results to crawl')
+    parser.add_argument('--no-crawl', action='store_true', help='Use the catalog only')
+    args = parser.parse_args()
+
+    try:
+        mongodb = MongoDBClient(
+            mongodb_url=settings.MONGODB_URL,
+            db_name=settings.MONGODB_DB,
+            collection_restaurants=settings.MONGODB_COLLECTION_RESTAURANTS,
+            collection_reviews=settings.MONGODB_COLLECTION_REVIEWS
+        )
+        catalog = PlacesCatalog(mongodb)
+        storage = build_storage()
+        job = JobContext(tenant=settings.tenant, proxy=settings.proxy)
+
+        cid = args.cid or extract_cid(args.url)
+        seed = catalog.find_by_cid(cid) if cid else None
+        with GoogleMapsScraper(debug=settings.headful, devtools=settings.devtools, job=job, id_strategy=build_id_strategy(settings.id_strategy)) as scraper:
+            if not seed:
+                if not args.url:
+                    raise ValueError(f"Place {cid} is not in the catalog, pass --url to crawl it")
+                seed = scraper.get_account(args.url).get('restaurant', {})
+                if not seed.get('_id'):
+                    raise ValueError(f"Could not scrape seed place {args.url}")
+                storage.upsert_restaurant(seed)
+
+            near = lat_lng(seed)
+            cuisine = cuisine_of(seed)
+            if not near or not cuisine:
+                raise ValueError(f"Seed place {seed.get('name')} has no coordinates or cuisine")
+            logger.info(f"Building {cuisine} competitor set within {args.radius_km} km of {seed.get('name')}")
+
+            if not args.no_crawl:
+                known = {p.get('cid') or p.get('url') for p in catalog.find_nearby(near, args.radius_km)}
+                crawl_area(scraper, storage, cuisine, near, known, args.max_results)
+        storage.close()
+
+        rows = build_competitor_set(catalog, seed, args.radius_km)
+        os.makedirs(settings.output_dir, exist_ok=True)
+        path = os.path.join(settings.output_dir, f"competitors_{seed.get('cid') or seed['_id']}.csv")
+        write_report(path, seed, rows)
+
+        rated = sorted([r for r in rows if r['overall_rating'] is not None] + [seed],
+                       key=lambda p: p.get('overall_rating') or 0, reverse=True)
+        rank = next(i for i, p in enumerate(rated, 1) if p is seed)
+        logger.info(f"{len(rows)} competitors found, {seed.get('name')} ranks {rank} of {len(rated)} by rating")
+        logger.info(f"Report written to {path}")
+    except Exception as e:
+        logger.error(f"Competitor set failed: {str(e)}")
+        sys.exit(1)
+
+if __name__ == "__main__":
+    main()
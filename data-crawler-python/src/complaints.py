@@ -0,0 +1,120 @@
+"""
+Complaints summary export.
+Builds a CSV of the top negative keywords per place, each with an example
+review quote and its date, from the reviews already stored in MongoDB so
+operations teams get it without re-crawling.
+
+Usage:
+    python -m src.complaints --output data/complaints.csv
+"""
+
+import argparse
+import csv
+import logging
+import re
+import sys
+from collections import Counter
+from typing import Dict, Iterable, List
+
+from src.config.settings import settings
+from src.database.mongodb import MongoDBClient
+from src.enrichment.dates import age_in_days
+from src.enrichment.text import snippet
+from src.main import configure_logging
+
+logger = logging.getLogger(__name__)
+
+NEGATIVE_MAX_RATING = 2
+TOP_KEYWORDS = 5
+
+COLUMNS = ['place_id', 'place_name', 'keyword', 'mentions', 'example_quote', 'example_date', 'example_rating']
+
+STOPWORDS = {
+    'a', 'about', 'after', 'again', 'all', 'also', 'am', 'an', 'and', 'any', 'are', 'as', 'at', 'be',
+    'because', 'been', 'before', 'being', 'but', 'by', 'came', 'can', 'come', 'could', 'did', 'do',
+    'does', 'don', 'even', 'ever', 'for', 'from', 'get', 'got', 'had', 'has', 'have', 'he', 'her',
+    'here', 'him', 'his', 'how', 'i', 'if', 'in', 'into', 'is', 'it', 'its', 'just', 'like', 'me',
+    'more', 'most', 'my', 'no', 'not', 'now', 'of', 'on', 'one', 'only', 'or', 'other', 'our', 'out',
+    'over', 'place', 'restaurant', 're', 'really', 's', 'said', 'she', 'so', 'some', 'than', 'that',
+    'the', 'their', 'them', 'then', 'there', 'they', 'this', 'to', 'too', 't', 'up', 'us', 'very',
+    'was', 'we', 'went', 'were', 'what', 'when', 'which', 'while', 'who', 'will', 'with', 'would',
+    'you', 'your', 'food', 'time', 'back', 'go', 'order', 'ordered', 'never', 'didn', 'wasn',
+}
+
+def keywords(text: str) -> List[str]:
+    """Return the distinct content words of a review."""
+    words = re.findall(r"[a-z]+", (text or '').lower())
+    return sorted({w for w in words if len(w) > 2 and w not in STOPWORDS})
+
+def top_complaints(reviews: Iterable[Dict], limit: int = TOP_KEYWORDS) -> List[Dict]:
+    """Return the most frequent keywords of negative reviews with the most recent example quote."""
+    counts = Counter()
+    examples = {}
+    for review in reviews:
+        rating = review.get('rating')
+        if rating is None or rating > NEGATIVE_MAX_RATING:
+            continue
+        text = review.get('text') or ''
+        for word in keywords(text):
+            counts[word] += 1
+            # Keep the most recent quote, dates are relative like "3 weeks ago"
+            age = age_in_days(review.get('date'))
+            previous = examples.get(word)
+            if previous is None or (age is not None and (previous[0] is None or age < previous[0])):
+                examples[word] = (age, review)
+
+    complaints = []
+    for word, mentions in counts.most_common(limit):
+        review = examples[word][1]
+        text = review.get('text') or ''
+        match = re.search(rf'\b{word}\b', text, re.I)
+        complaints.append({
+            'keyword': word,
+            'mentions': mentions,
+            'example_quote': snippet(text, match.start(), match.end()) if match else text,
+            'example_date': review.get('date'),
+            'example_rating': review.get('rating'),
+        })
+    return complaints
+
+def export_complaints(mongodb: MongoDBClient, path: str, limit: int = TOP_KEYWORDS) -> int:
+    """Write the complaints CSV and return the number of places covered."""
+    places = 0
+    with open(path, 'w', newline='', encoding='utf-8') as f:
+        writer = csv.DictWriter(f, fieldnames=COLUMNS)
+        writer.writeheader()
+        for place in mongodb.restaurants.find({}, {'name': 1}):
+            reviews = mongodb.reviews.find(
+                {'restaurant_id': place['_id'], 'rating': {'$lte': NEGATIVE_MAX_RATING}},
+                {'text': 1, 'date': 1, 'rating': 1}
+            )
+            complaints = top_complaints(reviews, limit)
+            if not complaints:
+                continue
+            places += 1
+            for complaint in complaints:
+                writer.writerow({'place_id': place['_id'], 'place_name': place.get('name'), **complaint})
+    return places
+
+def main():
+    configure_logging()
+    parser = argparse.ArgumentParser(description='Export top review complaints per place as CSV.')
+    parser.add_argument('--output', default='complaints.csv', help='CSV file to write')
+    parser.add_argument('--top', type=int, default=TOP_KEYWORDS, help='Keywords per place')
+    args = parser.parse_args()
+
+    try:
+        mongodb = MongoDBClient(
+            mongodb_url=settings.MONGODB_URL,
+            db_name=settings.MONGODB_DB,
+            collection_restaurants=settings.MONGODB_COLLECTION_RESTAURANTS,
+            collection_reviews=settings.MONGODB_COLLECTION_REVIEWS
+        )
+        places = export_complaints(mongodb, args.output, args.top)
+        logger.info(f"Wrote complaints for {places} places to {args.output}")
+    except Exception as e:
+        logger.error(f"Complaints export failed: {str(e)}")
+        sys.exit(1)
+
+if __name__ == "__main__":
+    main()
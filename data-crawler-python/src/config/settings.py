@@ -15,13 +15,146 @@ class Settings:
         self.MONGODB_DB = os.getenv('CRAWLER_MONGODB_DB', 'smartdine')
         self.MONGODB_COLLECTION_RESTAURANTS = os.getenv('CRAWLER_MONGODB_COLLECTION_RESTAURANTS', 'restaurants')
         self.MONGODB_COLLECTION_REVIEWS = os.getenv('CRAWLER_MONGODB_COLLECTION_REVIEWS', 'reviews')
+        self.MONGODB_COLLECTION_RAW = os.getenv('CRAWLER_MONGODB_COLLECTION_RAW', 'raw_places')
+        self.raw_ttl_seconds = int(os.getenv('CRAWLER_RAW_TTL_SECONDS')) if os.getenv('CRAWLER_RAW_TTL_SECONDS') else None
+        
+        # PostgreSQL settings
+        self.postgres_dsn = os.getenv('CRAWLER_POSTGRES_DSN', 'postgresql://localhost:5432/smartdine')
+        
+        # Kafka settings
+        self.kafka_bootstrap_servers = os.getenv('CRAWLER_KAFKA_BOOTSTRAP_SERVERS', 'localhost:9092')
+        self.kafka_places_topic = os.getenv('CRAWLER_KAFKA_PLACES_TOPIC', 'crawler.places')
+        self.kafka_reviews_topic = os.getenv('CRAWLER_KAFKA_REVIEWS_TOPIC')
+        
+        # Output settings
+        self.id_strategy = os.getenv('CRAWLER_ID_STRATEGY', 'stable')
+        self.sinks = [s.strip() for s in os.getenv('CRAWLER_SINKS', 'mongodb').split(',') if s.strip()]
+        self.output_dir = os.getenv('CRAWLER_OUTPUT_DIR', 'data')
+        self.transform_config = os.getenv('CRAWLER_TRANSFORM_CONFIG')
+        self.redaction_config = os.getenv('CRAWLER_REDACTION_CONFIG')
+        self.redact_fields = [f.strip() for f in os.getenv('CRAWLER_REDACT_FIELDS', '').split(',') if f.strip()]
+        # Merge places with their versions from earlier runs, tracked in this index file
+        self.dedupe = os.getenv('CRAWLER_DEDUPE', 'false').lower() == 'true'
+        self.dedupe_index = os.getenv('CRAWLER_DEDUPE_INDEX')
+        self.cuisine_classifier_url = os.getenv('CRAWLER_CUISINE_CLASSIFIER_URL')
+        self.photo_classifier_url = os.getenv('CRAWLER_PHOTO_CLASSIFIER_URL')
+        self.food_inspection_url = os.getenv('CRAWLER_FOOD_INSPECTION_URL')
+        # Fill phone, website, hours and place_id the scraper missed from the Google Places API
+        self.places_api_key = os.getenv('CRAWLER_PLACES_API_KEY')
+        # OpenStreetMap amenities fetched by python -m src.osm, and the Overpass endpoint to ask
+        self.overpass_url = os.getenv('CRAWLER_OVERPASS_URL', 'https://overpass-api.de/api/interpreter')
+        self.osm_amenities = [a.strip() for a in os.getenv('CRAWLER_OSM_AMENITIES', 'restaurant').split(',') if a.strip()]
+        self.boundaries_file = os.getenv('CRAWLER_BOUNDARIES_FILE')
+        self.media_dir = os.getenv('CRAWLER_MEDIA_DIR')
+        self.media_s3_bucket = os.getenv('CRAWLER_MEDIA_S3_BUCKET')
+        self.media_s3_prefix = os.getenv('CRAWLER_MEDIA_S3_PREFIX', 'media')
+        self.media_workers = int(os.getenv('CRAWLER_MEDIA_WORKERS', '8'))
+        self.photo_size = os.getenv('CRAWLER_PHOTO_SIZE', 'w1200')
+        self.selectors_file = os.getenv('CRAWLER_SELECTORS_FILE')
+        # Maps interface language and region, e.g. "de" and "DE"
+        self.hl = os.getenv('CRAWLER_HL')
+        self.gl = os.getenv('CRAWLER_GL')
+        # Cookie consent wall: accept, reject, cookie (pre-set consent cookies) or off
+        self.consent = os.getenv('CRAWLER_CONSENT', 'accept')
+        self.scrape_menus = os.getenv('CRAWLER_SCRAPE_MENUS', 'false').lower() == 'true'
+        # Place fields to scrape, e.g. "reviews,hours", or a depth: basic, standard or full (the default)
+        self.fields = os.getenv('CRAWLER_FIELDS')
+        self.depth = os.getenv('CRAWLER_DEPTH')
+        self.crawl_websites = os.getenv('CRAWLER_CRAWL_WEBSITES', 'false').lower() == 'true'
+        self.website_requests_per_minute = float(os.getenv('CRAWLER_WEBSITE_REQUESTS_PER_MINUTE', '30'))
+        self.website_max_pages = int(os.getenv('CRAWLER_WEBSITE_MAX_PAGES', '4'))
+        self.keep_days = int(os.getenv('CRAWLER_KEEP_DAYS')) if os.getenv('CRAWLER_KEEP_DAYS') else None
+        self.keep_runs = int(os.getenv('CRAWLER_KEEP_RUNS')) if os.getenv('CRAWLER_KEEP_RUNS') else None
         
         # Crawler settings
         self.area = os.getenv('CRAWLER_AREA', 'San Francisco, CA')
+        # Whether the area was configured rather than defaulted
+        self.area_set = bool(os.getenv('CRAWLER_AREA'))
+        self.search_url = os.getenv('CRAWLER_SEARCH_URL')
+        self.search_query = os.getenv('CRAWLER_SEARCH_QUERY', 'restaurants')
+        self.rephrase_sparse = os.getenv('CRAWLER_REPHRASE_SPARSE', 'true').lower() == 'true'
+        self.rephrase_min_results = int(os.getenv('CRAWLER_REPHRASE_MIN_RESULTS', '20'))
+        self.rephrase_phrasings = os.getenv('CRAWLER_REPHRASE_PHRASINGS')
+        self.grid_bbox = os.getenv('CRAWLER_GRID_BBOX')
+        self.grid_center = os.getenv('CRAWLER_GRID_CENTER')
+        self.grid_cell_km = float(os.getenv('CRAWLER_GRID_CELL_KM', '1'))
+        self.resume = os.getenv('CRAWLER_RESUME')
+        # Comma-separated URLs notified when a job completes or fails, signed with the secret
+        self.webhook_urls = [u.strip() for u in os.getenv('CRAWLER_WEBHOOK_URLS', '').split(',') if u.strip()]
+        self.webhook_secret = os.getenv('CRAWLER_WEBHOOK_SECRET')
+        # Seconds running places get to finish after SIGINT/SIGTERM
+        self.shutdown_grace = float(os.getenv('CRAWLER_SHUTDOWN_GRACE', '60'))
+        # Bearer token the REST API requires; without it the API only listens on localhost
+        self.api_token = os.getenv('CRAWLER_API_TOKEN')
+        # Seconds finished REST API jobs are kept for polling before they are forgotten
+        self.job_retention = float(os.getenv('CRAWLER_JOB_RETENTION', '3600'))
+        self.parallel_reviews = os.getenv('CRAWLER_PARALLEL_REVIEWS', 'false').lower() == 'true'
         self.radius_km = float(os.getenv('CRAWLER_RADIUS_KM', '5'))
         self.max_restaurants = int(os.getenv('CRAWLER_MAX_RESTAURANTS', '1'))
         self.max_reviews_per_restaurant = int(os.getenv('CRAWLER_MAX_REVIEWS_PER_RESTAURANT', '20'))
-        self.min_rating = float(os.getenv('CRAWLER_MIN_RATING', '4.0'))
+        # Search results below these are skipped; 0 keeps every result
+        self.min_rating = float(os.getenv('CRAWLER_MIN_RATING', '0'))
+        self.min_reviews = int(os.getenv('CRAWLER_MIN_REVIEWS', '0'))
+        # Comma-separated category terms, e.g. "Italian" or "Fast food", checked on the search cards
+        self.include_categories = [c.strip() for c in os.getenv('CRAWLER_INCLUDE_CATEGORIES', '').split(',') if c.strip()]
+        self.exclude_categories = [c.strip() for c in os.getenv('CRAWLER_EXCLUDE_CATEGORIES', '').split(',') if c.strip()]
+        # Places scraped per run across every search, grid cell and phrasing; unlimited when unset
+        self.max_places = int(os.getenv('CRAWLER_MAX_PLACES')) if os.getenv('CRAWLER_MAX_PLACES') else None
+        self.tenant = os.getenv('CRAWLER_TENANT')
+        self.proxy = os.getenv('CRAWLER_PROXY')
+        self.proxies = [p.strip() for p in os.getenv('CRAWLER_PROXIES', '').split(',') if p.strip()]
+        self.proxy_file = os.getenv('CRAWLER_PROXY_FILE')
+        self.block_handler = os.getenv('CRAWLER_BLOCK_HANDLER', 'backoff')
+        self.captcha_solver_url = os.getenv('CRAWLER_CAPTCHA_SOLVER_URL')
+        self.stealth = os.getenv('CRAWLER_STEALTH', 'false').lower() == 'true'
+        self.identified = os.getenv('CRAWLER_IDENTIFIED', 'false').lower() == 'true'
+        self.identified_agent = os.getenv('CRAWLER_IDENTIFIED_AGENT', 'SmartDineBot/1.0')
+        self.contact_url = os.getenv('CRAWLER_CONTACT_URL')
+        # Record every browser call of a session as a cassette under this directory
+        self.record_dir = os.getenv('CRAWLER_RECORD_DIR')
+        # Answer browser calls from this recorded cassette instead of Chrome
+        self.replay_file = os.getenv('CRAWLER_REPLAY_FILE')
+        # Save the DOM of every parsed page under this directory as test fixtures
+        self.fixture_dir = os.getenv('CRAWLER_FIXTURE_DIR')
+        # Serve pages from fixtures saved under this directory instead of Chrome
+        self.replay_fixtures = os.getenv('CRAWLER_REPLAY_FIXTURES')
+        # Save a screenshot, the HTML, the URL and the console log of every failed place
+        self.debug_artifacts = os.getenv('CRAWLER_DEBUG_ARTIFACTS', 'false').lower() == 'true'
+        self.failure_dir = os.path.join(self.output_dir, 'failures') if self.debug_artifacts else None
+        self.concurrency = int(os.getenv('CRAWLER_CONCURRENCY', '1'))
+        self.max_browsers = int(os.getenv('CRAWLER_MAX_BROWSERS', '2'))
+        self.domain_rate_limits = os.getenv('CRAWLER_DOMAIN_RATE_LIMITS', 'www.google.com=30/min')
+        self.browser_max_jobs = int(os.getenv('CRAWLER_BROWSER_MAX_JOBS', '50'))
+        self.browser_max_minutes = float(os.getenv('CRAWLER_BROWSER_MAX_MINUTES', '30'))
+        self.progress_interval = float(os.getenv('CRAWLER_PROGRESS_INTERVAL', '5'))
+        # Export OpenTelemetry spans over OTLP (endpoint from OTEL_EXPORTER_OTLP_ENDPOINT)
+        self.tracing = os.getenv('CRAWLER_TRACING', 'false').lower() == 'true'
+        self.throttle_min_delay = float(os.getenv('CRAWLER_THROTTLE_MIN_DELAY', '1'))
+        self.throttle_max_delay = float(os.getenv('CRAWLER_THROTTLE_MAX_DELAY', '60'))
+        self.cost_proxy_per_gb = float(os.getenv('CRAWLER_COST_PROXY_PER_GB', '0'))
+        self.cost_captcha_per_solve = float(os.getenv('CRAWLER_COST_CAPTCHA_PER_SOLVE', '0'))
+        self.cost_browser_per_hour = float(os.getenv('CRAWLER_COST_BROWSER_PER_HOUR', '0'))
+        self.canary = os.getenv('CRAWLER_CANARY', 'true').lower() == 'true'
+        self.feed_stable_window = float(os.getenv('CRAWLER_FEED_STABLE_WINDOW', '2'))
+        # Longest a search keeps scrolling the feed before giving up on reaching its end
+        self.feed_max_seconds = float(os.getenv('CRAWLER_FEED_MAX_SECONDS', '300'))
+        # Open Chrome visibly for selector debugging; with DevTools the panel opens on every tab
+        self.devtools = os.getenv('CRAWLER_DEVTOOLS', 'false').lower() == 'true'
+        self.headful = self.devtools or os.getenv('CRAWLER_HEADFUL', 'false').lower() == 'true'
+        # Multiplies every browser wait timeout and pause, e.g. 2 for slow proxies; debugging sessions default to 3
+        self.slow_mo = float(os.getenv('CRAWLER_SLOW_MO', '3' if self.devtools else '1'))
+        # Parse search result cards from one HTML snapshot per scroll instead of node by node
+        self.feed_snapshots = os.getenv('CRAWLER_FEED_SNAPSHOTS', 'false').lower() == 'true'
+        self.review_scroll_budget = float(os.getenv('CRAWLER_REVIEW_SCROLL_BUDGET', '120'))
+        self.max_reviews = int(os.getenv('CRAWLER_MAX_REVIEWS')) if os.getenv('CRAWLER_MAX_REVIEWS') else None
+        self.incremental_reviews = os.getenv('CRAWLER_INCREMENTAL_REVIEWS', 'false').lower() == 'true'
+
+        # Job queue settings
+        self.redis_url = os.getenv('CRAWLER_REDIS_URL', 'redis://localhost:6379/0')
+        self.queue_name = os.getenv('CRAWLER_QUEUE_NAME', 'crawler')
+        self.job_max_attempts = int(os.getenv('CRAWLER_JOB_MAX_ATTEMPTS', '3'))
+        # Seconds a claimed job stays leased without a heartbeat before another worker may requeue it
+        self.job_lease = float(os.getenv('CRAWLER_JOB_LEASE', '300'))
         
         # Logging settings
         self.log_level = os.getenv('CRAWLER_LOG_LEVEL', 'INFO')
@@ -0,0 +1,52 @@
+"""
+Result-count anomaly detection.
+Keeps a history of result counts per search query and flags runs whose
+count drops far below the historical baseline, which usually means a
+selector broke or Google is blocking us rather than a real-world change.
+"""
+
+import json
+import logging
+import statistics
+from datetime import datetime
+from pathlib import Path
+
+logger = logging.getLogger(__name__)
+
+DROP_THRESHOLD = 0.7
+HISTORY_SIZE = 10
+MIN_HISTORY = 3
+
+class ResultCountHistory:
+    """Historical result counts per query, persisted to a JSON file."""
+
+    def __init__(self, path: str, drop_threshold: float = DROP_THRESHOLD):
+        self.path = Path(path)
+        self.drop_threshold = drop_threshold
+        self.history = {}
+        if self.path.exists():
+            with open(self.path, 'r', encoding='utf-8') as f:
+                self.history = json.load(f)
+
+    def baseline(self, query: str):
+        """Return the median historical count for a query, or None without enough history."""
+        counts = [entry['count'] for entry in self.history.get(query, [])]
+        if len(counts) < MIN_HISTORY:
+            return None
+        return statistics.median(counts)
+
+    def record(self, query: str, count: int) -> bool:
+        """Record a run's result count and return True if it is anomalous."""
+        baseline = self.baseline(query)
+        anomalous = bool(baseline) and count < baseline * (1 - self.drop_threshold)
+        if anomalous:
+            logger.warning(f"Anomalous result count for '{query}': {count} vs baseline {baseline}, check selectors or blocking")
+        else:
+            # Anomalous runs stay out of the history so they don't drag the baseline down
+            entries = self.history.setdefault(query, [])
+            entries.append({'count': count, 'recorded_at': datetime.now().isoformat()})
+            del entries[:-HISTORY_SIZE]
+            self.path.parent.mkdir(parents=True, exist_ok=True)
+            with open(self.path, 'w', encoding='utf-8') as f:
+                json.dump(self.history, f, indent=2)
+        return anomalous
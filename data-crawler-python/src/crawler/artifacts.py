@@ -0,0 +1,106 @@
+"""
+Failure artifacts.
+When a place fails, the browser is captured as it was at the moment of
+failure, one folder per failure bundled under the crawl job:
+
+    failures/<job id>/<time>_<cid or url hash>/screenshot.png
+    failures/<job id>/<time>_<cid or url hash>/page.html
+    failures/<job id>/<time>_<cid or url hash>/failure.json   URLs, error and capture time
+    failures/<job id>/<time>_<cid or url hash>/console.json   browser console log
+
+Each artifact is captured on its own, so a browser that can no longer take
+screenshots still leaves its HTML and URL behind. The console log holds
+the scraper's recent console entries, since streaming them into the
+crawler's log drains the browser's own log.
+
+prune_failures() applies the output retention policy to these folders.
+"""
+
+import json
+import logging
+import os
+import re
+import shutil
+from datetime import datetime, timedelta, timezone
+from typing import Dict, List, Optional
+
+from .fixtures import fixture_key
+
+logger = logging.getLogger(__name__)
+
+def capture_failure(driver, directory: str, job_id: str, url: str, error: Optional[Dict] = None,
+                    console: Optional[List[Dict]] = None) -> Optional[str]:
+    """Save the screenshot, HTML, URL and console log of a failed page; returns the artifact folder."""
+    captured_at = datetime.now(timezone.utc)
+    folder = os.path.join(directory, job_id, f"{captured_at.strftime('%Y%m%dT%H%M%S')}_{fixture_key(url)}")
+    try:
+        os.makedirs(folder, exist_ok=True)
+    except OSError as e:
+        logger.error(f"Could not create failure artifact folder {folder}: {str(e)}")
+        return None
+
+    failure = {'url': url, 'error': error, 'captured_at': captured_at.isoformat()}
+    try:
+        failure['page_url'] = driver.current_url
+    except Exception as e:
+        logger.debug(f"Could not read the page URL: {str(e)}")
+    try:
+        driver.save_screenshot(os.path.join(folder, 'screenshot.png'))
+    except Exception as e:
+        logger.debug(f"Could not take a screenshot: {str(e)}")
+    try:
+        with open(os.path.join(folder, 'page.html'), 'w', encoding='utf-8') as f:
+            f.write(driver.page_source)
+    except Exception as e:
+        logger.debug(f"Could not save the page HTML: {str(e)}")
+    if console is None:
+        try:
+            console = driver.get_log('browser')
+        except Exception as e:
+            logger.debug(f"Could not read the console log: {str(e)}")
+    if console is not None:
+        with open(os.path.join(folder, 'console.json'), 'w', encoding='utf-8') as f:
+            json.dump(console, f, indent=2)
+    with open(os.path.join(folder, 'failure.json'), 'w', encoding='utf-8') as f:
+        json.dump(failure, f, indent=2)
+    logger.info(f"Saved failure artifacts of {url} to {folder}")
+    return folder
+
+def prune_failures(directory: str, keep_days: Optional[int] = None, keep_runs: Optional[int] = None) -> int:
+    """Delete old failure artifacts and return how many failure folders were removed.
+
+    keep_days removes failures captured more than that many days ago;
+    keep_runs keeps only the failures of the newest N crawl jobs.
+    """
+    if not os.path.isdir(directory):
+        return 0
+    cutoff = datetime.now(timezone.utc) - timedelta(days=keep_days) if keep_days is not None else None
+
+    # Failures of every job, with the time they were captured
+    jobs = {}
+    for job_id in os.listdir(directory):
+        job_dir = os.path.join(directory, job_id)
+        if not os.path.isdir(job_dir):
+            continue
+        failures = []
+        for name in os.listdir(job_dir):
+            match = re.match(r'^(\d{8}T\d{6})_', name)
+            if match and os.path.isdir(os.path.join(job_dir, name)):
+                captured_at = datetime.strptime(match.group(1), '%Y%m%dT%H%M%S').replace(tzinfo=timezone.utc)
+                failures.append((captured_at, os.path.join(job_dir, name)))
+        if failures:
+            jobs[job_dir] = failures
+
+    newest_first = sorted(jobs, key=lambda job_dir: max(jobs[job_dir])[0], reverse=True)
+    removed = 0
+    for index, job_dir in enumerate(newest_first):
+        surplus = keep_runs is not None and index >= keep_runs
+        for captured_at, folder in jobs[job_dir]:
+            if surplus or (cutoff is not None and captured_at < cutoff):
+                shutil.rmtree(folder, ignore_errors=True)
+                removed += 1
+        if not os.listdir(job_dir):
+            os.rmdir(job_dir)
+
+    logger.info(f"Pruned {removed} failure artifact folders")
+    return removed
@@ -0,0 +1,107 @@
+"""
+Detection and handling of Google's "unusual traffic" interstitial and
+CAPTCHA pages.
+Handlers are pluggable: back off and retry, rotate to another proxy, or
+hand the challenge to an external solving service.
+"""
+
+import logging
+import time
+
+import requests
+from selenium.webdriver.common.by import By
+
+logger = logging.getLogger(__name__)
+
+BLOCK_MARKERS = [
+    'our systems have detected unusual traffic',
+    'not a robot',
+]
+
+def is_blocked(driver) -> bool:
+    """Return True if the current page is a block interstitial or CAPTCHA."""
+    if '/sorry/' in driver.current_url:
+        return True
+    if driver.find_elements(By.CSS_SELECTOR, 'iframe[src*="recaptcha"]'):
+        return True
+    text = driver.execute_script("return document.body ? document.body.innerText.toLowerCase() : '';") or ''
+    return any(marker in text for marker in BLOCK_MARKERS)
+
+class BlockHandler:
+    """Interface for block handlers."""
+
+    def handle(self, scraper, attempt: int) -> bool:
+        """Deal with a block page; return True if navigation should be retried."""
+        raise NotImplementedError
+
+class BackoffHandler(BlockHandler):
+    """Pause with exponential backoff before retrying."""
+
+    def __init__(self, base_delay: float = 60, max_delay: float = 900):
+        self.base_delay = base_delay
+        self.max_delay = max_delay
+
+    def handle(self, scraper, attempt: int) -> bool:
+        delay = min(self.base_delay * (2 ** (attempt - 1)), self.max_delay)
+        logger.warning(f"Blocked by Google, backing off for {delay}s (attempt {attempt})")
+        time.sleep(delay)
+        return True
+
+class RotateProxyHandler(BlockHandler):
+    """Restart the browser behind another proxy before retrying."""
+
+    def handle(self, scraper, attempt: int) -> bool:
+        if not scraper.proxy_pool:
+            logger.error("Blocked by Google and no proxy pool is configured to rotate")
+            return False
+        scraper.rotate_proxy()
+        return True
+
+class SolverHandler(BlockHandler):
+    """Send the reCAPTCHA to an external solving service and submit its token.
+
+    The service receives {"url", "sitekey"} and must answer {"token"}.
+    """
+
+    def __init__(self, url: str, timeout: float = 180):
+        self.url = url
+        self.timeout = timeout
+
+    def handle(self, scraper, attempt: int) -> bool:
+        driver = scraper.driver
+        sitekeys = driver.find_elements(By.CSS_SELECTOR, '[data-sitekey]')
+        if not sitekeys:
+            logger.error("Blocked by Google but no reCAPTCHA sitekey was found to solve")
+            return False
+        try:
+            response = requests.post(
+                self.url,
+                json={'url': driver.current_url, 'sitekey': sitekeys[0].get_attribute('data-sitekey')},
+                timeout=self.timeout
+            )
+            response.raise_for_status()
+            token = response.json()['token']
+        except Exception as e:
+            logger.error(f"CAPTCHA solving service failed: {str(e)}")
+            return False
+
+        driver.execute_script(
+            "document.getElementById('g-recaptcha-response').value = arguments[0];"
+            "document.querySelector('form').submit();",
+            token
+        )
+        scraper.costs.add_captcha_solve()
+        scraper.waits.for_network_idle(driver)
+        return True
+
+def build_block_handler(name: str, solver_url: str = None) -> BlockHandler:
+    """Create a block handler by name: backoff, rotate or solver."""
+    if name == 'backoff':
+        return BackoffHandler()
+    if name == 'rotate':
+        return RotateProxyHandler()
+    if name == 'solver':
+        if not solver_url:
+            raise ValueError("The solver block handler needs a solver URL")
+        return SolverHandler(solver_url)
+    raise ValueError(f"Unknown block handler: {name}")
@@ -0,0 +1,91 @@
+"""
+Bounded pool of warm browsers.
+Scrapers are created lazily up to the pool size and handed from job to
+job instead of starting a new Chrome per place. A browser is restarted
+after a number of jobs or minutes to keep its memory in check.
+"""
+
+import logging
+import queue
+import threading
+import time
+from contextlib import contextmanager
+from typing import Callable, Dict, Iterator, List
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_POOL_SIZE = 2
+DEFAULT_MAX_JOBS = 50
+DEFAULT_MAX_MINUTES = 30
+
+class BrowserPool:
+    """Hands out scrapers from a fixed-size pool and recycles worn-out browsers."""
+
+    def __init__(self, factory: Callable[[], object], size: int = DEFAULT_POOL_SIZE,
+                 max_jobs: int = DEFAULT_MAX_JOBS, max_minutes: float = DEFAULT_MAX_MINUTES):
+        if size < 1:
+            raise ValueError("Browser pool needs at least one browser")
+        self.factory = factory
+        self.size = size
+        self.max_jobs = max_jobs
+        self.max_age = max_minutes * 60
+        # Search cards shared by every scraper for reconciliation
+        self.cards: Dict[str, Dict] = {}
+        self.scrapers: List[object] = []
+        self._idle: queue.Queue = queue.Queue()
+        self._usage: Dict[int, Dict] = {}
+        self._lock = threading.Lock()
+
+    def __enter__(self):
+        return self
+
+    def __exit__(self, exc_type, exc_value, tb):
+        self.close()
+
+    def acquire(self) -> object:
+        """Return an idle scraper, starting a new one while below the pool size."""
+        try:
+            return self._idle.get_nowait()
+        except queue.Empty:
+            pass
+        with self._lock:
+            if len(self.scrapers) < self.size:
+                scraper = self.factory()
+                scraper.cards = self.cards
+                self.scrapers.append(scraper)
+                self._usage[id(scraper)] = {'jobs': 0, 'started': time.time()}
+                logger.info(f"Started browser {len(self.scrapers)}/{self.size}")
+                return scraper
+        return self._idle.get()
+
+    def release(self, scraper: object):
+        """Return a scraper after a job, restarting its browser when it is due."""
+        usage = self._usage[id(scraper)]
+        usage['jobs'] += 1
+        age = time.time() - usage['started']
+        if usage['jobs'] >= self.max_jobs or age >= self.max_age:
+            logger.info(f"Recycling browser after {usage['jobs']} jobs and {age / 60:.1f} minutes")
+            try:
+                scraper.restart()
+            except Exception as e:
+                logger.error(f"Failed to recycle browser: {str(e)}")
+            usage['jobs'] = 0
+            usage['started'] = time.time()
+        self._idle.put(scraper)
+
+    @contextmanager
+    def browser(self) -> Iterator[object]:
+        """Borrow a scraper for the duration of a job."""
+        scraper = self.acquire()
+        try:
+            yield scraper
+        finally:
+            self.release(scraper)
+
+    def close(self):
+        """Close every browser of the pool."""
+        for scraper in self.scrapers:
+            scraper.__exit__(None, None, None)
+        self.scrapers = []
+        self._usage = {}
+        self._idle = queue.Queue()
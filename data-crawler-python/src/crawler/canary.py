@@ -0,0 +1,52 @@
+"""
+Canary check run before a crawl.
+Scrapes a few known-stable places and verifies that every critical field
+is extracted, so a selector break aborts the run up front instead of
+silently producing empty records.
+"""
+
+import logging
+from typing import Dict, List
+
+logger = logging.getLogger(__name__)
+
+CANARY_URLS = [
+    "https://www.google.com/maps/place/Rich+Table/data=!4m7!3m6!1s0x80858093eabc4f2d:0x68f428012b5db354!8m2!3d37.7743021!4d-122.4212768!16s%2Fg%2F1q5bmz5wy!19sChIJLfK8rJOAhYARVLNbKwGIb2g?authuser=0&hl=en&rclk=1",
+]
+
+CRITICAL_FIELDS = [
+    'name',
+    'location.address',
+    'location.coordinates',
+    'phone',
+    'website',
+    'attributes.cuisine_type',
+]
+
+def _has_value(document: dict, path: str) -> bool:
+    current = document
+    for part in path.split('.'):
+        if not isinstance(current, dict) or not current.get(part):
+            return False
+        current = current[part]
+    return True
+
+def run_canary(scraper, urls: List[str] = CANARY_URLS) -> Dict:
+    """Scrape the canary places and return a selector-health report."""
+    report = {'healthy': True, 'places': {}}
+    for url in urls:
+        restaurant = scraper.get_account(url).get('restaurant', {})
+        fields = {field: _has_value(restaurant, field) for field in CRITICAL_FIELDS}
+        report['places'][url] = fields
+        if not all(fields.values()):
+            report['healthy'] = False
+    return report
+
+def format_report(report: Dict) -> str:
+    """Render a selector-health report for the log."""
+    lines = ['Selector health: ' + ('OK' if report['healthy'] else 'FAILED')]
+    for url, fields in report['places'].items():
+        lines.append(f"  {url}")
+        for field, ok in fields.items():
+            lines.append(f"    {'ok     ' if ok else 'MISSING'} {field}")
+    return '\n'.join(lines)
@@ -0,0 +1,45 @@
+"""
+Search result filters.
+Cards below the minimum rating or review count, or whose category does
+not pass the include/exclude filters, are skipped during the search,
+before a place job is created for them, and do not count towards the
+search's result limit, so the feed is scrolled until enough qualifying
+places are found. Category terms match case-insensitively anywhere in
+the card's category or name, so "fast food" excludes "Fast food
+restaurant" and "Italian" includes "Italian restaurant".
+"""
+
+from typing import Dict, Iterable, Optional
+
+class CardFilter:
+    """Minimum rating and review count, and category filters, of the search results to scrape."""
+
+    def __init__(self, min_rating: Optional[float] = None, min_reviews: Optional[int] = None,
+                 include_categories: Iterable[str] = (), exclude_categories: Iterable[str] = ()):
+        self.min_rating = min_rating or None
+        self.min_reviews = min_reviews or None
+        self.include_categories = [term.lower() for term in include_categories if term]
+        self.exclude_categories = [term.lower() for term in exclude_categories if term]
+
+    @property
+    def active(self) -> bool:
+        return (self.min_rating is not None or self.min_reviews is not None
+                or bool(self.include_categories) or bool(self.exclude_categories))
+
+    def accepts(self, card: Dict) -> bool:
+        """Return True if the card meets the thresholds; unrated cards only pass without a minimum rating."""
+        if self.min_rating is not None and (card.get('overall_rating') or 0) < self.min_rating:
+            return False
+        if self.min_reviews is not None and (card.get('total_reviews') or 0) < self.min_reviews:
+            return False
+        text = f"{card.get('category') or ''} {card.get('name') or ''}".lower()
+        if any(term in text for term in self.exclude_categories):
+            return False
+        # Without a category on the card the include filter cannot tell, so the place is kept
+        if self.include_categories and card.get('category'):
+            return any(term in text for term in self.include_categories)
+        return True
+
+    def __repr__(self) -> str:
+        return (f"CardFilter(min_rating={self.min_rating}, min_reviews={self.min_reviews}, "
+                f"include={self.include_categories}, exclude={self.exclude_categories})")
@@ -0,0 +1,64 @@
+"""
+Crawl checkpoints.
+Records which places are done and which are still pending after every
+place, so a crawl that dies halfway can be resumed without redoing work.
+"""
+
+import json
+import logging
+import os
+import threading
+from pathlib import Path
+from typing import List
+
+from ..storage.idempotency import extract_cid
+
+logger = logging.getLogger(__name__)
+
+class CrawlCheckpoint:
+    """Completed and pending places of a crawl, persisted to a JSON file."""
+
+    def __init__(self, path: str):
+        self.path = Path(path)
+        self.completed = set()
+        self.pending: List[str] = []
+        # Places finish on several scheduler threads
+        self._lock = threading.RLock()
+        if self.path.exists():
+            with open(self.path, 'r', encoding='utf-8') as f:
+                state = json.load(f)
+            self.completed = set(state.get('completed', []))
+            self.pending = state.get('pending', [])
+            logger.info(f"Resuming from {self.path}: {len(self.completed)} done, {len(self.pending)} pending")
+
+    @staticmethod
+    def _key(url: str) -> str:
+        return extract_cid(url) or url
+
+    def is_done(self, url: str) -> bool:
+        """Return True if the place was completed by an earlier attempt."""
+        return self._key(url) in self.completed
+
+    def add_pending(self, url: str):
+        """Queue a place that still has to be scraped."""
+        with self._lock:
+            if not self.is_done(url) and url not in self.pending:
+                self.pending.append(url)
+                self.save()
+
+    def mark_done(self, url: str):
+        """Record a completed place."""
+        with self._lock:
+            self.completed.add(self._key(url))
+            if url in self.pending:
+                self.pending.remove(url)
+            self.save()
+
+    def save(self):
+        """Write the checkpoint atomically."""
+        with self._lock:
+            self.path.parent.mkdir(parents=True, exist_ok=True)
+            tmp_path = self.path.with_suffix('.tmp')
+            with open(tmp_path, 'w', encoding='utf-8') as f:
+                json.dump({'completed': sorted(self.completed), 'pending': self.pending}, f, indent=2)
+            os.replace(tmp_path, self.path)
@@ -0,0 +1,95 @@
+"""
+Cookie consent handling.
+From EU IPs Google redirects to consent.google.com, or shows a consent
+dialog over Maps, before any search feed or place panel is rendered.
+Depending on the run's mode the consent is accepted or rejected by
+clicking the dialog's button in any of the common interface languages, or
+consent cookies are set before the first page so the wall never appears.
+"""
+
+import logging
+import time
+from typing import List
+
+from selenium.webdriver.common.by import By
+
+logger = logging.getLogger(__name__)
+
+ACCEPT, REJECT, COOKIE, OFF = 'accept', 'reject', 'cookie', 'off'
+MODES = (ACCEPT, REJECT, COOKIE, OFF)
+# Seconds to wait for the consent buttons once on the consent page
+CONSENT_WAIT = 5
+CONSENT_BUTTONS = 'form[action*="consent"] button, div[role="dialog"] button'
+ACCEPT_LABELS = {
+    'accept all', 'alle akzeptieren', 'tout accepter', 'aceptar todo', 'accetta tutto', 'alles accepteren',
+    'aceitar tudo', 'zaakceptuj wszystko', 'godkänn alla', 'accepter alle', 'hyväksy kaikki', 'přijmout vše',
+    'alle accepteren', 'acceptați tot', 'αποδοχή όλων', 'összes elfogadása',
+}
+REJECT_LABELS = {
+    'reject all', 'alle ablehnen', 'tout refuser', 'rechazar todo', 'rifiuta tutto', 'alles afwijzen',
+    'rejeitar tudo', 'odrzuć wszystko', 'avvisa alla', 'afvis alle', 'hylkää kaikki', 'odmítnout vše',
+    'respingeți tot', 'απόρριψη όλων', 'összes elutasítása',
+}
+# Consent cookies Google sets after "Accept all"
+CONSENT_COOKIES = [
+    {'name': 'SOCS', 'value': 'CAESHAgBEhJnd3NfMjAyMzA4MTAtMF9SQzIaAmVuIAEaBgiAo_CmBg'},
+    {'name': 'CONSENT', 'value': 'YES+cb'},
+]
+
+def is_consent_wall(driver) -> bool:
+    """Return True if the browser is on Google's cookie consent page."""
+    return 'consent.google.' in (driver.current_url or '')
+
+class ConsentHandler:
+    """Gets past the cookie consent wall in the configured mode."""
+
+    def __init__(self, mode: str = ACCEPT):
+        if mode not in MODES:
+            raise ValueError(f"Unknown consent mode '{mode}', expected one of {', '.join(MODES)}")
+        self.mode = mode
+
+    def prepare(self, driver):
+        """Set the consent cookies on a new browser in cookie mode."""
+        if self.mode != COOKIE:
+            return
+        for cookie in CONSENT_COOKIES:
+            try:
+                driver.execute_cdp_cmd('Network.setCookie', {
+                    **cookie, 'domain': '.google.com', 'path': '/', 'secure': True,
+                    'expires': int(time.time()) + 365 * 24 * 3600,
+                })
+            except Exception as e:
+                logger.warning(f"Could not set consent cookie {cookie['name']}: {str(e)}")
+
+    def _buttons(self, driver, labels: set, wait: float) -> List:
+        deadline = time.time() + wait
+        while True:
+            buttons = []
+            for button in driver.find_elements(By.CSS_SELECTOR, CONSENT_BUTTONS):
+                label = (button.text or button.get_attribute('aria-label') or '').strip().lower()
+                if label in labels:
+                    buttons.append(button)
+            if buttons or time.time() >= deadline:
+                return buttons
+            time.sleep(0.25)
+
+    def handle(self, driver) -> bool:
+        """Dismiss the consent page or dialog if one is shown; returns False if the wall is still up."""
+        on_wall = is_consent_wall(driver)
+        if self.mode == OFF:
+            return not on_wall
+        # Stale or rejected cookies bring the wall back, so cookie mode falls back to accepting
+        labels = REJECT_LABELS if self.mode == REJECT else ACCEPT_LABELS
+        buttons = self._buttons(driver, labels, CONSENT_WAIT if on_wall else 0)
+        if not buttons:
+            return not on_wall
+        logger.info(f"Answering the cookie consent with '{self.mode}'")
+        try:
+            buttons[0].click()
+        except Exception as e:
+            logger.warning(f"Could not click the consent button: {str(e)}")
+            return not on_wall
+        deadline = time.time() + CONSENT_WAIT
+        while is_consent_wall(driver) and time.time() < deadline:
+            time.sleep(0.25)
+        return not is_consent_wall(driver)
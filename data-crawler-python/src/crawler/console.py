@@ -0,0 +1,43 @@
+"""
+Browser console capture.
+Chrome's browser log carries the page's console messages and uncaught
+JavaScript exceptions (source "javascript"). The scraper drains it before
+every navigation and when the browser quits, logging each entry at debug
+level tagged with the job, and keeps the most recent entries for the
+failure artifacts, since a drained log cannot be read again.
+"""
+
+import logging
+from collections import deque
+from typing import Dict, List
+
+logger = logging.getLogger(__name__)
+
+KEEP_ENTRIES = 200
+
+class ConsoleLog:
+    """Streams a browser's console messages and JS exceptions into the crawler's log."""
+
+    def __init__(self, log_prefix: str = '', keep: int = KEEP_ENTRIES):
+        self.log_prefix = log_prefix
+        self.entries = deque(maxlen=keep)
+
+    def collect(self, driver, page_url: str = None) -> int:
+        """Drain the browser log of `driver` and return the number of new entries."""
+        try:
+            entries = driver.get_log('browser')
+        except Exception as e:
+            logger.debug(f"Could not read the console log: {str(e)}")
+            return 0
+        for entry in entries:
+            entry = {**entry, 'page_url': page_url}
+            self.entries.append(entry)
+            logger.debug(f"{self.log_prefix} {self.__kind(entry)} {entry.get('level')}: {entry.get('message')}")
+        return len(entries)
+
+    def recent(self) -> List[Dict]:
+        return list(self.entries)
+
+    @staticmethod
+    def __kind(entry: Dict) -> str:
+        return 'JS exception' if entry.get('source') == 'javascript' else 'Console'
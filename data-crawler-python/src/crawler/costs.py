@@ -0,0 +1,89 @@
+"""
+Per-run cost accounting.
+Counts page loads, bytes received (from Chrome's network events), bytes
+sent through proxies, CAPTCHA solves and browser wall-clock time, and
+prices them with configurable unit costs so data acquisition can be
+priced per market.
+"""
+
+import json
+import logging
+import threading
+from typing import Dict, Optional
+
+logger = logging.getLogger(__name__)
+
+GB = 1024 ** 3
+
+class RunCosts:
+    """Thread-safe resource counters for one crawl run."""
+
+    def __init__(self, proxy_per_gb: float = 0.0, captcha_per_solve: float = 0.0,
+                 browser_per_hour: float = 0.0):
+        self.prices = {
+            'proxy_per_gb': proxy_per_gb,
+            'captcha_per_solve': captcha_per_solve,
+            'browser_per_hour': browser_per_hour,
+        }
+        self.page_loads = 0
+        self.bytes_received = 0
+        self.proxy_bytes = 0
+        self.captcha_solves = 0
+        self.browser_seconds = 0.0
+        self._lock = threading.Lock()
+
+    def add_page_load(self):
+        with self._lock:
+            self.page_loads += 1
+
+    def add_bytes(self, count: int, proxied: bool):
+        with self._lock:
+            self.bytes_received += count
+            if proxied:
+                self.proxy_bytes += count
+
+    def add_captcha_solve(self):
+        with self._lock:
+            self.captcha_solves += 1
+
+    def add_browser_time(self, seconds: float):
+        with self._lock:
+            self.browser_seconds += seconds
+
+    def summary(self) -> Dict:
+        """Return the usage totals and their estimated cost."""
+        with self._lock:
+            proxy_gb = self.proxy_bytes / GB
+            browser_hours = self.browser_seconds / 3600
+            costs = {
+                'proxy': round(proxy_gb * self.prices['proxy_per_gb'], 4),
+                'captcha': round(self.captcha_solves * self.prices['captcha_per_solve'], 4),
+                'browser': round(browser_hours * self.prices['browser_per_hour'], 4),
+            }
+            return {
+                'page_loads': self.page_loads,
+                'bandwidth_gb': round(self.bytes_received / GB, 4),
+                'proxy_gb': round(proxy_gb, 4),
+                'captcha_solves': self.captcha_solves,
+                'browser_hours': round(browser_hours, 3),
+                'costs': costs,
+                'total_cost': round(sum(costs.values()), 4),
+            }
+
+    def save(self, path: str, extra: Optional[Dict] = None):
+        """Write the summary as JSON, merged with `extra` fields such as the job ID."""
+        with open(path, 'w', encoding='utf-8') as f:
+            json.dump({**(extra or {}), **self.summary()}, f, indent=2)
+        logger.info(f"Run costs written to {path}")
+
+def network_bytes(performance_log) -> int:
+    """Sum the encoded bytes of finished requests in a Chrome performance log."""
+    total = 0
+    for entry in performance_log:
+        try:
+            message = json.loads(entry['message'])['message']
+        except (KeyError, ValueError):
+            continue
+        if message.get('method') == 'Network.loadingFinished':
+            total += int(message.get('params', {}).get('encodedDataLength') or 0)
+    return total
@@ -0,0 +1,59 @@
+"""
+Crawl error types.
+Failures are raised and reported as one of a few kinds, so callers can
+decide whether to retry, rotate proxies or alert without parsing log
+messages: blocked by Google, stuck on the cookie consent wall, a selector
+that matched nothing, or a page that did not load in time.
+"""
+
+from typing import Dict, Optional
+
+from selenium.common.exceptions import NoSuchElementException, TimeoutException
+
+class CrawlError(Exception):
+    """Base class of classified crawl failures."""
+    kind = 'error'
+    # Whether the same job may succeed when tried again later
+    retryable = True
+
+    def __init__(self, message: str, url: Optional[str] = None):
+        super().__init__(message)
+        self.url = url
+
+    def to_dict(self) -> Dict:
+        """Return the error as stored with job results."""
+        return {'type': self.kind, 'message': str(self), 'retryable': self.retryable, 'url': self.url}
+
+class BlockedError(CrawlError):
+    """Google served a block page or CAPTCHA."""
+    kind = 'blocked'
+
+class ConsentWallError(CrawlError):
+    """The cookie consent page could not be dismissed."""
+    kind = 'consent_wall'
+
+class NavigationTimeoutError(CrawlError):
+    """The page did not finish loading in time."""
+    kind = 'nav_timeout'
+
+class SelectorMissingError(CrawlError):
+    """A field's selectors matched nothing on a page that loaded, usually a layout change."""
+    kind = 'selector_missing'
+    retryable = False
+
+    def __init__(self, field: str, url: Optional[str] = None):
+        super().__init__(f"No element for '{field}'", url)
+        self.field = field
+
+    def to_dict(self) -> Dict:
+        return {**super().to_dict(), 'field': self.field}
+
+def classify(error: Exception, url: Optional[str] = None) -> CrawlError:
+    """Return `error` as a CrawlError, mapping Selenium timeouts and lookups to their kinds."""
+    if isinstance(error, CrawlError):
+        return error
+    if isinstance(error, TimeoutException):
+        return NavigationTimeoutError(str(error).strip() or 'Timed out', url)
+    if isinstance(error, NoSuchElementException):
+        return SelectorMissingError('unknown', url)
+    return CrawlError(str(error), url)
@@ -0,0 +1,46 @@
+"""
+Place detail field selection.
+Not every run needs every field, and some fields cost interactions on
+the place page: the About tab is clicked, the reviews pane is scrolled,
+the Menu tab is opened and the restaurant's website is crawled. A run
+picks fields by name (CRAWLER_FIELDS=reviews,hours) or by depth
+(CRAWLER_DEPTH=basic|standard|full), and the scraper skips the work for
+the others. The name, address, contact details, rating and status are
+always extracted. Menus and websites also need their own settings on.
+"""
+
+from typing import Iterable, Optional
+
+FIELDS = ['hours', 'photos', 'popular_times', 'reviews', 'about', 'menu', 'website']
+DEPTHS = {
+    'basic': ['hours'],
+    'standard': ['hours', 'photos', 'popular_times', 'reviews'],
+    'full': FIELDS,
+}
+
+class FieldSelection:
+    """The optional place fields a run extracts."""
+
+    def __init__(self, fields: Iterable[str] = FIELDS):
+        fields = set(fields)
+        unknown = fields - set(FIELDS)
+        if unknown:
+            raise ValueError(f"Unknown fields: {', '.join(sorted(unknown))} (known: {', '.join(FIELDS)})")
+        self.fields = fields
+
+    @classmethod
+    def parse(cls, fields: Optional[str] = None, depth: Optional[str] = None) -> 'FieldSelection':
+        """Create a selection from a comma-separated field list or a depth; all fields when neither is given."""
+        if fields:
+            return cls(f.strip() for f in fields.split(',') if f.strip())
+        if depth:
+            if depth not in DEPTHS:
+                raise ValueError(f"Unknown depth: {depth} (known: {', '.join(DEPTHS)})")
+            return cls(DEPTHS[depth])
+        return cls()
+
+    def wants(self, field: str) -> bool:
+        return field in self.fields
+
+    def __repr__(self) -> str:
+        return f"FieldSelection({','.join(f for f in FIELDS if f in self.fields)})"
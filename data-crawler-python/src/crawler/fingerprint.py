@@ -0,0 +1,40 @@
+"""
+Google Maps layout fingerprinting.
+Records which key selectors are present on a place page and a hash of
+the loaded script bundles, so layout changes can be correlated with
+data-quality regressions across runs.
+"""
+
+import hashlib
+import json
+from typing import Dict
+
+# Selectors the parser depends on
+KEY_SELECTORS = [
+    'h1.DUwDvf',
+    'button[data-item-id="address"]',
+    'button[data-item-id^="phone:tel:"]',
+    'a[data-item-id="authority"]',
+    'div.skqShb',
+    'div.jftiEf',
+    'span.kvMYJc',
+    'button[data-value="Sort"]',
+]
+
+def layout_fingerprint(driver) -> Dict:
+    """Fingerprint the page currently loaded in the driver."""
+    selectors = driver.execute_script(
+        "return arguments[0].map(sel => document.querySelector(sel) !== null);",
+        KEY_SELECTORS
+    )
+    scripts = driver.execute_script(
+        "return Array.from(document.scripts).map(s => s.src).filter(src => src);"
+    )
+    # Bundle URLs carry a build identifier, so their hash changes on deploys
+    bundle_hash = hashlib.sha256('\n'.join(sorted(scripts)).encode('utf-8')).hexdigest()[:16]
+    fingerprint = {
+        'selectors': dict(zip(KEY_SELECTORS, selectors)),
+        'bundle_hash': bundle_hash,
+    }
+    fingerprint['hash'] = hashlib.sha256(json.dumps(fingerprint, sort_keys=True).encode('utf-8')).hexdigest()[:16]
+    return fingerprint
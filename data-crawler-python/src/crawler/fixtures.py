@@ -0,0 +1,188 @@
+"""
+HTML fixtures of Maps pages.
+In capture mode the scraper saves the full DOM of every search feed,
+place page and place tab it parses under a fixture directory, one folder
+per place or search:
+
+    testdata/<cid or url hash>/meta.json    URL and capture time, or "synthetic" for hand-written pages
+    testdata/<cid or url hash>/place.html   overview, then about.html, menu.html, reviews.html
+    testdata/<cid or url hash>/expected.json   what the scraper extracted
+
+FixtureDriver serves those files in place of Chrome, so the scraper's own
+extraction code runs unchanged against saved HTML and its output can be
+compared with what was extracted at capture time.
+"""
+
+import hashlib
+import json
+import logging
+import os
+from datetime import datetime, timezone
+from typing import Dict, List, Optional
+
+from bs4 import BeautifulSoup
+from selenium.common.exceptions import NoSuchElementException
+from selenium.webdriver.common.by import By
+
+from ..storage.idempotency import extract_cid
+
+logger = logging.getLogger(__name__)
+
+SEARCH, PLACE = 'search', 'place'
+
+class FixtureMissError(RuntimeError):
+    """Raised when a page is requested that has no fixture."""
+
+def fixture_key(url: str) -> str:
+    """Return the folder name of a URL: its CID, or a hash of the URL without query string."""
+    cid = extract_cid(url)
+    if cid:
+        return f"cid_{cid}"
+    return hashlib.sha1(url.split('?')[0].encode('utf-8')).hexdigest()[:16]
+
+def save_fixture(directory: str, url: str, kind: str, html: str, expected: Optional[object] = None):
+    """Save the HTML of one page kind of `url`, and optionally what was extracted from it."""
+    folder = os.path.join(directory, fixture_key(url))
+    os.makedirs(folder, exist_ok=True)
+    with open(os.path.join(folder, f"{kind}.html"), 'w', encoding='utf-8') as f:
+        f.write(html)
+    meta_path = os.path.join(folder, 'meta.json')
+    meta = {'url': url, 'captured_at': datetime.now(timezone.utc).isoformat(), 'kinds': []}
+    if os.path.exists(meta_path):
+        with open(meta_path, encoding='utf-8') as f:
+            meta = {**json.load(f), 'captured_at': meta['captured_at']}
+    meta['kinds'] = sorted(set(meta['kinds']) | {kind})
+    with open(meta_path, 'w', encoding='utf-8') as f:
+        json.dump(meta, f, indent=2)
+    if expected is not None:
+        save_expected(directory, url, expected)
+    logger.info(f"Saved {kind} fixture of {url} to {folder}")
+
+def save_expected(directory: str, url: str, expected: object):
+    """Save the extraction result of `url` as the fixture's expected output."""
+    folder = os.path.join(directory, fixture_key(url))
+    os.makedirs(folder, exist_ok=True)
+    with open(os.path.join(folder, 'expected.json'), 'w', encoding='utf-8') as f:
+        json.dump(expected, f, indent=2, ensure_ascii=False, default=str)
+
+def list_fixtures(directory: str) -> List[Dict]:
+    """Return the meta of every fixture folder, with its path and expected output if saved."""
+    fixtures = []
+    if not os.path.isdir(directory):
+        return fixtures
+    for name in sorted(os.listdir(directory)):
+        meta_path = os.path.join(directory, name, 'meta.json')
+        if not os.path.exists(meta_path):
+            continue
+        with open(meta_path, encoding='utf-8') as f:
+            meta = json.load(f)
+        meta['path'] = os.path.join(directory, name)
+        expected_path = os.path.join(meta['path'], 'expected.json')
+        if os.path.exists(expected_path):
+            with open(expected_path, encoding='utf-8') as f:
+                meta['expected'] = json.load(f)
+        fixtures.append(meta)
+    return fixtures
+
+def _css(by: str, value: str) -> Optional[str]:
+    if by == By.CSS_SELECTOR:
+        return value
+    if by == By.CLASS_NAME:
+        return f".{value}"
+    if by == By.ID:
+        return f"#{value}"
+    if by == By.TAG_NAME:
+        return value
+    # XPath lookups have no equivalent on saved HTML
+    return None
+
+class FixtureElement:
+    """Read-only stand-in for a WebElement backed by a BeautifulSoup tag."""
+
+    def __init__(self, tag, driver: 'FixtureDriver'):
+        self.tag = tag
+        self.driver = driver
+
+    @property
+    def text(self) -> str:
+        return self.tag.get_text(' ', strip=True)
+
+    def get_attribute(self, name: str) -> Optional[str]:
+        value = self.tag.get(name)
+        return ' '.join(value) if isinstance(value, list) else value
+
+    def find_elements(self, by: str, value: str) -> List['FixtureElement']:
+        css = _css(by, value)
+        return [FixtureElement(tag, self.driver) for tag in self.tag.select(css)] if css else []
+
+    def find_element(self, by: str, value: str) -> 'FixtureElement':
+        elements = self.find_elements(by, value)
+        if not elements:
+            raise NoSuchElementException(f"No fixture element for {value}")
+        return elements[0]
+
+    def is_displayed(self) -> bool:
+        return True
+
+    def is_enabled(self) -> bool:
+        return True
+
+    def click(self):
+        # Clicking a tab shows that tab's saved page, e.g. "About" -> about.html
+        if self.tag.get('role') == 'tab':
+            self.driver.show_tab((self.tag.get('aria-label') or '').split(' ')[0].lower())
+
+class FixtureDriver:
+    """Serves saved pages in place of a WebDriver so extraction runs without Chrome."""
+
+    def __init__(self, directory: str):
+        self.directory = directory
+        self.current_url = 'about:blank'
+        self.page_source = '<html></html>'
+        self._soup = BeautifulSoup(self.page_source, 'html.parser')
+        self._folder: Optional[str] = None
+
+    def _load(self, kind: str) -> bool:
+        path = os.path.join(self._folder, f"{kind}.html")
+        if not os.path.exists(path):
+            return False
+        with open(path, encoding='utf-8') as f:
+            self.page_source = f.read()
+        self._soup = BeautifulSoup(self.page_source, 'html.parser')
+        return True
+
+    def get(self, url: str):
+        self._folder = os.path.join(self.directory, fixture_key(url))
+        self.current_url = url
+        if not (self._load(PLACE) or self._load(SEARCH)):
+            raise FixtureMissError(f"No fixture for {url} in {self.directory}")
+
+    def show_tab(self, kind: str):
+        if self._folder and not self._load(kind):
+            logger.debug(f"No {kind} fixture for {self.current_url}")
+
+    def find_elements(self, by: str, value: str) -> List[FixtureElement]:
+        css = _css(by, value)
+        return [FixtureElement(tag, self) for tag in self._soup.select(css)] if css else []
+
+    def find_element(self, by: str, value: str) -> FixtureElement:
+        elements = self.find_elements(by, value)
+        if not elements:
+            raise NoSuchElementException(f"No fixture element for {value}")
+        return elements[0]
+
+    def execute_script(self, script: str, *args):
+        # Scrolling and clicks are no-ops; DOM queries made through scripts find nothing
+        return []
+
+    def get_log(self, log_type: str) -> list:
+        return []
+
+    def execute_cdp_cmd(self, cmd: str, params: Dict) -> Dict:
+        return {}
+
+    def close(self):
+        pass
+
+    def quit(self):
+        pass
@@ -5,16 +5,17 @@ Handles the web scraping logic for Google Maps restaurants.
 """
 
 import logging
+import os
 import re
 import time
 import traceback
-from datetime import datetime
-from typing import Dict, List, Optional
 import uuid
+from datetime import datetime, timezone
+from typing import Dict, Iterator, List, Optional, Tuple
 
 from bs4 import BeautifulSoup
 from selenium import webdriver
-from selenium.common.exceptions import NoSuchElementException
+from selenium.common.exceptions import TimeoutException
 from selenium.webdriver import ChromeOptions as Options
 from selenium.webdriver.chrome.service import Service
 from selenium.webdriver.common.by import By
@@ -22,17 +23,136 @@ from selenium.webdriver.support import expected_conditions as EC
 from selenium.webdriver.support.ui import WebDriverWait
 from webdriver_manager.chrome import ChromeDriverManager
 
+from ..enrichment.geo import coordinates_from_url, decode_plus_code
+from ..enrichment.hours import parse_opening_hours
+from ..enrichment.language import detect_language, language_code
+from ..enrichment.popular_times import hourly, parse_live
+from ..enrichment.prices import is_price_text, normalize_price_range
+from ..enrichment.status import normalize_status
+from ..enrichment.timezones import tag_timezone
+from ..storage.idempotency import extract_cid, extract_place_id
+from ..storage.ids import IdStrategy, StableIdStrategy
+from .anomaly import ResultCountHistory
+from .artifacts import capture_failure
+from .blocking import BackoffHandler, BlockHandler, is_blocked
+from .card_filter import CardFilter
+from .console import ConsoleLog
+from .consent import ConsentHandler
+from .costs import RunCosts, network_bytes
+from .errors import BlockedError, ConsentWallError, NavigationTimeoutError, SelectorMissingError, classify
+from .fields import FieldSelection
+from .fixtures import FixtureDriver, save_expected, save_fixture
+from .fingerprint import layout_fingerprint
+from .identified import CrawlerIdentity
+from .locale import REVIEW_WORDS, CrawlLocale, parse_count, parse_decimal, review_count_label
+from .photo_urls import base_photo_url, is_google_photo, resize_photo_url
+from .progress import ProgressReporter
+from .proxy_pool import ProxyPool
+from .reconcile import reconcile
+from .replay import record, replay
+from .selectors import SelectorRegistry
+from .stealth import StealthProfile
+from .throttle import AdaptiveThrottle
+from .tracing import span
+from .waits import Waits
+from ..models.job_context import JobContext
+
 GM_WEBPAGE = 'https://www.google.com/maps/'
 MAX_WAIT = 10
 MAX_RETRY = 5
 MAX_SCROLLS = 40
+# Popular times charts start on Sunday
+WEEKDAYS = ['Sunday', 'Monday', 'Tuesday', 'Wednesday', 'Thursday', 'Friday', 'Saturday']
+# Index of "Newest" in the reviews sort menu
+SORT_NEWEST = 1
+FEED_STABLE_WINDOW = 2
+FEED_STABLE_TIMEOUT = 15
+# Seconds a scroll waits for new cards, and scrolls in a row without any before giving up
+FEED_GROWTH_TIMEOUT = 10
+FEED_MAX_STALLS = 2
+FEED_MAX_SECONDS = 300
+# Counts mutations of the feed so a scroll waits for Maps to append cards instead of sleeping
+FEED_OBSERVER_JS = '''
+const feed = arguments[0];
+if (!feed.__crawlerObserver) {
+  feed.__crawlerMutations = 0;
+  feed.__crawlerObserver = new MutationObserver(() => { feed.__crawlerMutations += 1; });
+  feed.__crawlerObserver.observe(feed, {childList: true, subtree: true});
+}
+return feed.__crawlerMutations;
+'''
+REVIEW_SCROLL_BUDGET = 120
+# Seconds to wait for the About tab, which some places do not have
+ABOUT_TAB_WAIT = 5
+# Prices such as "$12", "12,50 €", "A$ 9.90" or "¥1,200", with or without thousands separators
+MENU_AMOUNT = r'(?:\d{1,3}(?:[.,]\d{3})+|\d+)(?:[.,]\d{1,2})?'
+MENU_PRICE = re.compile(rf'(?:[A-Z]{{0,3}}[$€£¥]\s?{MENU_AMOUNT}|{MENU_AMOUNT}\s?[$€£¥])')
+# Size requested for gallery and review photos instead of the small thumbnails
+PHOTO_SIZE = 'w1200'
+# Seconds without newly loaded reviews after which the pane is considered exhausted
+REVIEW_STALL_SECONDS = 8
 
 logger = logging.getLogger(__name__)
 
 class GoogleMapsScraper:
-    def __init__(self, debug=False):
-        self.debug = debug
-        logger.info(f"Initializing Google Maps scraper (debug mode: {debug})")
+    def __init__(self, debug=False, feed_stable_window=FEED_STABLE_WINDOW, job: Optional[JobContext] = None,
+                 review_scroll_budget=REVIEW_SCROLL_BUDGET, result_history: Optional[ResultCountHistory] = None,
+                 proxy_pool: Optional[ProxyPool] = None, block_handler: Optional[BlockHandler] = None,
+                 stealth: bool = False, progress: Optional[ProgressReporter] = None,
+                 throttle: Optional[AdaptiveThrottle] = None, costs: Optional[RunCosts] = None,
+                 id_strategy: Optional[IdStrategy] = None, record_dir: Optional[str] = None,
+                 replay_from: Optional[str] = None, max_reviews: Optional[int] = None,
+                 photo_size: str = PHOTO_SIZE, identity: Optional[CrawlerIdentity] = None,
+                 locale: Optional[CrawlLocale] = None, selectors: Optional[SelectorRegistry] = None,
+                 fixture_dir: Optional[str] = None, replay_fixtures: Optional[str] = None,
+                 feed_snapshots: bool = False, consent: Optional[ConsentHandler] = None,
+                 fields: Optional[FieldSelection] = None, card_filter: Optional[CardFilter] = None,
+                 feed_max_seconds: float = FEED_MAX_SECONDS, slow_mo: float = 1.0,
+                 failure_dir: Optional[str] = None, devtools: bool = False):
+        if identity and stealth:
+            raise ValueError("Identified crawler mode cannot be combined with stealth")
+        # DevTools only open in a visible browser
+        self.debug = debug or devtools
+        self.devtools = devtools
+        self.identity = identity
+        self.locale = locale
+        self.consent = consent or ConsentHandler()
+        self.fields = fields or FieldSelection()
+        self.waits = Waits(slow_mo)
+        self.card_filter = card_filter
+        self.selectors = selectors or SelectorRegistry.load()
+        self.photo_size = photo_size
+        self.max_reviews = max_reviews
+        self.record_dir = record_dir
+        self.replay_from = replay_from
+        self.fixture_dir = fixture_dir
+        self.replay_fixtures = replay_fixtures
+        # Failed places leave a screenshot, HTML and console log here when set
+        self.failure_dir = failure_dir
+        self.page_url = None
+        self.id_strategy = id_strategy or StableIdStrategy()
+        self.costs = costs or RunCosts()
+        self.throttle = throttle
+        self.stealth = stealth
+        self.block_handler = block_handler or BackoffHandler()
+        self.proxy_pool = proxy_pool
+        self.result_history = result_history
+        self.last_search_anomalous = False
+        # Classified failure of the last get_account call, None when it succeeded
+        self.last_error = None
+        self.fingerprint = None
+        self.review_scroll_budget = review_scroll_budget
+        self.job = job or JobContext()
+        self.progress = progress or ProgressReporter(self.job)
+        self.feed_stable_window = feed_stable_window
+        self.feed_snapshots = feed_snapshots
+        self.feed_max_seconds = feed_max_seconds
+        # Whether the last search scrolled to the feed's end-of-list marker
+        self.last_search_exhausted = False
+        self.cards = {}
+        self.proxy = self.job.proxy or (proxy_pool.acquire() if proxy_pool else None)
+        self.console = ConsoleLog(self.job.log_prefix())
+        logger.info(f"{self.job.log_prefix()} Initializing Google Maps scraper (debug mode: {self.debug}, devtools: {devtools})")
         self.driver = self.__get_driver()
         self.logger = self.__get_logger()
 
@@ -45,21 +165,88 @@ class GoogleMapsScraper:
             traceback.print_exception(exc_type, exc_value, tb)
         logger.info("Closing Chrome driver")
         self.driver.close()
-        self.driver.quit()
+        self.__quit_driver()
+        if self.proxy_pool and self.proxy:
+            self.proxy_pool.release(self.proxy)
         return True
 
     def __get_driver(self):
+        if self.replay_fixtures:
+            self.driver_started = time.time()
+            return FixtureDriver(self.replay_fixtures)
+        if self.replay_from:
+            self.driver_started = time.time()
+            return replay(self.replay_from)
         logger.info("Setting up Chrome driver")
         options = Options()
         if not self.debug:
             options.add_argument('--headless')
+        if self.devtools:
+            options.add_argument('--auto-open-devtools-for-tabs')
         options.add_argument('--no-sandbox')
         options.add_argument('--disable-dev-shm-usage')
+        # Network events feed the bandwidth figures of the cost report, console messages the failure artifacts
+        options.set_capability('goog:loggingPrefs', {'performance': 'ALL', 'browser': 'ALL'})
+        if self.locale:
+            self.locale.apply_options(options)
+        if self.proxy:
+            logger.info(f"Using proxy {self.proxy}")
+            options.add_argument(f'--proxy-server={self.proxy}')
+        # A fresh fingerprint for every browser, including after proxy rotation
+        profile = StealthProfile.random() if self.stealth else None
+        if profile:
+            profile.apply_options(options)
         service = Service(ChromeDriverManager().install())
         driver = webdriver.Chrome(service=service, options=options)
+        if profile:
+            profile.apply_driver(driver)
+            logger.info(f"Stealth profile: {profile.user_agent}, {profile.viewport[0]}x{profile.viewport[1]}, {profile.timezone}")
+        if self.identity:
+            self.identity.apply_driver(driver)
+        self.consent.prepare(driver)
+        self.driver_started = time.time()
         logger.info("Chrome driver initialized successfully")
+        if self.record_dir:
+            os.makedirs(self.record_dir, exist_ok=True)
+            driver = record(driver, os.path.join(self.record_dir, f"{self.job.job_id}_{uuid.uuid4().hex[:8]}.json"))
         return driver
 
+    def __collect_network(self):
+        """Add the bytes received since the last call to the run costs."""
+        try:
+            self.costs.add_bytes(network_bytes(self.driver.get_log('performance')), proxied=bool(self.proxy))
+        except Exception as e:
+            logger.debug(f"Could not read network events: {str(e)}")
+
+    def __collect_console(self):
+        """Log the console messages and JS exceptions of the current page."""
+        if not self.replay_fixtures:
+            self.console.collect(self.driver, self.page_url)
+
+    def __quit_driver(self):
+        """Account for the browser's traffic and lifetime, then quit it."""
+        self.__collect_network()
+        self.__collect_console()
+        self.costs.add_browser_time(time.time() - self.driver_started)
+        self.driver.quit()
+
+    def rotate_proxy(self):
+        """Restart the browser behind a different proxy from the pool."""
+        if not self.proxy_pool:
+            return
+        self.proxy_pool.report_failure(self.proxy)
+        self.proxy = self.proxy_pool.rotate(self.proxy)
+        logger.info(f"{self.job.log_prefix()} Rotating to proxy {self.proxy}")
+        self.restart()
+
+    def restart(self):
+        """Replace the browser with a fresh one."""
+        try:
+            self.__quit_driver()
+        except Exception as e:
+            logger.warning(f"Failed to quit Chrome driver: {str(e)}")
+        self.driver = self.__get_driver()
+
     def __get_logger(self):
         logger = logging.getLogger('googlemaps_scraper')
         logger.setLevel(logging.DEBUG)
@@ -68,21 +255,45 @@ class GoogleMapsScraper:
         logger.addHandler(handler)
         return logger
 
+    def __save_fixture(self, kind: str, expected=None):
+        """Save the current DOM as a fixture of the page being scraped when capturing fixtures."""
+        if not self.fixture_dir or not self.page_url:
+            return
+        try:
+            save_fixture(self.fixture_dir, self.page_url, kind, self.driver.page_source, expected)
+        except Exception as e:
+            logger.warning(f"Could not save {kind} fixture of {self.page_url}: {str(e)}")
+
+    def capture_failure(self, url: str, error: Optional[Dict] = None) -> Optional[str]:
+        """Save the browser's state after a failure of `url` when failure artifacts are on."""
+        if not self.failure_dir:
+            return None
+        try:
+            self.__collect_console()
+            return capture_failure(self.driver, self.failure_dir, self.job.job_id, url, error, self.console.recent())
+        except Exception as e:
+            logger.warning(f"Could not save failure artifacts of {url}: {str(e)}")
+            return None
+
     def sort_by(self, url: str, ind: int) -> int:
         logger.info(f"Sorting results at URL: {url}")
-        self.driver.get(url)
-        self.__click_on_cookie_agreement()
+        self.__navigate(url)
+        return self.__sort_reviews(ind)
 
-        wait = WebDriverWait(self.driver, MAX_WAIT)
+    def __sort_reviews(self, ind: int) -> int:
+        """Pick entry `ind` of the reviews sort menu on the current page."""
+        wait = WebDriverWait(self.driver, self.waits.scaled(MAX_WAIT))
         tries = 0
         while tries < MAX_RETRY:
             try:
-                menu_bt = wait.until(EC.element_to_be_clickable((By.XPATH, '//button[@data-value=\'Sort\']')))
+                menu_bt = wait.until(EC.element_to_be_clickable((By.CSS_SELECTOR, self.selectors.css('review_sort'))))
                 menu_bt.click()
-                time.sleep(3)
-                recent_rating_bt = self.driver.find_elements(By.XPATH, '//div[@role=\'menuitemradio\']')[ind]
+                self.waits.for_present(self.driver, self.selectors.css('review_sort_option'), MAX_WAIT)
+                recent_rating_bt = self.selectors.find_elements(self.driver, 'review_sort_option')[ind]
                 recent_rating_bt.click()
-                time.sleep(5)
+                # The pane reloads with the new order
+                self.waits.for_network_idle(self.driver)
+                self.waits.for_present(self.driver, self.selectors.css('review'), MAX_WAIT)
                 logger.info("Successfully sorted results")
                 return 0
             except Exception as e:
@@ -90,50 +301,213 @@ class GoogleMapsScraper:
                 logger.warning(f'Failed to click sorting button (attempt {tries}/{MAX_RETRY}): {str(e)}')
         return -1
 
-    def get_reviews(self, offset: int) -> List[Dict]:
-        """Get reviews starting from the given offset."""
-        self.__scroll()
-        time.sleep(4)
+    def open_reviews_tab(self, url: str, newest_first: bool = False) -> bool:
+        """Open a place and switch to its reviews pane, optionally sorted newest first."""
+        try:
+            self.__navigate(url)
+            wait = WebDriverWait(self.driver, self.waits.scaled(MAX_WAIT))
+            tab = wait.until(EC.element_to_be_clickable((By.CSS_SELECTOR, self.selectors.css('reviews_tab'))))
+            tab.click()
+            wait.until(EC.presence_of_element_located((By.CSS_SELECTOR, self.selectors.css('review'))))
+            if newest_first and self.__sort_reviews(SORT_NEWEST) != 0:
+                logger.warning(f"Could not sort reviews by newest for {url}")
+                return False
+            return True
+        except Exception as e:
+            logger.warning(f"Could not open reviews tab for {url}: {str(e)}")
+            return False
+
+    def get_reviews(self, offset: int, seen_review_ids: Optional[set] = None, restaurant_id: str = None) -> List[Dict]:
+        """Get reviews starting from the given offset, skipping already seen review IDs."""
+        seen_review_ids = seen_review_ids or set()
+        with span('review_scroll', max_reviews=self.max_reviews):
+            self.__scroll(stop_at_ids=seen_review_ids)
+        self.waits.for_network_idle(self.driver)
         self.__expand_reviews()
 
+        self.__save_fixture('reviews')
         response = BeautifulSoup(self.driver.page_source, 'html.parser')
-        rblock = response.find_all('div', class_='jftiEf fontBodyMedium')
+        rblock = self.selectors.select(response, 'review')
         parsed_reviews = []
         
         for index, review in enumerate(rblock):
+            if review.get('data-review-id') in seen_review_ids:
+                continue
             if index >= offset:
-                r = self.__parse_review(review)
+                r = self.__parse_review(review, restaurant_id)
                 if r:
                     parsed_reviews.append(r)
 
+        if self.max_reviews:
+            parsed_reviews = parsed_reviews[:self.max_reviews]
         logger.info(f"Parsed {len(parsed_reviews)} reviews")
+        self.progress.emit('reviews_parsed', force=True, restaurant_id=restaurant_id, reviews=len(parsed_reviews))
         return parsed_reviews
 
+    def get_menu(self, url: str) -> Optional[Dict]:
+        """Open a place's Menu tab and return its items, or menu photos when there is no structured menu."""
+        try:
+            self.__navigate(url)
+            wait = WebDriverWait(self.driver, self.waits.scaled(MAX_WAIT))
+            tab = wait.until(EC.element_to_be_clickable((By.CSS_SELECTOR, self.selectors.css('menu_tab'))))
+            tab.click()
+            self.waits.for_network_idle(self.driver)
+        except TimeoutException:
+            logger.info(f"No menu tab for {url}")
+            return None
+        except Exception as e:
+            logger.warning(f"Could not open menu tab for {url}: {str(e)}")
+            return None
+
+        self.__save_fixture('menu')
+        response = BeautifulSoup(self.driver.page_source, 'html.parser')
+        panel = response.find('div', attrs={'role': 'main'}) or response
+        items = self.__parse_menu_items(panel)
+        images = [] if items else self.__parse_menu_images(panel)
+        if not items and not images:
+            return None
+        logger.info(f"Menu of {url}: {len(items)} items, {len(images)} photos")
+        return {'items': items, 'images': images}
+
+    def __parse_menu_items(self, panel: BeautifulSoup) -> List[Dict]:
+        """Parse menu rows into {section, name, price, description} items."""
+        items, section = [], None
+        for element in panel.find_all(['h2', 'h3', 'div']):
+            classes = element.get('class') or []
+            if element.name in ('h2', 'h3') or 'fontTitleSmall' in classes:
+                section = element.get_text(' ', strip=True) or section
+                continue
+            if 'fontBodyMedium' not in classes or element.find('div', class_='fontBodyMedium'):
+                continue
+            lines = [line.strip() for line in element.get_text('\n').split('\n') if line.strip()]
+            price = next((line for line in lines if MENU_PRICE.fullmatch(line)), None)
+            if not lines or not price or lines[0] == price:
+                continue
+            description = [line for line in lines[1:] if line != price]
+            items.append({
+                'section': section,
+                'name': lines[0],
+                'price': price,
+                'description': ' '.join(description) or None,
+            })
+        return items
+
+    def __parse_menu_images(self, panel: BeautifulSoup) -> List[str]:
+        """Return the menu photo URLs at the configured size."""
+        images = []
+        for image in panel.find_all('img'):
+            src = image.get('src', '')
+            if is_google_photo(src):
+                url = resize_photo_url(src, self.photo_size)
+                if url not in images:
+                    images.append(url)
+        return images
+
     def get_account(self, url: str) -> Dict:
         """Get restaurant details from URL."""
-        logger.info(f"Fetching restaurant details from URL: {url}")
-        self.driver.get(url)
-        self.__click_on_cookie_agreement()
-        
+        logger.info(f"{self.job.log_prefix()} Fetching restaurant details from URL: {url}")
+        self.last_error = None
         try:
-            wait = WebDriverWait(self.driver, MAX_WAIT)
+            self.selectors.reload()
+            self.__navigate(url)
+            wait = WebDriverWait(self.driver, self.waits.scaled(MAX_WAIT))
             logger.info("Waiting for restaurant name element to load")
-            name_element = wait.until(
-                EC.presence_of_element_located((By.CLASS_NAME, 'DUwDvf'))
-            )
+            try:
+                name_element = wait.until(
+                    EC.presence_of_element_located((By.CSS_SELECTOR, self.selectors.css('place_name')))
+                )
+            except TimeoutException:
+                raise SelectorMissingError('place_name', url)
             restaurant_name = name_element.text.strip()
             logger.info(f"Found restaurant name in page: {restaurant_name}")
             
             # Get the page source after JavaScript has rendered
             logger.info("Getting page source for parsing")
-            response = BeautifulSoup(self.driver.page_source, 'html.parser')
-            result = self.__parse_place(response, url)
+            self.__save_fixture('place')
+            with span('extraction', page_url=url):
+                response = BeautifulSoup(self.driver.page_source, 'html.parser')
+                result = self.__parse_place(response, url)
+            card = self.cards.get(extract_cid(url) or url)
+            if card:
+                conflicts = reconcile(card, result['restaurant'])
+                if conflicts:
+                    result['restaurant']['extraction_conflicts'] = conflicts
+            result['restaurant']['review_count'] = self.__listed_review_count(response) or (card or {}).get('total_reviews')
+            result['restaurant']['cid'] = extract_cid(url)
+            result['restaurant']['place_id'] = extract_place_id(url)
+            result['restaurant']['job'] = self.job.dict()
+            if self.fingerprint is None:
+                self.fingerprint = layout_fingerprint(self.driver)
+                logger.info(f"Maps layout fingerprint: {self.fingerprint}")
+            result['restaurant']['layout_fingerprint'] = self.fingerprint['hash']
+            about = self.__get_about() if self.fields.wants('about') else {}
+            if about:
+                result['restaurant']['about'] = about
+            # Overview reviews are still parsed for the rating
+            if not self.fields.wants('reviews'):
+                result['reviews'] = []
+            for review in result['reviews']:
+                review['job_id'] = self.job.job_id
             logger.info(f"Parsed restaurant data: {result.get('restaurant', {}).get('name')}")
+            if self.fixture_dir:
+                save_expected(self.fixture_dir, url, result)
+            if self.proxy_pool:
+                self.proxy_pool.report_success(self.proxy)
             return result
             
         except Exception as e:
-            logger.error(f"Error getting restaurant details: {str(e)}", exc_info=True)
-            return {'restaurant': {'url': url}, 'reviews': []}
+            error = classify(e, url)
+            self.last_error = error
+            logger.error(f"{self.job.log_prefix()} Error getting restaurant details ({error.kind}): {str(e)}", exc_info=True)
+            # Before a proxy rotation replaces the browser
+            self.capture_failure(url, error.to_dict())
+            if self.throttle and isinstance(error, SelectorMissingError):
+                # The page loaded but never rendered the place panel
+                self.throttle.record(MAX_WAIT, partial=True)
+            if error.retryable:
+                # A failed navigation is often a blocked IP, so move to another proxy
+                self.rotate_proxy()
+            return {'restaurant': {'url': url}, 'reviews': [], 'error': error.to_dict()}
+
+    def __get_about(self) -> Dict[str, List[str]]:
+        """Open the About tab of the current place and return its attributes by section."""
+        try:
+            tab = WebDriverWait(self.driver, self.waits.scaled(ABOUT_TAB_WAIT)).until(
+                EC.element_to_be_clickable((By.CSS_SELECTOR, self.selectors.css('about_tab')))
+            )
+            tab.click()
+            WebDriverWait(self.driver, self.waits.scaled(MAX_WAIT)).until(
+                EC.presence_of_element_located((By.CSS_SELECTOR, self.selectors.css('about_section')))
+            )
+        except TimeoutException:
+            logger.info("No About tab for this place")
+            return {}
+        except Exception as e:
+            logger.warning(f"Could not open the About tab: {str(e)}")
+            return {}
+        self.__save_fixture('about')
+        return self.__parse_about(BeautifulSoup(self.driver.page_source, 'html.parser'))
+
+    def __parse_about(self, response: BeautifulSoup) -> Dict[str, List[str]]:
+        """Parse About sections, e.g. {"Service options": ["Dine-in", "Takeout"]}, keeping only offered attributes."""
+        about = {}
+        for section in self.selectors.select(response, 'about_section'):
+            heading = section.find('h2')
+            if not heading:
+                continue
+            values = []
+            for item in section.select('li span[aria-label]'):
+                label = item['aria-label'].strip()
+                # Missing attributes are listed as "No Wi-Fi" or "Doesn't have ..."
+                if re.match(r"^(No|Doesn't|Does not)\b", label):
+                    continue
+                value = item.get_text(' ', strip=True) or re.sub(r'^(Has|Serves|Offers|Accepts)\s+', '', label)
+                if value and value not in values:
+                    values.append(value)
+            if values:
+                about[heading.get_text(' ', strip=True)] = values
+        logger.info(f"Found {sum(len(v) for v in about.values())} About attributes in {len(about)} sections")
+        return about
 
     def __parse_review(self, review_div: BeautifulSoup, restaurant_id: str = None) -> Dict:
         """Parse a single review."""
@@ -144,61 +518,133 @@ class GoogleMapsScraper:
                 review['restaurant_id'] = restaurant_id
             
             review_id = review_div.get('data-review-id')
-            if review_id and restaurant_id:
+            if not review_id:
+                return None  # Skip reviews without valid IDs
+            review['review_id'] = review_id
+            if restaurant_id:
                 review['_id'] = f"{restaurant_id}_review_{review_id}"
                 review['id_review'] = review['_id']  # Set id_review to match _id
-            else:
-                return None  # Skip reviews without valid IDs
             
-            text_div = review_div.find('span', class_='wiI7pd')
+            text_div = self.selectors.select_one(review_div, 'review_text', record=False)
             if text_div:
                 review['text'] = text_div.text.strip()
             else:
                 review['text'] = ''
+            review['language'] = detect_language(review['text'])
+            translation = self.__parse_translation(review_div)
+            if translation:
+                review['translation'] = translation
+            review['photos'] = self.__parse_review_photos(review_div) or None
 
-            date_span = review_div.find('span', class_='rsqaWe')
+            date_span = self.selectors.select_one(review_div, 'review_date')
             if date_span:
                 review['date'] = date_span.text.strip()
 
-            rating_span = review_div.find('span', class_='kvMYJc')
+            rating_span = self.selectors.select_one(review_div, 'review_rating')
             if rating_span:
                 aria_label = rating_span.get('aria-label', '')
                 rating_match = re.search(r'(\d+)', aria_label)
                 if rating_match:
                     review['rating'] = int(rating_match.group(1))
 
-            reviewer_div = review_div.find('div', class_='d4r55')
+            reviewer_div = self.selectors.select_one(review_div, 'reviewer_name')
             if reviewer_div:
                 review['reviewer'] = {
                     'name': reviewer_div.text.strip(),
                     'total_reviews': None
                 }
 
-                stats_div = review_div.find('div', class_='RfnDt')
+                stats_div = self.selectors.select_one(review_div, 'reviewer_stats', record=False)
                 if stats_div:
                     stats_text = stats_div.text.strip()
-                    reviews_match = re.search(r'(\d+)\s*reviews?', stats_text)
+                    reviews_match = re.search(rf'(\d[\d.,]*)\s*{REVIEW_WORDS}', stats_text, re.IGNORECASE)
                     if reviews_match:
-                        review['reviewer']['total_reviews'] = int(reviews_match.group(1))
+                        review['reviewer']['total_reviews'] = parse_count(reviews_match.group(1))
+
+            response_div = self.selectors.select_one(review_div, 'owner_response', record=False)
+            if response_div:
+                response_date = self.selectors.select_one(response_div, 'owner_response_date')
+                response_text = self.selectors.select_one(response_div, 'owner_response_text')
+                review['owner_response'] = {
+                    'text': response_text.text.strip() if response_text else '',
+                    'date': response_date.text.strip() if response_date else None
+                }
 
         except Exception as e:
             return None
 
-        fields_to_keep = ['_id', 'id_review', 'restaurant_id', 'text', 'date', 'rating', 'reviewer']
+        fields_to_keep = ['_id', 'id_review', 'review_id', 'restaurant_id', 'text', 'date', 'rating', 'reviewer',
+                          'owner_response', 'language', 'translation', 'photos']
         return {k: v for k, v in review.items() if k in fields_to_keep and v is not None}
 
-    def __generate_unique_key(self, name: str, postal_code: str = None) -> str:
-        """Generate a unique key from restaurant name and postal code."""
-        # Clean the name: lowercase, remove special chars, replace spaces with underscores
-        clean_name = re.sub(r'[^a-zA-Z0-9\s]', '', name.lower()).strip()
-        clean_name = re.sub(r'\s+', '_', clean_name)
-        
-        if postal_code:
-            # Clean the postal code: remove spaces and special characters
-            clean_postal = re.sub(r'[^a-zA-Z0-9]', '', postal_code)
-            return f"{clean_name}_{clean_postal}"
-        
-        return clean_name
+    def __parse_translation(self, review_div: BeautifulSoup) -> Optional[Dict]:
+        """Return the source language when Maps shows the review translated by Google."""
+        text = review_div.get_text(' ', strip=True)
+        if 'Translated by Google' not in text:
+            return None
+        original = re.search(r'See original \(([^)]+)\)', text)
+        source = original.group(1) if original else None
+        return {'by': 'google', 'source_language': language_code(source) or source}
+
+    def __parse_review_photos(self, review_div: BeautifulSoup) -> List[str]:
+        """Return the URLs of the photos attached to a review."""
+        photos = []
+        for button in self.selectors.select(review_div, 'review_photo', record=False):
+            match = re.search(r'url\(["\']?([^"\')]+)', button.get('style', ''))
+            if not match:
+                continue
+            url = resize_photo_url(match.group(1), self.photo_size)
+            if url not in photos:
+                photos.append(url)
+        return photos
+
+    def __current_url(self) -> Optional[str]:
+        try:
+            return self.driver.current_url
+        except Exception:
+            return None
+
+    def __parse_hours(self, response: BeautifulSoup) -> Dict[str, str]:
+        """Return the hours text by day name from the weekly hours table, e.g. {"Monday": "11 AM–10 PM"}."""
+        rows = {}
+        for row in self.selectors.select(response, 'hours_rows'):
+            cells = row.find_all('td')
+            if len(cells) < 2:
+                continue
+            day = cells[0].get_text(' ', strip=True)
+            text = cells[1].get('aria-label') or ', '.join(
+                li.get_text(' ', strip=True) for li in cells[1].find_all('li')
+            ) or cells[1].get_text(' ', strip=True)
+            if day and text:
+                rows[day] = text.strip()
+        if rows:
+            return rows
+
+        # Collapsed hours only carry a summary like "Monday, 11 AM to 10 PM; Tuesday, Closed; Hide open hours"
+        summary = self.selectors.select_one(response, 'hours_summary')
+        for part in (summary['aria-label'] if summary else '').split(';'):
+            day, _, text = part.partition(',')
+            if text.strip() and day.strip() in WEEKDAYS:
+                rows[day.strip()] = re.sub(r'\.?\s*Hide open hours.*$', '', text).strip()
+        return rows
+
+    def __parse_gallery(self, response: BeautifulSoup) -> List[Dict]:
+        """Return the place's gallery photos at the configured size, header photo first."""
+        urls = []
+        for image in self.selectors.select(response, 'gallery_photo', record=False):
+            urls.append(image.get('src', ''))
+        for tile in self.selectors.select(response, 'gallery_tile', record=False):
+            match = re.search(r'url\(["\']?([^"\')]+)', tile.get('style', ''))
+            if match:
+                urls.append(match.group(1))
+
+        photos, seen = [], set()
+        for url in urls:
+            if not url.startswith('http') or base_photo_url(url) in seen:
+                continue
+            seen.add(base_photo_url(url))
+            photos.append({'url': resize_photo_url(url, self.photo_size), 'thumbnail_url': url})
+        return photos
 
     def __parse_place(self, response, url: str) -> Dict:
         """Parse restaurant details from the page."""
@@ -221,35 +667,16 @@ class GoogleMapsScraper:
         
         try:
             # Parse restaurant name - try multiple selectors
-            name = None
-            name_selectors = [
-                ('h1', 'DUwDvf'),  # Primary selector
-                ('h1', 'fontHeadlineLarge'),  # Alternative class
-                ('div', 'fontHeadlineLarge'),  # Alternative element
-                ('div', 'DUwDvf')  # Fallback
-            ]
-            
-            logger.info("Attempting to find restaurant name using selectors")
-            for element, class_name in name_selectors:
-                name_element = response.find(element, class_=class_name)
-                if name_element and name_element.text.strip():
-                    name = name_element.text.strip()
-                    logger.info(f"Found restaurant name '{name}' using {element}.{class_name}")
-                    break
-                else:
-                    logger.debug(f"No name found with {element}.{class_name}")
-            
+            name_element = self.selectors.select_one(response, 'place_name')
+            name = name_element.text.strip() if name_element else None
             if not name:
                 logger.error("Could not find restaurant name with any selector")
                 return {'restaurant': place, 'reviews': []}
             
             place['name'] = name
-            # Generate a unique ID for the restaurant
-            place['_id'] = self.__generate_unique_key(place['name'])
-            logger.info(f"Generated ID '{place['_id']}' for restaurant '{place['name']}'")
 
             # Parse address and location details
-            address_element = response.find('button', {'data-item-id': 'address'})
+            address_element = self.selectors.select_one(response, 'address')
             if address_element:
                 # Clean the address text by removing special characters
                 address = re.sub(r'[^\x00-\x7F]+', '', address_element.text.strip())
@@ -262,9 +689,6 @@ class GoogleMapsScraper:
                     postal_code = postal_match.group(0)
                     place['location']['postal_code'] = postal_code
                     logger.info(f"Extracted postal code: {postal_code}")
-                    # Update ID with postal code if available
-                    place['_id'] = self.__generate_unique_key(place['name'], postal_code)
-                    logger.info(f"Updated ID with postal code: {place['_id']}")
                 
                 # Try to parse address components
                 address_parts = address.split(',')
@@ -275,34 +699,56 @@ class GoogleMapsScraper:
                         place['location']['state'] = state_zip[0]
                     place['location']['country'] = address_parts[-1].strip()
 
-            # Try to extract coordinates from URL
-            coords_match = re.search(r'!3d(-?\d+\.\d+)!4d(-?\d+\.\d+)', url)
-            if coords_match:
-                lat = float(coords_match.group(1))
-                lng = float(coords_match.group(2))
+            # Parse the plus code, e.g. "QHPR+X8 San Francisco, California"
+            plus_code_button = self.selectors.select_one(response, 'plus_code')
+            if plus_code_button:
+                plus_code = re.sub(r'^Plus code:\s*', '', plus_code_button.get('aria-label') or plus_code_button.text).strip()
+                place['plus_code'] = re.sub(r'[^\x00-\x7F]+', '', plus_code).strip()
+
+            # Coordinates come from the place's URL parameters, then the URL the page
+            # settled on, then a full plus code
+            coordinates = coordinates_from_url(url) or coordinates_from_url(self.__current_url())
+            if not coordinates and place.get('plus_code'):
+                coordinates = decode_plus_code(place['plus_code'])
+            if coordinates:
+                lat, lng = coordinates
                 place['location']['coordinates'] = [lng, lat]  # GeoJSON uses [longitude, latitude]
                 logger.info(f"Extracted coordinates: {lat}, {lng}")
+                tag_timezone(place)
+
+            # Generate a unique ID once the URL, address and coordinates are known
+            place['_id'] = self.id_strategy.place_id(place)
+            logger.info(f"Generated ID '{place['_id']}' for restaurant '{place['name']}'")
 
             # Parse phone number
-            phone_button = response.find('button', {'data-item-id': 'phone:tel:'})
+            phone_button = self.selectors.select_one(response, 'phone')
             if phone_button:
                 place['phone'] = phone_button.text.strip()
                 logger.info(f"Found phone number: {place['phone']}")
 
+            # Parse the menu link
+            menu_link = self.selectors.select_one(response, 'menu_link')
+            if menu_link and menu_link.get('href'):
+                place['menu_url'] = menu_link['href']
+
             # Parse website
-            website_button = response.find('a', {'data-item-id': 'authority'})
+            website_button = self.selectors.select_one(response, 'website')
             if website_button:
                 place['website'] = website_button.get('href')
                 logger.info(f"Found website: {place['website']}")
 
             # Parse attributes (price level, cuisine type)
-            category_div = response.find('div', class_='skqShb')
+            category_div = self.selectors.select_one(response, 'category')
             if category_div:
                 categories = []
-                price_level = None
+                price = None
                 rating_info = None
                 
                 for span in category_div.find_all('span'):
+                    # Prices keep their currency symbols, e.g. "€10–20"
+                    if span.find('span') is None and is_price_text(span.text.strip()):
+                        price = price or normalize_price_range(span.text.strip(), place['location'].get('country'))
+                        continue
                     text = re.sub(r'[^\x00-\x7F]+', '', span.text.strip())  # Remove non-ASCII chars
                     
                     # Skip empty or bullet point texts
@@ -310,25 +756,14 @@ class GoogleMapsScraper:
                         continue
                         
                     # Check if it's a rating
-                    if re.match(r'^\d+\.?\d*$', text):
-                        rating_info = float(text)
+                    if re.match(r'^\d+([.,]\d+)?$', text):
+                        rating_info = parse_decimal(text)
                         continue
                         
                     # Check if it's a review count in parentheses
                     if text.startswith('(') and text.endswith(')'):
                         continue
                         
-                    # Check if it's a price range
-                    if text.startswith('USD'):
-                        price_text = text.replace('USD', '$').replace(' ', '')
-                        if '-' in price_text:
-                            # If it's a range like "$10-20", just count the dollar signs
-                            price_level = 1
-                        else:
-                            # Count the dollar signs
-                            price_level = text.count('$')
-                        continue
-                    
                     # If we get here, it's probably a cuisine type
                     if text and not any(x in text for x in ['USD', '$', '(', ')']):
                         # Avoid duplicates
@@ -338,11 +773,47 @@ class GoogleMapsScraper:
                 # Set the attributes
                 if categories:
                     place['attributes']['cuisine_type'] = categories
-                if price_level is not None:
-                    place['attributes']['price_level'] = price_level
+                    # The primary category, the one search result cards show
+                    place['category'] = categories[0]
+                if price:
+                    place['attributes']['price_level'] = price['level']
+                    place['attributes']['price_range'] = price
+
+            # Parse opening hours, keeping the displayed text by day
+            hours_rows = self.__parse_hours(response) if self.fields.wants('hours') else {}
+            if hours_rows:
+                place['opening_hours'] = parse_opening_hours(hours_rows)
+                place['opening_hours_raw'] = hours_rows
+
+            # Parse the business status, e.g. "Permanently closed" or "Open ⋅ Closes 10 PM"
+            status_element = self.selectors.select_one(response, 'open_status', record=False)
+            if status_element:
+                place['status_raw'] = status_element.get('aria-label') or status_element.get_text(' ', strip=True)
+            place.update(normalize_status(place.get('status_raw')))
+
+            # Parse the header photo
+            header_image = self.selectors.select_one(response, 'header_photo')
+            if header_image and header_image.get('src', '').startswith('http'):
+                place['thumbnail'] = header_image['src']
+            if self.fields.wants('photos'):
+                place['photos'] = self.__parse_gallery(response)
+
+            # Parse the popular times histogram and the live busyness, if shown
+            histogram, live = None, None
+            if self.fields.wants('popular_times'):
+                histogram, live = self.__parse_popular_times(response)
+            if histogram:
+                captured_at = datetime.now(timezone.utc).isoformat()
+                place['popular_times'] = {
+                    'captured_at': captured_at,
+                    'histogram': histogram,
+                    'hours': hourly(histogram)
+                }
+                if live:
+                    place['popular_times']['live'] = {**live, 'captured_at': captured_at}
 
             # Parse reviews
-            reviews_container = response.find_all('div', class_='jftiEf fontBodyMedium')
+            reviews_container = self.selectors.select(response, 'review', record=False)
             if reviews_container:
                 for review_div in reviews_container:
                     # Skip reviews without a review ID
@@ -366,6 +837,13 @@ class GoogleMapsScraper:
                     # Only add reviews with text content
                     if not review.get('text'):
                         continue
+                    review['language'] = detect_language(review['text'])
+                    translation = self.__parse_translation(review_div)
+                    if translation:
+                        review['translation'] = translation
+                    photos = self.__parse_review_photos(review_div)
+                    if photos:
+                        review['photos'] = photos
                         
                     # Generate review ID
                     review['_id'] = f"{place['_id']}_review_{review_id}"
@@ -386,38 +864,151 @@ class GoogleMapsScraper:
             logger.error(f"Error parsing restaurant details: {str(e)}", exc_info=True)
             return {'restaurant': place, 'reviews': []}
 
+    def __parse_popular_times(self, response: BeautifulSoup) -> Tuple[Optional[Dict[str, List[Optional[int]]]],
+                                                                      Optional[Dict]]:
+        """Parse the popular times chart into 24 hourly busyness percentages per weekday, plus the live
+        busyness of the current hour as {'day', 'hour', 'busyness', 'usual'} when the chart shows it."""
+        container = self.selectors.select_one(response, 'popular_times')
+        if not container:
+            return None, None
+        histogram, live = {}, None
+        days = container.find_all('div', recursive=False)
+        for day, day_div in zip(WEEKDAYS, days):
+            hours = [None] * 24
+            # The live bar is labelled "Currently 45% busy, usually 60% busy." without its hour
+            previous_hour, live_bar = None, None
+            for bar in day_div.find_all('div', attrs={'aria-label': True}):
+                match = re.match(r'(\d+)% busy at (\d+)\s*(am|pm)', bar['aria-label'], re.IGNORECASE)
+                if match:
+                    hour = int(match.group(2)) % 12 + (12 if match.group(3).lower() == 'pm' else 0)
+                    hours[hour] = int(match.group(1))
+                    if live_bar and live_bar['hour'] is None:
+                        live_bar['hour'] = (hour - 1) % 24
+                    previous_hour = hour
+                elif parse_live(bar['aria-label']):
+                    live_bar = {'day': day, 'hour': None if previous_hour is None else (previous_hour + 1) % 24,
+                                **parse_live(bar['aria-label'])}
+            if live_bar:
+                live = live_bar
+                if live['hour'] is not None and hours[live['hour']] is None:
+                    hours[live['hour']] = live['usual']
+            if any(value is not None for value in hours):
+                histogram[day] = hours
+        return histogram or None, live
+
     def __filter_string(self, str):
         return str.replace('\r', ' ').replace('\n', ' ').replace('\t', ' ').strip()
 
-    def __click_on_cookie_agreement(self):
-        """Click on cookie agreement if present."""
-        try:
-            agree = WebDriverWait(self.driver, 10).until(
-                EC.element_to_be_clickable((By.XPATH, '//span[contains(text(), "Accept all")]')))
-            agree.click()
-        except:
-            pass
+    def __navigate(self, url: str):
+        """Load a page in the crawl locale, accept cookies and get past block pages via the block handler."""
+        self.page_url = url
+        if self.locale:
+            url = self.locale.apply_url(url)
+        with span('navigation', page_url=url):
+            for attempt in range(1, MAX_RETRY + 1):
+                if self.throttle:
+                    self.throttle.wait()
+                self.__collect_network()
+                self.__collect_console()
+                started = time.time()
+                try:
+                    self.driver.get(url)
+                except TimeoutException as e:
+                    raise NavigationTimeoutError(f"Timed out loading {url}", url) from e
+                self.costs.add_page_load()
+                if not self.replay_fixtures and not self.consent.handle(self.driver):
+                    raise ConsentWallError(f"Stuck on the cookie consent page loading {url}", url)
+                blocked = is_blocked(self.driver)
+                if self.throttle:
+                    self.throttle.record(time.time() - started, blocked=blocked)
+                if not blocked:
+                    return
+                logger.warning(f"{self.job.log_prefix()} Block page detected at {self.driver.current_url}")
+                # No point backing off or rotating when there is no retry left
+                if attempt == MAX_RETRY or not self.block_handler.handle(self, attempt):
+                    break
+            raise BlockedError(f"Blocked by Google while loading {url}", url)
 
-    def __scroll(self):
-        """Scroll through reviews."""
+    def __scroll(self, stop_at_ids: Optional[set] = None):
+        """Scroll through reviews until `max_reviews` are loaded or no new ones appear,
+        stopping early once the time budget is spent or a review from `stop_at_ids` has been loaded.
+        Without `max_reviews` at most MAX_SCROLLS scrolls are made."""
+        start_time = time.time()
         try:
-            scrollable_div = self.driver.find_element(By.CSS_SELECTOR, 'div.m6QErb.DxyBCb.kA9KIf.dS8AEf')
-            for _ in range(MAX_SCROLLS):
+            scrollable_div = self.selectors.find_element(self.driver, 'review_pane', self.page_url)
+            loaded, last_growth, scroll_count = 0, time.time(), 0
+            while self.max_reviews or scroll_count < MAX_SCROLLS:
+                if time.time() - start_time > self.review_scroll_budget:
+                    logger.warning(f"Review scroll budget of {self.review_scroll_budget}s spent after {scroll_count} scrolls, keeping loaded reviews")
+                    break
+                if stop_at_ids and self.__loaded_review_ids() & stop_at_ids:
+                    logger.info(f"Reached a previously seen review after {scroll_count} scrolls")
+                    break
+                if self.max_reviews and loaded >= self.max_reviews:
+                    logger.info(f"Loaded {loaded} reviews after {scroll_count} scrolls, reached the maximum of {self.max_reviews}")
+                    break
                 self.driver.execute_script('arguments[0].scrollTop = arguments[0].scrollHeight', scrollable_div)
-                time.sleep(0.1)
+                self.waits.pause(0.1)
+                scroll_count += 1
+
+                count = len(self.__loaded_review_ids())
+                if count > loaded:
+                    loaded, last_growth = count, time.time()
+                elif time.time() - last_growth > REVIEW_STALL_SECONDS:
+                    logger.info(f"No new reviews for {REVIEW_STALL_SECONDS}s, stopping after {scroll_count} scrolls with {loaded} loaded")
+                    break
+                else:
+                    # Some panes stop paging until "More reviews" is clicked
+                    self.__click_more_reviews()
+                if self.progress.due('review_scroll'):
+                    self.progress.emit('review_scroll', scroll=scroll_count,
+                                       reviews_loaded=loaded,
+                                       elapsed=round(time.time() - start_time, 1))
         except Exception as e:
             logger.error(f"Error while scrolling: {str(e)}")
 
+    def __click_more_reviews(self) -> bool:
+        """Click the "More reviews" button if the pane shows one."""
+        try:
+            buttons = self.selectors.find_elements(self.driver, 'more_reviews')
+            if not buttons:
+                return False
+            before = len(self.__loaded_review_ids())
+            self.driver.execute_script("arguments[0].click();", buttons[0])
+            self.waits.for_count_change(self.driver, self.selectors.css('review'), before, 2)
+            return True
+        except Exception:
+            return False
+
+    def __listed_review_count(self, response: BeautifulSoup) -> Optional[int]:
+        """Return the review count shown under the place name."""
+        for span in response.find_all('span', attrs={'aria-label': True}):
+            count = review_count_label(span['aria-label'])
+            if count is not None:
+                return count
+        return None
+
+    def __loaded_review_ids(self) -> set:
+        """Return the IDs of the reviews currently in the DOM."""
+        ids = self.driver.execute_script(
+            "return Array.from(document.querySelectorAll(arguments[0]))"
+            ".map(el => el.getAttribute('data-review-id'));",
+            self.selectors.css('review')
+        )
+        return set(ids or [])
+
     def __expand_reviews(self):
         """Expand all reviews."""
         try:
-            buttons = self.driver.find_elements(By.CSS_SELECTOR, 'button.w8nwRe.kyuRq')
+            buttons = self.selectors.find_elements(self.driver, 'review_expand')
             for button in buttons:
                 try:
                     self.driver.execute_script("arguments[0].click();", button)
-                    time.sleep(0.5)
                 except:
                     continue
+            # Expanded reviews drop their "More" button
+            if buttons:
+                self.waits.for_count_change(self.driver, self.selectors.css('review_expand'), len(buttons), 2)
             return True
         except:
             return False
@@ -436,14 +1027,13 @@ class GoogleMapsScraper:
             logger.debug(f"Completed scroll {scroll_count}")
             
             # Wait for dynamic content to load
-            time.sleep(scroll_pause)
+            self.waits.for_network_idle(self.driver, timeout=scroll_pause)
             
             # Try to expand any collapsed sections
             try:
                 more_buttons = self.driver.find_elements(By.XPATH, "//button[contains(text(), 'More')]")
                 for button in more_buttons:
                     button.click()
-                    time.sleep(0.5)
             except:
                 pass
 
@@ -460,42 +1050,173 @@ class GoogleMapsScraper:
             last_height = new_height
             logger.debug(f"New height: {new_height}")
 
+    def __feed_end_reached(self) -> bool:
+        return bool(self.selectors.find_elements(self.driver, 'feed_end'))
+
+    def __scroll_feed(self, timeout: float) -> bool:
+        """Scroll the feed to the bottom and wait until Maps appends cards or shows the end of the list.
+        Returns False when nothing changed within `timeout` seconds."""
+        card_css = self.selectors.css('feed_card')
+        before = len(self.driver.find_elements(By.CSS_SELECTOR, card_css))
+        try:
+            feed = self.selectors.find_element(self.driver, 'feed', self.page_url)
+        except SelectorMissingError:
+            self.driver.execute_script("window.scrollTo(0, document.body.scrollHeight);")
+            self.waits.for_network_idle(self.driver)
+            return len(self.driver.find_elements(By.CSS_SELECTOR, card_css)) > before
+        if self.replay_fixtures:
+            # A fixture snapshot never grows
+            return False
+        mutations = self.driver.execute_script(FEED_OBSERVER_JS, feed)
+        self.driver.execute_script('arguments[0].scrollTop = arguments[0].scrollHeight', feed)
+        deadline = time.time() + timeout
+        while time.time() < deadline:
+            time.sleep(0.25)
+            # Only recount the cards once the feed has changed
+            if self.driver.execute_script('return arguments[0].__crawlerMutations', feed) == mutations:
+                continue
+            if self.__feed_end_reached() or len(self.driver.find_elements(By.CSS_SELECTOR, card_css)) > before:
+                return True
+        logger.info(f"No new cards {timeout:.0f}s after scrolling the feed")
+        return False
+
+    def __card(self, url: Optional[str], name: Optional[str], rating_text: Optional[str],
+               count_text: Optional[str], category_text: Optional[str] = None) -> Optional[Dict]:
+        """Build a search result card from the texts shown on it."""
+        if not url:
+            return None
+        card = {
+            'url': url,
+            'cid': extract_cid(url),
+            'name': name
+        }
+        rating = parse_decimal(rating_text)
+        if rating is not None:
+            card['overall_rating'] = rating
+        if count_text:
+            card['total_reviews'] = parse_count(count_text)
+        if category_text and category_text.strip():
+            card['category'] = category_text.strip()
+        return card
+
+    def __parse_card(self, element) -> Optional[Dict]:
+        """Extract the data shown on a single search result card."""
+        try:
+            link = self.selectors.find_element(element, 'feed_card_link', self.page_url)
+        except SelectorMissingError:
+            return None
+
+        def text(field):
+            try:
+                return self.selectors.find_element(element, field, self.page_url).text
+            except SelectorMissingError:
+                return None
+
+        return self.__card(link.get_attribute('href'), link.get_attribute('aria-label'),
+                           text('feed_card_rating'), text('feed_card_review_count'), text('feed_card_category'))
+
+    def __parse_card_html(self, element: BeautifulSoup) -> Optional[Dict]:
+        """Extract the data shown on a search result card from a snapshot of the feed."""
+        link = self.selectors.select_one(element, 'feed_card_link')
+        if link is None:
+            return None
+        rating = self.selectors.select_one(element, 'feed_card_rating')
+        count = self.selectors.select_one(element, 'feed_card_review_count')
+        category = self.selectors.select_one(element, 'feed_card_category')
+        return self.__card(link.get('href'), link.get('aria-label'),
+                           rating.get_text(strip=True) if rating else None,
+                           count.get_text(strip=True) if count else None,
+                           category.get_text(strip=True) if category else None)
+
+    def __visible_cards(self) -> List[Dict]:
+        """Parse the result cards currently in the feed, from one HTML snapshot or node by node."""
+        if self.feed_snapshots:
+            response = BeautifulSoup(self.driver.page_source, 'html.parser')
+            cards = (self.__parse_card_html(element) for element in self.selectors.select(response, 'feed_card'))
+        else:
+            cards = (self.__parse_card(element) for element in self.selectors.find_elements(self.driver, 'feed_card'))
+        return [card for card in cards if card]
+
     def search_restaurants(self, search_url: str, max_results: int = 20) -> List[str]:
         """Search for restaurants and return their URLs."""
-        self.driver.get(search_url)
-        self.__click_on_cookie_agreement()
+        return [card['url'] for card in self.iter_search_cards(search_url, max_results)]
+
+    def iter_search_cards(self, search_url: str, max_results: int = 20,
+                          card_filter: Optional[CardFilter] = None) -> Iterator[Dict]:
+        """Search for restaurants and yield each result card as soon as it is extracted.
+        Cards below the thresholds of `card_filter`, or of the scraper's own, are skipped and do not count
+        towards `max_results`."""
+        card_filter = card_filter or self.card_filter
+        self.__navigate(search_url)
         
-        wait = WebDriverWait(self.driver, MAX_WAIT)
-        wait.until(EC.presence_of_element_located((By.CLASS_NAME, 'Nv2PK')))
+        wait = WebDriverWait(self.driver, self.waits.scaled(MAX_WAIT))
+        try:
+            wait.until(EC.presence_of_element_located((By.CSS_SELECTOR, self.selectors.css('feed_card'))))
+        except TimeoutException:
+            raise SelectorMissingError('feed_card', search_url)
         
-        urls = []
+        # Google removes off-screen cards from long feeds, so cards are
+        # harvested on every scroll and accumulated by CID. self.cards may be
+        # shared with other scrapers, so it is added to but never reset
+        found = {}
         scrolls = 0
+        qualifying = 0
+        stalls = 0
+        deadline = time.time() + self.feed_max_seconds
+        outcome = 'max_results'
         
-        while len(urls) < max_results and scrolls < MAX_SCROLLS:
-            elements = self.driver.find_elements(By.CLASS_NAME, 'Nv2PK')
-            
-            for element in elements:
-                try:
-                    link = element.find_element(By.CSS_SELECTOR, 'a.hfpxzc')
-                    url = link.get_attribute('href')
-                    if url and url not in urls:
-                        urls.append(url)
-                except:
-                    continue
+        while qualifying < max_results:
+            with span('extraction', scroll=scrolls):
+                # Let late-loading cards render before enumerating them
+                self.waits.for_stable_count(self.driver, 'a[href*="maps/place"]', self.feed_stable_window,
+                                            FEED_STABLE_TIMEOUT)
+                visible = self.__visible_cards()
+            for card in visible:
+                key = card['cid'] or card['url']
+                if key not in found:
+                    found[key] = card
+                    self.cards[key] = card
+                    if card_filter and not card_filter.accepts(card):
+                        continue
+                    qualifying += 1
+                    yield card
                 
-                if len(urls) >= max_results:
+                if qualifying >= max_results:
                     break
+            if qualifying >= max_results:
+                break
+            if self.__feed_end_reached():
+                outcome = 'end_of_list'
+                break
+            if stalls >= FEED_MAX_STALLS:
+                outcome = 'stalled'
+                break
+            if time.time() >= deadline:
+                outcome = 'timeout'
+                break
             
-            self.driver.execute_script("window.scrollTo(0, document.body.scrollHeight);")
-            time.sleep(2)
+            with span('feed_scroll', scroll=scrolls):
+                grew = self.__scroll_feed(min(self.waits.scaled(FEED_GROWTH_TIMEOUT), max(deadline - time.time(), 0)))
+            stalls = 0 if grew else stalls + 1
             scrolls += 1
+            self.progress.emit('feed_scroll', scroll=scrolls, cards=len(found), max_results=max_results)
         
-        logger.info(f"Found {len(urls)} restaurants")
-        return urls[:max_results]
+        self.last_search_exhausted = outcome == 'end_of_list'
+        if card_filter and card_filter.active:
+            logger.info(f"Found {len(found)} restaurants, {qualifying} meeting {card_filter} ({outcome})")
+        else:
+            logger.info(f"Found {len(found)} restaurants ({outcome})")
+        if self.fixture_dir:
+            # Cards scrolled out of the feed are gone from the DOM, so only the ones still shown are expected
+            self.__save_fixture('search', expected={'cards': self.__visible_cards()})
+        self.progress.emit('feed_done', force=True, scrolls=scrolls, cards=len(found), outcome=outcome,
+                           exhausted=self.last_search_exhausted)
+        if self.result_history:
+            self.last_search_anomalous = self.result_history.record(search_url, len(found))
 
     def __get_review_text(self, review):
         try:
-            text_element = review.find('span', class_='wiI7pd')
+            text_element = self.selectors.select_one(review, 'review_text')
             if text_element:
                 return self.__filter_string(text_element.text)
             return None
@@ -504,7 +1225,7 @@ class GoogleMapsScraper:
 
     def __get_review_date(self, review):
         try:
-            date_element = review.find('span', class_='rsqaWe')
+            date_element = self.selectors.select_one(review, 'review_date')
             if date_element:
                 return date_element.text.strip()
             return None
@@ -513,17 +1234,17 @@ class GoogleMapsScraper:
 
     def __get_review_rating(self, review):
         try:
-            rating_element = review.find('span', class_='kvMYJc')
+            rating_element = self.selectors.select_one(review, 'review_rating')
             if rating_element and 'aria-label' in rating_element.attrs:
                 rating_text = rating_element['aria-label']
-                return float(rating_text.split()[0])
+                return parse_decimal(rating_text)
             return None
         except:
             return None
 
     def __get_review_username(self, review):
         try:
-            username_element = review.find('div', class_='d4r55')
+            username_element = self.selectors.select_one(review, 'reviewer_name')
             if username_element:
                 return username_element.text.strip()
             return None
@@ -532,19 +1253,19 @@ class GoogleMapsScraper:
 
     def __get_reviewer_review_count(self, review):
         try:
-            count_element = review.find('div', class_='RfnDt')
+            count_element = self.selectors.select_one(review, 'reviewer_stats', record=False)
             if count_element:
                 count_text = count_element.text.strip()
-                match = re.search(r'(\d+)\s+reviews?', count_text)
+                match = re.search(rf'(\d[\d.,]*)\s*{REVIEW_WORDS}', count_text, re.IGNORECASE)
                 if match:
-                    return int(match.group(1))
+                    return parse_count(match.group(1))
             return None
         except:
             return None
 
     def __get_reviewer_photo_count(self, review):
         try:
-            count_element = review.find('div', class_='RfnDt')
+            count_element = self.selectors.select_one(review, 'reviewer_stats', record=False)
             if count_element:
                 count_text = count_element.text.strip()
                 match = re.search(r'(\d+)\s+photos?', count_text)
@@ -556,7 +1277,7 @@ class GoogleMapsScraper:
 
     def __get_reviewer_url(self, review):
         try:
-            url_element = review.find('button', class_='WEBjve')
+            url_element = self.selectors.select_one(review, 'reviewer_url', record=False)
             if url_element and 'data-href' in url_element.attrs:
                 return url_element['data-href']
             return None
@@ -0,0 +1,88 @@
+"""
+Grid-based area coverage.
+A single Maps search stops at roughly 120 results, so a city is covered
+by splitting its bounding box (or a circle around a centre) into cells,
+searching each cell at a zoom level that fits it, and deduplicating the
+results by CID.
+"""
+
+import logging
+import math
+from typing import Dict, Iterator, List, Optional, Tuple
+from urllib.parse import quote_plus
+
+from ..enrichment.geo import haversine_m
+
+logger = logging.getLogger(__name__)
+
+KM_PER_DEGREE_LAT = 111.32
+# Meters per pixel at zoom 0 on the equator, and the width of the results map in pixels
+METERS_PER_PIXEL_Z0 = 156543.03
+MAP_WIDTH_PX = 1000
+MIN_ZOOM, MAX_ZOOM = 10, 18
+
+BoundingBox = Tuple[float, float, float, float]  # south, west, north, east
+
+def parse_bbox(text: str) -> BoundingBox:
+    """Parse "south,west,north,east" into a bounding box."""
+    parts = [float(p) for p in text.split(',')]
+    if len(parts) != 4 or parts[0] >= parts[2] or parts[1] >= parts[3]:
+        raise ValueError(f"Invalid bounding box '{text}', expected south,west,north,east")
+    return tuple(parts)
+
+def bbox_around(lat: float, lng: float, radius_km: float) -> BoundingBox:
+    """Return the bounding box of a circle."""
+    d_lat = radius_km / KM_PER_DEGREE_LAT
+    d_lng = radius_km / (KM_PER_DEGREE_LAT * math.cos(math.radians(lat)))
+    return lat - d_lat, lng - d_lng, lat + d_lat, lng + d_lng
+
+def zoom_for(cell_km: float, lat: float) -> int:
+    """Return the zoom level at which a cell roughly fills the results map."""
+    meters_per_pixel = cell_km * 1000 / MAP_WIDTH_PX
+    zoom = math.log2(METERS_PER_PIXEL_Z0 * math.cos(math.radians(lat)) / meters_per_pixel)
+    return max(MIN_ZOOM, min(MAX_ZOOM, int(zoom)))
+
+def grid_cells(bbox: BoundingBox, cell_km: float,
+               center: Optional[Tuple[float, float]] = None, radius_km: Optional[float] = None) -> List[Tuple[float, float, int]]:
+    """Split a bounding box into (lat, lng, zoom) cells, dropping cells outside the circle if given."""
+    south, west, north, east = bbox
+    mid_lat = (south + north) / 2
+    d_lat = cell_km / KM_PER_DEGREE_LAT
+    d_lng = cell_km / (KM_PER_DEGREE_LAT * math.cos(math.radians(mid_lat)))
+    zoom = zoom_for(cell_km, mid_lat)
+    half_diagonal_m = cell_km * 1000 / math.sqrt(2)
+
+    cells = []
+    rows = max(1, math.ceil((north - south) / d_lat - 1e-9))
+    cols = max(1, math.ceil((east - west) / d_lng - 1e-9))
+    for row in range(rows):
+        lat = south + (row + 0.5) * d_lat
+        for col in range(cols):
+            lng = west + (col + 0.5) * d_lng
+            if center and radius_km and haversine_m(*center, lat, lng) > radius_km * 1000 + half_diagonal_m:
+                continue
+            cells.append((round(lat, 6), round(lng, 6), zoom))
+    return cells
+
+def cell_search_url(query: str, lat: float, lng: float, zoom: int) -> str:
+    """Build the Maps search URL of one grid cell."""
+    return f"https://www.google.com/maps/search/{quote_plus(query)}/@{lat},{lng},{zoom}z"
+
+def iter_grid_cards(scraper, query: str, cells: List[Tuple[float, float, int]],
+                    max_per_cell: int = 120) -> Iterator[Dict]:
+    """Search every cell and yield each place once across the whole grid."""
+    seen = set()
+    for index, (lat, lng, zoom) in enumerate(cells, 1):
+        found = new = 0
+        for card in scraper.iter_search_cards(cell_search_url(query, lat, lng, zoom), max_per_cell):
+            found += 1
+            key = card['cid'] or card['url']
+            if key in seen:
+                continue
+            seen.add(key)
+            new += 1
+            yield card
+        logger.info(f"Grid cell {index}/{len(cells)} at {lat},{lng} added {new} places, {len(seen)} in total")
+        # The cap applies to the cell's own results, including places already seen in a neighbouring cell
+        if found >= max_per_cell:
+            logger.warning(f"Cell {lat},{lng} hit the {max_per_cell} result cap, use a smaller cell size")
@@ -0,0 +1,45 @@
+"""
+Identified crawler mode.
+For partners and test environments that require transparency over
+stealth: the browser announces itself with a user-agent suffix and a
+contact URL, and the crawl runs one place at a time under conservative
+per-domain rate limits.
+"""
+
+import logging
+from typing import Dict
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_AGENT = 'SmartDineBot/1.0'
+# Jobs per second allowed per host, whatever the configured limits say
+POLITE_RATE = 6 / 60
+POLITE_MIN_DELAY = 5.0
+POLITE_CONCURRENCY = 1
+
+class CrawlerIdentity:
+    """User-agent suffix and contact details announced to the sites we crawl."""
+
+    def __init__(self, contact_url: str, agent: str = DEFAULT_AGENT):
+        if not contact_url:
+            raise ValueError("Identified crawler mode requires CRAWLER_CONTACT_URL")
+        self.contact_url = contact_url
+        self.agent = agent
+
+    def user_agent(self, base: str) -> str:
+        """Return `base` with the crawler identification appended."""
+        return f"{base} {self.agent} (+{self.contact_url})"
+
+    def apply_driver(self, driver):
+        """Override the user agent of a running driver and send the contact URL with every request."""
+        base = driver.execute_script('return navigator.userAgent')
+        driver.execute_cdp_cmd('Network.enable', {})
+        driver.execute_cdp_cmd('Network.setUserAgentOverride', {'userAgent': self.user_agent(base)})
+        driver.execute_cdp_cmd('Network.setExtraHTTPHeaders', {'headers': {'From': self.contact_url}})
+        logger.info(f"Identified crawler mode: {self.agent} (+{self.contact_url})")
+
+def polite_rate_limits(rate_limits: Dict[str, float]) -> Dict[str, float]:
+    """Cap every configured host rate at POLITE_RATE, keeping stricter ones."""
+    limits = {host: min(rate, POLITE_RATE) for host, rate in rate_limits.items()}
+    limits.setdefault('www.google.com', POLITE_RATE)
+    return limits
@@ -0,0 +1,183 @@
+"""
+Persistent place job queue backed by Redis.
+Each job is a hash holding its URL, status (pending/running/failed/done),
+attempt count and last error with its type. Pending job IDs sit in a list
+that workers pop atomically into a running list; failed jobs are retried
+until they run out of attempts, or straight away for errors that retrying
+cannot fix, and then land on a dead-letter list.
+
+Claiming a job takes a lease on it that the worker renews with heartbeats.
+Only running jobs whose lease expired, i.e. whose worker died or hung, are
+requeued by recover(), so it is safe to call while other workers run.
+"""
+
+import hashlib
+import json
+import logging
+import time
+from typing import Dict, List, Optional
+
+import redis
+
+from ..storage.idempotency import extract_cid
+from .tracing import inject
+
+logger = logging.getLogger(__name__)
+
+PENDING = 'pending'
+RUNNING = 'running'
+FAILED = 'failed'
+DONE = 'done'
+
+DEFAULT_MAX_ATTEMPTS = 3
+DEFAULT_LEASE_SECONDS = 300
+
+# Pop the next pending job onto the running list and lease it in one step,
+# so recover() never sees a running job without a lease
+CLAIM_SCRIPT = """
+local job_id = redis.call('RPOPLPUSH', KEYS[1], KEYS[2])
+if job_id then
+    redis.call('ZADD', KEYS[3], ARGV[1], job_id)
+end
+return job_id
+"""
+
+# Requeue the running jobs whose lease expired before ARGV[1]
+RECOVER_SCRIPT = """
+local recovered = 0
+for _, job_id in ipairs(redis.call('LRANGE', KEYS[1], 0, -1)) do
+    local lease = redis.call('ZSCORE', KEYS[3], job_id)
+    if not lease or tonumber(lease) < tonumber(ARGV[1]) then
+        redis.call('LREM', KEYS[1], 0, job_id)
+        redis.call('ZREM', KEYS[3], job_id)
+        redis.call('LPUSH', KEYS[2], job_id)
+        redis.call('HSET', ARGV[2] .. job_id, 'status', 'pending', 'updated_at', ARGV[1])
+        recovered = recovered + 1
+    end
+end
+return recovered
+"""
+
+def job_id_for(url: str) -> str:
+    """Stable job ID for a place URL, so enqueuing the same place twice is a no-op."""
+    cid = extract_cid(url)
+    if cid:
+        return f"cid:{cid}"
+    return f"url:{hashlib.sha1(url.encode('utf-8')).hexdigest()}"
+
+class RedisJobQueue:
+    """Place jobs persisted in Redis with retries and a dead-letter list."""
+
+    def __init__(self, redis_url: str, name: str = 'crawler', max_attempts: int = DEFAULT_MAX_ATTEMPTS,
+                 lease_seconds: float = DEFAULT_LEASE_SECONDS):
+        self.redis = redis.Redis.from_url(redis_url, decode_responses=True)
+        self.name = name
+        self.max_attempts = max_attempts
+        self.lease_seconds = lease_seconds
+        self.pending_key = f"{name}:pending"
+        self.running_key = f"{name}:running"
+        self.dead_key = f"{name}:dead"
+        # Lease expiry time of every running job
+        self.leases_key = f"{name}:leases"
+        self._claim = self.redis.register_script(CLAIM_SCRIPT)
+        self._recover = self.redis.register_script(RECOVER_SCRIPT)
+
+    def _job_key(self, job_id: str) -> str:
+        return f"{self.name}:job:{job_id}"
+
+    def enqueue(self, url: str, force: bool = False) -> Optional[str]:
+        """Add a place job; returns its ID, or None if the job already exists and `force` is off."""
+        job_id = job_id_for(url)
+        key = self._job_key(job_id)
+        if not force and self.redis.exists(key):
+            return None
+        pipe = self.redis.pipeline()
+        pipe.hset(key, mapping={
+            'url': url,
+            'status': PENDING,
+            'attempts': 0,
+            'error': '',
+            'error_type': '',
+            # Trace context of the enqueuer, continued by the worker that runs the job
+            'trace': json.dumps(inject()),
+            'updated_at': time.time(),
+        })
+        pipe.lrem(self.dead_key, 0, job_id)
+        pipe.lpush(self.pending_key, job_id)
+        pipe.execute()
+        return job_id
+
+    def claim(self, timeout: int = 5) -> Optional[Dict]:
+        """Move the next pending job to running, lease it and return it, or None after `timeout` seconds."""
+        deadline = time.time() + timeout
+        while True:
+            job_id = self._claim(keys=[self.pending_key, self.running_key, self.leases_key],
+                                 args=[time.time() + self.lease_seconds])
+            if job_id or time.time() >= deadline:
+                break
+            time.sleep(min(0.5, max(0, deadline - time.time())))
+        if not job_id:
+            return None
+        key = self._job_key(job_id)
+        self.redis.hset(key, mapping={'status': RUNNING, 'updated_at': time.time()})
+        attempts = self.redis.hincrby(key, 'attempts', 1)
+        url, trace = self.redis.hmget(key, 'url', 'trace')
+        return {'id': job_id, 'url': url, 'attempts': attempts, 'trace': json.loads(trace or '{}')}
+
+    def heartbeat(self, job_id: str) -> bool:
+        """Renew the lease of a running job; False if it expired and the job was requeued."""
+        return bool(self.redis.zadd(self.leases_key, {job_id: time.time() + self.lease_seconds}, xx=True, ch=True))
+
+    def complete(self, job_id: str):
+        """Mark a running job as done."""
+        pipe = self.redis.pipeline()
+        pipe.lrem(self.running_key, 0, job_id)
+        pipe.zrem(self.leases_key, job_id)
+        pipe.hset(self._job_key(job_id), mapping={
+            'status': DONE, 'error': '', 'error_type': '', 'updated_at': time.time()
+        })
+        pipe.execute()
+
+    def fail(self, job_id: str, error: str, kind: str = 'error', retryable: bool = True):
+        """Requeue a failed job, or move it to the dead-letter list once out of attempts
+        or when the error is not `retryable`."""
+        key = self._job_key(job_id)
+        attempts = int(self.redis.hget(key, 'attempts') or 0)
+        pipe = self.redis.pipeline()
+        pipe.lrem(self.running_key, 0, job_id)
+        pipe.zrem(self.leases_key, job_id)
+        if retryable and attempts < self.max_attempts:
+            pipe.hset(key, mapping={'status': PENDING, 'error': error, 'error_type': kind, 'updated_at': time.time()})
+            pipe.lpush(self.pending_key, job_id)
+            logger.warning(f"Job {job_id} failed (attempt {attempts}/{self.max_attempts}), requeued: {error}")
+        else:
+            pipe.hset(key, mapping={'status': FAILED, 'error': error, 'error_type': kind, 'updated_at': time.time()})
+            pipe.lpush(self.dead_key, job_id)
+            reason = f"{attempts} times" if retryable else f"with non-retryable {kind}"
+            logger.error(f"Job {job_id} failed {reason}, moved to dead letters: {error}")
+        pipe.execute()
+
+    def recover(self) -> int:
+        """Requeue running jobs whose lease expired because their worker died or stopped heartbeating."""
+        recovered = int(self._recover(keys=[self.running_key, self.pending_key, self.leases_key],
+                                      args=[time.time(), self._job_key('')]))
+        if recovered:
+            logger.info(f"Requeued {recovered} jobs whose lease expired")
+        return recovered
+
+    def status(self, job_id: str) -> Optional[Dict]:
+        """Return the stored state of a job."""
+        job = self.redis.hgetall(self._job_key(job_id))
+        return job or None
+
+    def dead_letters(self) -> List[Dict]:
+        """Return the permanently failing jobs."""
+        return [dict(self.status(job_id) or {}, id=job_id) for job_id in self.redis.lrange(self.dead_key, 0, -1)]
+
+    def counts(self) -> Dict[str, int]:
+        """Return the number of pending, running and dead-lettered jobs."""
+        return {
+            PENDING: self.redis.llen(self.pending_key),
+            RUNNING: self.redis.llen(self.running_key),
+            'dead': self.redis.llen(self.dead_key),
+        }
@@ -0,0 +1,68 @@
+"""
+Crawl locale.
+Sets the Maps interface language (hl) and region (gl) through the URL
+parameters, Chrome's UI language and the Accept-Language header, and
+parses numbers the way they are displayed in that locale: "4,5" and
+"4.5" are both ratings, "1.234", "1,234" and "1 234" are all counts.
+"""
+
+import re
+from typing import Optional
+from urllib.parse import parse_qsl, urlencode, urlparse, urlunparse
+
+# "reviews" in the interface languages Maps is most often crawled in
+REVIEW_WORDS = r'(?:reviews?|Rezensionen|Rezension|avis|reseñas|reseña|recensioni|recensione|avaliações|avaliação|' \
+               r'recensies|recensie|opinii|opinie|yorum|отзыв\w*|レビュー|条评论|리뷰)'
+MAGNITUDES = {'k': 1_000, 'm': 1_000_000, 'tsd': 1_000, 'mil': 1_000}
+
+class CrawlLocale:
+    """Interface language and region of a crawl."""
+
+    def __init__(self, hl: str = 'en', gl: Optional[str] = None):
+        self.hl = hl
+        self.gl = gl
+
+    def apply_url(self, url: str) -> str:
+        """Return the URL with its hl/gl parameters set to this locale."""
+        parsed = urlparse(url)
+        params = dict(parse_qsl(parsed.query, keep_blank_values=True))
+        params['hl'] = self.hl
+        if self.gl:
+            params['gl'] = self.gl
+        return urlunparse(parsed._replace(query=urlencode(params)))
+
+    def accept_language(self) -> str:
+        """Accept-Language value preferring this locale, e.g. "de-DE,de;q=0.9,en;q=0.5"."""
+        language = self.hl.split('-')[0]
+        tags = [f"{language}-{self.gl.upper()}"] if self.gl and '-' not in self.hl else [self.hl]
+        if language not in tags:
+            tags.append(f"{language};q=0.9")
+        if language != 'en':
+            tags.append('en;q=0.5')
+        return ','.join(tags)
+
+    def apply_options(self, options):
+        """Set Chrome's UI language and Accept-Language header."""
+        options.add_argument(f'--lang={self.hl}')
+        options.add_experimental_option('prefs', {'intl.accept_languages': self.accept_language()})
+
+def parse_decimal(text: Optional[str]) -> Optional[float]:
+    """Parse the first decimal such as "4.5" or "4,5" in a text."""
+    match = re.search(r'\d+(?:[.,]\d+)?', text or '')
+    return float(match.group(0).replace(',', '.')) if match else None
+
+def parse_count(text: Optional[str]) -> Optional[int]:
+    """Parse the first count in a text whatever its grouping separators,
+    including abbreviations such as "1.2K" or "1,2 Tsd."."""
+    match = re.search(r'(\d(?:[\d.,\s\u00a0\u202f]*\d)?)\s*(k|m|tsd|mil)?\b', text or '', re.IGNORECASE)
+    if not match:
+        return None
+    digits, magnitude = match.group(1).strip(), (match.group(2) or '').lower()
+    if magnitude:
+        return int(float(re.sub(r'[\s\u00a0\u202f]', '', digits).replace(',', '.')) * MAGNITUDES[magnitude])
+    return int(re.sub(r'\D', '', digits))
+
+def review_count_label(text: str) -> Optional[int]:
+    """Return N from an aria-label such as "1,234 reviews" or "1.234 Rezensionen"."""
+    match = re.match(rf'^\s*(\d[\d.,\s\u00a0\u202f]*)\s*{REVIEW_WORDS}\s*$', text, re.IGNORECASE)
+    return parse_count(match.group(1)) if match else None
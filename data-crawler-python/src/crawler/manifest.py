@@ -0,0 +1,102 @@
+"""
+Run manifests.
+Every crawl run writes manifest_<job id>.json next to its output, recording
+what produced the data: the crawler version and git commit, the selector
+registry version, the query and location, the settings the run used,
+when it started and ended, and its counts and errors. Secrets in the
+settings, and credentials in URLs, are redacted.
+"""
+
+import json
+import logging
+import os
+import re
+import subprocess
+from datetime import datetime, timezone
+from typing import Dict, Optional
+
+from .. import __version__
+
+logger = logging.getLogger(__name__)
+
+SECRET_WORDS = ('secret', 'password', 'token', 'key')
+URL_CREDENTIALS = re.compile(r'(://)[^/@\s]+@')
+
+def git_commit() -> Optional[str]:
+    """Return the commit the crawler runs from; images without .git set CRAWLER_GIT_COMMIT instead."""
+    commit = os.getenv('CRAWLER_GIT_COMMIT')
+    if commit:
+        return commit
+    try:
+        return subprocess.run(['git', 'rev-parse', 'HEAD'], cwd=os.path.dirname(__file__), capture_output=True,
+                              text=True, timeout=5, check=True).stdout.strip() or None
+    except Exception as e:
+        logger.debug(f"Could not read the git commit: {str(e)}")
+        return None
+
+def _redact(name: str, value):
+    if value and any(word in name.lower() for word in SECRET_WORDS):
+        return '***'
+    if isinstance(value, str):
+        return URL_CREDENTIALS.sub(r'\1***@', value)
+    if isinstance(value, list):
+        return [_redact(name, v) for v in value]
+    return value
+
+def settings_snapshot(settings) -> Dict:
+    """Return the run's settings as JSON values with secrets redacted."""
+    snapshot = {}
+    for name, value in sorted(vars(settings).items()):
+        value = _redact(name, value)
+        try:
+            json.dumps(value)
+        except TypeError:
+            value = str(value)
+        snapshot[name] = value
+    return snapshot
+
+class RunManifest:
+    """Provenance of one crawl run, written when the run ends."""
+
+    def __init__(self, job_id: str, settings, selectors_version: Optional[str] = None):
+        self.job_id = job_id
+        self.settings = settings
+        self.selectors_version = selectors_version
+        self.started_at = datetime.now(timezone.utc)
+
+    def to_dict(self, status: str, report: Dict) -> Dict:
+        settings = self.settings
+        return {
+            'job_id': self.job_id,
+            'status': status,
+            'crawler_version': __version__,
+            'git_commit': git_commit(),
+            'selectors_version': self.selectors_version,
+            'query': {
+                'search_query': settings.search_query,
+                'search_url': settings.search_url,
+                'area': settings.area,
+                'grid_bbox': settings.grid_bbox,
+                'grid_center': settings.grid_center,
+                'radius_km': settings.radius_km,
+            },
+            'settings': settings_snapshot(settings),
+            'started_at': self.started_at.isoformat(),
+            'finished_at': datetime.now(timezone.utc).isoformat(),
+            'counts': report.get('counts'),
+            'output': report.get('output'),
+            'errors': report.get('errors'),
+        }
+
+    def save(self, directory: str, status: str, report: Dict) -> Optional[str]:
+        """Write the manifest under `directory` and return its path."""
+        path = os.path.join(directory, f"manifest_{self.job_id}.json")
+        try:
+            os.makedirs(directory, exist_ok=True)
+            with open(path, 'w', encoding='utf-8') as f:
+                json.dump(self.to_dict(status, report), f, indent=2)
+        except Exception as e:
+            logger.error(f"Could not write the run manifest {path}: {str(e)}")
+            return None
+        logger.info(f"Wrote run manifest to {path}")
+        return path
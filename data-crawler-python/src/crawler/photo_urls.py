@@ -0,0 +1,35 @@
+"""
+Google photo URL rewriting.
+Maps serves photos from googleusercontent.com with the requested size in
+the URL, either as a "=w80-h142-k-no" suffix or as a "/w80-h142-k-no/"
+path segment. Rewriting that part fetches the same photo at another size.
+"""
+
+import re
+from typing import Optional
+
+SIZE_OPTION = re.compile(r'(?:^|-)[swh]\d+')
+SIZE_SEGMENT = re.compile(r'/((?:[swh]\d+)(?:-[a-z0-9]+)*)/')
+
+def is_google_photo(url: Optional[str]) -> bool:
+    return bool(url) and 'googleusercontent.com' in url
+
+def base_photo_url(url: str) -> str:
+    """Return the URL without its size options, used to deduplicate photos."""
+    if '=' in url.rsplit('/', 1)[-1]:
+        return url.rsplit('=', 1)[0]
+    return SIZE_SEGMENT.sub('/', url, count=1)
+
+def resize_photo_url(url: Optional[str], size: str = 'w1200') -> Optional[str]:
+    """Rewrite a Google photo URL to request `size`, e.g. "w1200", "w1200-h800" or "s0" for the original."""
+    if not is_google_photo(url):
+        return url
+    last_segment = url.rsplit('/', 1)[-1]
+    if '=' in last_segment:
+        base, options = url.rsplit('=', 1)
+        if SIZE_OPTION.search(options):
+            return f"{base}={size}"
+        return f"{url}-{size}" if options else f"{base}={size}"
+    if SIZE_SEGMENT.search(url):
+        return SIZE_SEGMENT.sub(f'/{size}/', url, count=1)
+    return f"{url}={size}"
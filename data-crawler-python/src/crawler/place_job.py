@@ -0,0 +1,75 @@
+"""
+Parallel place scraping.
+Details and the reviews pane of the same place are scraped at the same
+time in two browsers, then merged, roughly halving per-place latency for
+review-heavy listings. In incremental mode the pane is sorted newest first
+and pagination stops at the first review already stored for the place.
+The Menu tab is scraped afterwards with the detail browser when enabled.
+"""
+
+import logging
+from concurrent.futures import ThreadPoolExecutor
+from typing import Dict, List, Optional
+
+logger = logging.getLogger(__name__)
+
+def _scrape_reviews(review_scraper, url: str, known_review_ids: Optional[set] = None) -> List[Dict]:
+    if known_review_ids:
+        if review_scraper.open_reviews_tab(url, newest_first=True):
+            return review_scraper.get_reviews(0, seen_review_ids=known_review_ids)
+        # Stopping at known reviews is only safe when the newest come first
+        logger.warning(f"Falling back to a full review crawl for {url}")
+    if not review_scraper.open_reviews_tab(url):
+        return []
+    return review_scraper.get_reviews(0)
+
+def scrape_menu(scraper, restaurant: Dict, url: str) -> bool:
+    """Attach the Menu tab's items as `menu` and its photos as `menu_images`; returns True if a menu was found."""
+    try:
+        menu = scraper.get_menu(url)
+    except Exception as e:
+        logger.error(f"Menu scraping failed for {url}: {str(e)}")
+        return False
+    if not menu:
+        return False
+    restaurant['menu'] = menu['items']
+    restaurant['menu_images'] = menu['images']
+    return True
+
+def scrape_place(detail_scraper, review_scraper, url: str, known_review_ids: Optional[set] = None) -> Dict:
+    """Scrape a place's details and its reviews pane, concurrently when two scrapers are given.
+    Reviews in `known_review_ids` end the pane pagination and are not returned again."""
+    if review_scraper is detail_scraper:
+        result = detail_scraper.get_account(url)
+        try:
+            reviews = _scrape_reviews(review_scraper, url, known_review_ids)
+        except Exception as e:
+            logger.error(f"Review pane scraping failed for {url}: {str(e)}")
+            reviews = []
+    else:
+        with ThreadPoolExecutor(max_workers=2) as executor:
+            details = executor.submit(detail_scraper.get_account, url)
+            pane_reviews = executor.submit(_scrape_reviews, review_scraper, url, known_review_ids)
+            result = details.result()
+            try:
+                reviews = pane_reviews.result()
+            except Exception as e:
+                logger.error(f"Review pane scraping failed for {url}: {str(e)}")
+                reviews = []
+
+    restaurant_id = result.get('restaurant', {}).get('_id')
+    if not restaurant_id:
+        return result
+
+    # The review pass ran before the restaurant ID was known, so IDs are
+    # assigned here; pane reviews replace the overview's copies
+    merged = {review['_id']: review for review in result.get('reviews', [])}
+    for review in reviews:
+        review['restaurant_id'] = restaurant_id
+        review['_id'] = f"{restaurant_id}_review_{review['review_id']}"
+        review['id_review'] = review['_id']
+        review['job_id'] = detail_scraper.job.job_id
+        merged[review['_id']] = review
+    result['reviews'] = list(merged.values())
+    logger.info(f"Merged {len(reviews)} pane reviews for {url}, {len(merged)} in total")
+    return result
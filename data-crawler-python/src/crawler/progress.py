@@ -0,0 +1,43 @@
+"""
+Structured progress events.
+Emits one JSON object per line on the "smartdine.progress" logger while
+feeds and review panes are scrolled, so dashboards and ETA estimates can
+follow a crawl in real time instead of waiting for the final counts.
+"""
+
+import json
+import logging
+import time
+from typing import Dict, Optional
+
+from ..models.job_context import JobContext
+
+logger = logging.getLogger('smartdine.progress')
+
+DEFAULT_INTERVAL = 5.0
+
+class ProgressReporter:
+    """Rate-limited emitter of progress events for one job."""
+
+    def __init__(self, job: Optional[JobContext] = None, interval: float = DEFAULT_INTERVAL):
+        self.job = job or JobContext()
+        self.interval = interval
+        self._last_emit: Dict[str, float] = {}
+
+    def due(self, event: str) -> bool:
+        """Return True if an event of this type may be emitted now."""
+        return time.time() - self._last_emit.get(event, 0) >= self.interval
+
+    def emit(self, event: str, force: bool = False, **fields):
+        """Log a progress event unless one of the same type was emitted within the interval."""
+        if not force and not self.due(event):
+            return
+        self._last_emit[event] = time.time()
+        record = {
+            'ts': round(time.time(), 3),
+            'event': event,
+            'job_id': self.job.job_id,
+            'tenant': self.job.tenant,
+            **fields,
+        }
+        logger.info(json.dumps(record, ensure_ascii=False, default=str))
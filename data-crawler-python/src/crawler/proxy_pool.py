@@ -0,0 +1,80 @@
+"""
+Proxy pool for browser sessions.
+Hands out HTTP/SOCKS5 proxies to scrapers, tracks failures per proxy and
+benches a proxy for a cooldown period after repeated failures.
+"""
+
+import logging
+import threading
+import time
+from typing import Dict, List, Optional
+
+logger = logging.getLogger(__name__)
+
+MAX_CONSECUTIVE_FAILURES = 3
+COOLDOWN_SECONDS = 600
+
+class ProxyPool:
+    """Rotating pool of proxies with per-proxy health tracking."""
+
+    def __init__(self, proxies: List[str], max_failures: int = MAX_CONSECUTIVE_FAILURES,
+                 cooldown: float = COOLDOWN_SECONDS):
+        if not proxies:
+            raise ValueError("Proxy pool needs at least one proxy")
+        self.max_failures = max_failures
+        self.cooldown = cooldown
+        self.stats: Dict[str, Dict] = {
+            proxy: {'in_use': 0, 'failures': 0, 'successes': 0, 'benched_until': 0.0}
+            for proxy in proxies
+        }
+        self._lock = threading.Lock()
+
+    @classmethod
+    def from_file(cls, path: str) -> 'ProxyPool':
+        """Load proxies from a file with one proxy URL per line; # starts a comment."""
+        with open(path, 'r', encoding='utf-8') as f:
+            proxies = [line.split('#')[0].strip() for line in f]
+        return cls([p for p in proxies if p])
+
+    def acquire(self, exclude: Optional[str] = None) -> str:
+        """Return the least used healthy proxy, falling back to the one benched the shortest."""
+        with self._lock:
+            now = time.time()
+            candidates = [p for p in self.stats if p != exclude] or list(self.stats)
+            healthy = [p for p in candidates if self.stats[p]['benched_until'] <= now]
+            if healthy:
+                proxy = min(healthy, key=lambda p: (self.stats[p]['in_use'], self.stats[p]['failures']))
+            else:
+                proxy = min(candidates, key=lambda p: self.stats[p]['benched_until'])
+                logger.warning(f"All proxies are benched, reusing {proxy}")
+            self.stats[proxy]['in_use'] += 1
+            return proxy
+
+    def release(self, proxy: str):
+        """Return a proxy to the pool."""
+        with self._lock:
+            if proxy in self.stats and self.stats[proxy]['in_use'] > 0:
+                self.stats[proxy]['in_use'] -= 1
+
+    def report_success(self, proxy: str):
+        """Record a successful navigation through a proxy."""
+        with self._lock:
+            if proxy in self.stats:
+                self.stats[proxy]['successes'] += 1
+                self.stats[proxy]['failures'] = 0
+
+    def report_failure(self, proxy: str):
+        """Record a failed navigation and bench the proxy after repeated failures."""
+        with self._lock:
+            if proxy not in self.stats:
+                return
+            stats = self.stats[proxy]
+            stats['failures'] += 1
+            if stats['failures'] >= self.max_failures:
+                stats['benched_until'] = time.time() + self.cooldown
+                logger.warning(f"Benching proxy {proxy} for {self.cooldown}s after {stats['failures']} failures")
+
+    def rotate(self, proxy: str) -> str:
+        """Release a proxy and acquire a different one."""
+        self.release(proxy)
+        return self.acquire(exclude=proxy)
@@ -0,0 +1,47 @@
+"""
+Reconciliation of card-level (search) and page-level (detail) extraction.
+Each field has a precedence rule deciding which pass wins when both passes
+extracted a value and they disagree; every disagreement is logged.
+"""
+
+import logging
+from typing import Dict, List
+
+logger = logging.getLogger(__name__)
+
+# Which extraction pass is trusted for each field. The card shows Google's
+# aggregate rating and review count, while the detail pass only averages
+# the reviews it managed to load. The card's category is read by position
+# and can pick up another label, while the detail pass reads the category
+# line of the place itself.
+FIELD_PRECEDENCE = {
+    'name': 'detail',
+    'overall_rating': 'card',
+    'total_reviews': 'card',
+    'category': 'detail',
+}
+
+def reconcile(card: Dict, place: Dict) -> List[Dict]:
+    """Merge card fields into the detail place in place and return the conflicts."""
+    conflicts = []
+    for field, winner in FIELD_PRECEDENCE.items():
+        card_value = card.get(field)
+        detail_value = place.get(field)
+        if card_value is None:
+            continue
+        if detail_value is None:
+            place[field] = card_value
+            continue
+        if card_value == detail_value:
+            continue
+
+        chosen = card_value if winner == 'card' else detail_value
+        conflicts.append({
+            'field': field,
+            'card': card_value,
+            'detail': detail_value,
+            'chosen': winner
+        })
+        logger.warning(f"Conflict on '{field}' for {place.get('url')}: card={card_value!r} detail={detail_value!r}, using {winner}")
+        place[field] = chosen
+    return conflicts
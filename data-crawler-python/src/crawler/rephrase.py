@@ -0,0 +1,75 @@
+"""
+Retry sparse searches with alternate query phrasings.
+Maps sometimes answers a query for a dense area with only a handful of
+results. When that happens the search is repeated with known-good
+phrasings and the results are merged by CID. The phrasings name the area
+the original search covers, taken from its query ("ramen in Oakland, CA")
+or else from its map viewport.
+"""
+
+import logging
+import re
+from typing import Dict, Iterator, List, Optional
+from urllib.parse import quote_plus, unquote_plus
+
+from ..enrichment.geo import coordinates_from_url
+
+logger = logging.getLogger(__name__)
+
+# Templates filled with the search area, tried in order
+DEFAULT_PHRASINGS = [
+    'restaurants near {area}',
+    'places to eat in {area}',
+    'food in {area}',
+    'dinner in {area}',
+]
+
+SEARCH_QUERY = re.compile(r'/maps/search/([^/?@]+)')
+AREA_IN_QUERY = re.compile(r'\b(?:in|near|around)\s+(.+)$', re.IGNORECASE)
+
+def search_area(search_url: str) -> Optional[str]:
+    """Return the area a search URL covers: the place its query names, else its viewport as "lat,lng"."""
+    query = SEARCH_QUERY.search(search_url or '')
+    if query:
+        match = AREA_IN_QUERY.search(unquote_plus(query.group(1)))
+        if match:
+            return match.group(1).strip()
+    coordinates = coordinates_from_url(search_url)
+    if coordinates:
+        return f"{coordinates[0]},{coordinates[1]}"
+    return None
+
+def parse_phrasings(text: str) -> List[str]:
+    """Parse "|"-separated phrasing templates, e.g. "restaurantes en {area}|comida en {area}"."""
+    return [p.strip() for p in (text or '').split('|') if p.strip()]
+
+def phrasing_url(template: str, area: str) -> str:
+    """Build the Maps search URL of a phrasing template."""
+    return f"https://www.google.com/maps/search/{quote_plus(template.format(area=area))}"
+
+def iter_cards_with_rephrasing(scraper, search_url: str, max_results: int, area: str,
+                               phrasings: List[str], min_results: int) -> Iterator[Dict]:
+    """Yield the cards of a search, then of alternate phrasings while the results stay sparse."""
+    seen = set()
+
+    def search(url: str) -> Iterator[Dict]:
+        for card in scraper.iter_search_cards(url, max_results):
+            key = card['cid'] or card['url']
+            if key not in seen:
+                seen.add(key)
+                yield card
+            if len(seen) >= max_results:
+                return
+
+    yield from search(search_url)
+    sparse = len(seen) < min(min_results, max_results) or scraper.last_search_anomalous
+    if not sparse:
+        return
+
+    logger.warning(f"Only {len(seen)} results for {search_url}, retrying with alternate phrasings")
+    for template in phrasings:
+        if len(seen) >= max_results:
+            break
+        before = len(seen)
+        yield from search(phrasing_url(template, area))
+        logger.info(f"Phrasing '{template}' added {len(seen) - before} places, {len(seen)} in total")
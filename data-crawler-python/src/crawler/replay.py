@@ -0,0 +1,194 @@
+"""
+Record and replay of browser sessions.
+The recorder wraps a live WebDriver and logs every call made on the
+driver and on the elements it returns, together with the result or the
+exception raised. The replay driver answers the same calls from that
+cassette, so full place and search scrapes run without Chrome or network.
+
+Calls are matched by target, name and arguments; repeated identical calls
+are answered in recorded order.
+"""
+
+import importlib
+import json
+import logging
+import threading
+from collections import defaultdict, deque
+from typing import Any, Deque, Dict, List
+
+logger = logging.getLogger(__name__)
+
+DRIVER = 'driver'
+
+class ReplayMissError(RuntimeError):
+    """Raised when the replayed code makes a call that was not recorded."""
+
+def _is_element(value: Any) -> bool:
+    return hasattr(value, 'get_attribute') and hasattr(value, 'find_element') and not hasattr(value, 'get')
+
+def _key(target: str, name: str, args: Any) -> str:
+    return json.dumps([target, name, args], sort_keys=True, default=str)
+
+class Recorder:
+    """Collects the calls of one browser session and writes them as a cassette."""
+
+    def __init__(self, path: str):
+        self.path = path
+        self.entries: List[Dict] = []
+        self._elements: Dict[int, str] = {}
+        # Recorded elements are kept alive so their id() is never reused
+        self._alive: List[Any] = []
+        self._lock = threading.Lock()
+
+    def element_ref(self, element) -> Dict:
+        with self._lock:
+            if id(element) not in self._elements:
+                self._elements[id(element)] = f"e{len(self._elements) + 1}"
+                self._alive.append(element)
+            return {'__element__': self._elements[id(element)]}
+
+    def encode(self, value: Any) -> Any:
+        if _is_element(value):
+            return self.element_ref(value)
+        if isinstance(value, (list, tuple)):
+            return [self.encode(v) for v in value]
+        if isinstance(value, dict):
+            return {k: self.encode(v) for k, v in value.items()}
+        if isinstance(value, (str, int, float, bool)) or value is None:
+            return value
+        return str(value)
+
+    def wrap(self, value: Any) -> Any:
+        """Return recording proxies for elements inside a result."""
+        if _is_element(value):
+            return RecordingProxy(value, self.element_ref(value)['__element__'], self)
+        if isinstance(value, list):
+            return [self.wrap(v) for v in value]
+        return value
+
+    def add(self, target: str, name: str, kind: str, args: Any, result: Any = None, error: BaseException = None):
+        entry = {'target': target, 'name': name, 'kind': kind, 'args': args}
+        if error is not None:
+            entry['error'] = {'type': f"{type(error).__module__}.{type(error).__qualname__}", 'message': str(error)}
+        else:
+            entry['result'] = self.encode(result)
+        with self._lock:
+            self.entries.append(entry)
+
+    def save(self):
+        with open(self.path, 'w', encoding='utf-8') as f:
+            json.dump({'version': 1, 'entries': self.entries}, f, ensure_ascii=False)
+        logger.info(f"Recorded {len(self.entries)} browser calls to {self.path}")
+
+class RecordingProxy:
+    """Forwards attribute access to a driver or element and records it."""
+
+    def __init__(self, wrapped, target: str, recorder: Recorder):
+        object.__setattr__(self, '_wrapped', wrapped)
+        object.__setattr__(self, '_target', target)
+        object.__setattr__(self, '_recorder', recorder)
+
+    def __getattr__(self, name: str):
+        wrapped, target, recorder = self._wrapped, self._target, self._recorder
+        value = getattr(wrapped, name)
+        if not callable(value):
+            recorder.add(target, name, 'property', None, value)
+            return recorder.wrap(value)
+
+        def call(*args, **kwargs):
+            # Elements passed back into the browser are recorded as references
+            raw_args = [a._wrapped if isinstance(a, RecordingProxy) else a for a in args]
+            encoded = recorder.encode([raw_args, kwargs])
+            try:
+                result = value(*raw_args, **kwargs)
+            except Exception as e:
+                recorder.add(target, name, 'call', encoded, error=e)
+                raise
+            recorder.add(target, name, 'call', encoded, result)
+            if target == DRIVER and name == 'quit':
+                recorder.save()
+            return recorder.wrap(result)
+        return call
+
+    def __setattr__(self, name: str, value):
+        setattr(self._wrapped, name, value)
+
+def record(driver, path: str) -> RecordingProxy:
+    """Wrap a live driver so its session is recorded to `path` when it quits."""
+    return RecordingProxy(driver, DRIVER, Recorder(path))
+
+def _rebuild_error(error: Dict) -> Exception:
+    module_name, _, class_name = error['type'].rpartition('.')
+    try:
+        error_class = getattr(importlib.import_module(module_name), class_name)
+        if isinstance(error_class, type) and issubclass(error_class, Exception):
+            return error_class(error['message'])
+    except Exception:
+        pass
+    return ReplayMissError(f"{error['type']}: {error['message']}")
+
+class Cassette:
+    """Recorded calls indexed for replay."""
+
+    def __init__(self, entries: List[Dict]):
+        self.calls: Dict[str, Deque[Dict]] = defaultdict(deque)
+        self.properties: Dict[str, Deque[Dict]] = defaultdict(deque)
+        for entry in entries:
+            if entry['kind'] == 'property':
+                self.properties[_key(entry['target'], entry['name'], None)].append(entry)
+            else:
+                self.calls[_key(entry['target'], entry['name'], entry['args'])].append(entry)
+        self._lock = threading.Lock()
+
+    @classmethod
+    def load(cls, path: str) -> 'Cassette':
+        with open(path, 'r', encoding='utf-8') as f:
+            return cls(json.load(f)['entries'])
+
+    def next(self, index: Dict[str, Deque[Dict]], key: str) -> Dict:
+        with self._lock:
+            queue = index.get(key)
+            if not queue:
+                raise ReplayMissError(f"No recorded response for {key}")
+            # The last answer keeps being replayed for polling loops that run longer
+            return queue.popleft() if len(queue) > 1 else queue[0]
+
+class ReplayProxy:
+    """Answers driver and element calls from a cassette."""
+
+    def __init__(self, cassette: Cassette, target: str = DRIVER):
+        object.__setattr__(self, '_cassette', cassette)
+        object.__setattr__(self, '_target', target)
+
+    def _decode(self, value: Any) -> Any:
+        if isinstance(value, dict) and '__element__' in value:
+            return ReplayProxy(self._cassette, value['__element__'])
+        if isinstance(value, list):
+            return [self._decode(v) for v in value]
+        return value
+
+    def _answer(self, entry: Dict) -> Any:
+        if 'error' in entry:
+            raise _rebuild_error(entry['error'])
+        return self._decode(entry['result'])
+
+    def __getattr__(self, name: str):
+        cassette, target = self._cassette, self._target
+        property_key = _key(target, name, None)
+        if property_key in cassette.properties:
+            return self._answer(cassette.next(cassette.properties, property_key))
+
+        def call(*args, **kwargs):
+            encoded = [[{'__element__': a._target} if isinstance(a, ReplayProxy) else a for a in args], kwargs]
+            encoded = json.loads(json.dumps(encoded, default=str))
+            return self._answer(cassette.next(cassette.calls, _key(target, name, encoded)))
+        return call
+
+    def __setattr__(self, name: str, value):
+        pass
+
+def replay(path: str) -> ReplayProxy:
+    """Return a driver that replays the session recorded at `path`."""
+    cassette = Cassette.load(path)
+    logger.info(f"Replaying browser session from {path}")
+    return ReplayProxy(cassette)
@@ -0,0 +1,198 @@
+"""
+Central scheduler for place jobs.
+Runs jobs on a bounded number of threads, lowers the number running at
+once while the adaptive throttle is backing off, and spaces out job starts
+per domain according to configured rate limits. When stopped, queued jobs
+are cancelled and running ones are waited for up to a grace period. Jobs
+run on daemon threads, so jobs abandoned after the grace period do not
+keep the process alive the way ThreadPoolExecutor's exit hook would.
+"""
+
+import logging
+import queue
+import re
+import threading
+import time
+from concurrent.futures import CancelledError, Future
+from concurrent.futures import TimeoutError as FutureTimeout
+from contextlib import contextmanager
+from typing import Callable, Dict, Iterator, List, Optional
+from urllib.parse import urlparse
+
+from .throttle import AdaptiveThrottle
+from .tracing import bind
+
+logger = logging.getLogger(__name__)
+
+UNIT_SECONDS = {'s': 1, 'sec': 1, 'm': 60, 'min': 60, 'h': 3600, 'hour': 3600}
+
+def parse_rate_limits(spec: Optional[str]) -> Dict[str, float]:
+    """Parse "host=30/min,host2=2/s" into jobs per second by host."""
+    limits = {}
+    for part in (spec or '').split(','):
+        part = part.strip()
+        if not part:
+            continue
+        match = re.fullmatch(r'([^=\s]+)\s*=\s*(\d+(?:\.\d+)?)\s*/\s*(\w+)', part)
+        if not match or match.group(3) not in UNIT_SECONDS:
+            raise ValueError(f"Invalid rate limit '{part}', expected host=N/s|min|hour")
+        limits[match.group(1).lower()] = float(match.group(2)) / UNIT_SECONDS[match.group(3)]
+    return limits
+
+class DaemonExecutor:
+    """Runs submitted calls on a fixed number of daemon threads."""
+
+    def __init__(self, max_workers: int, thread_name_prefix: str = 'worker'):
+        self._queue: queue.Queue = queue.Queue()
+        self._threads = [threading.Thread(target=self._work, name=f"{thread_name_prefix}_{i}", daemon=True)
+                         for i in range(max_workers)]
+        for thread in self._threads:
+            thread.start()
+
+    def _work(self):
+        while True:
+            item = self._queue.get()
+            if item is None:
+                return
+            future, fn = item
+            if not future.set_running_or_notify_cancel():
+                continue
+            try:
+                future.set_result(fn())
+            except BaseException as e:
+                future.set_exception(e)
+
+    def submit(self, fn: Callable) -> Future:
+        future = Future()
+        self._queue.put((future, fn))
+        return future
+
+    def shutdown(self, wait: bool = True, cancel_futures: bool = False):
+        """Stop the threads once the queue is drained, cancelling what is still queued if asked."""
+        if cancel_futures:
+            while True:
+                try:
+                    item = self._queue.get_nowait()
+                except queue.Empty:
+                    break
+                if item is not None:
+                    item[0].cancel()
+        for _ in self._threads:
+            self._queue.put(None)
+        if wait:
+            for thread in self._threads:
+                thread.join()
+
+class Scheduler:
+    """Bounded, rate-limited executor for place jobs."""
+
+    def __init__(self, concurrency: int = 1, rate_limits: Optional[Dict[str, float]] = None,
+                 throttle: Optional[AdaptiveThrottle] = None):
+        if concurrency < 1:
+            raise ValueError("Concurrency must be at least 1")
+        self.concurrency = concurrency
+        self.rate_limits = rate_limits or {}
+        self.throttle = throttle
+        self.active = 0
+        self.futures: List[Future] = []
+        self._executor = DaemonExecutor(max_workers=concurrency, thread_name_prefix='place')
+        self._next_start: Dict[str, float] = {}
+        self._condition = threading.Condition()
+        self.stopping = False
+        self._deadline: Optional[float] = None
+
+    def __enter__(self):
+        return self
+
+    def __exit__(self, exc_type, exc_value, tb):
+        self.close()
+
+    def close(self):
+        """Wait for every submitted job and shut the threads down."""
+        self.join()
+        # After a stop, jobs still running past the grace period are abandoned to their daemon threads
+        self._executor.shutdown(wait=not self.stopping, cancel_futures=True)
+
+    def stop(self, grace: float):
+        """Cancel queued jobs, refuse new ones and give running jobs `grace` seconds to finish."""
+        self.stopping = True
+        self._deadline = time.time() + grace
+        cancelled = sum(1 for future in list(self.futures) if future.cancel())
+        logger.info(f"Scheduler stopping: cancelled {cancelled} queued jobs, waiting up to {grace}s for running ones")
+
+    def limit(self) -> int:
+        """Number of jobs allowed to run at once right now."""
+        if self.throttle:
+            return self.throttle.allowed_concurrency(self.concurrency)
+        return self.concurrency
+
+    def _wait_for_rate(self, url: str):
+        host = (urlparse(url).hostname or '').lower()
+        per_second = self.rate_limits.get(host)
+        if not per_second:
+            return
+        with self._condition:
+            now = time.time()
+            start = max(now, self._next_start.get(host, 0))
+            self._next_start[host] = start + 1 / per_second
+        if start > now:
+            time.sleep(start - now)
+
+    @contextmanager
+    def slot(self, url: str) -> Iterator[None]:
+        """Hold one of the running job slots for `url`."""
+        with self._condition:
+            while self.active >= self.limit():
+                self._condition.wait(timeout=1)
+            self.active += 1
+        try:
+            self._wait_for_rate(url)
+            yield
+        finally:
+            with self._condition:
+                self.active -= 1
+                self._condition.notify_all()
+
+    def submit(self, handle_url: Callable[[str], None], url: str) -> Optional[Future]:
+        """Schedule `handle_url(url)` to run once a slot is free; returns None once stopping."""
+        if self.stopping:
+            logger.debug(f"Scheduler stopping, not starting {url}")
+            return None
+        def run():
+            with self.slot(url):
+                handle_url(url)
+        # Jobs continue the trace of whoever submitted them
+        future = self._executor.submit(bind(run))
+        with self._condition:
+            self.futures.append(future)
+        future.add_done_callback(self._finished)
+        return future
+
+    def _finished(self, future: Future):
+        """Log a job that raised and forget it, so a long-lived scheduler only holds unfinished jobs."""
+        with self._condition:
+            if future in self.futures:
+                self.futures.remove(future)
+        if not future.cancelled() and future.exception():
+            logger.error(f"Scheduled job failed: {str(future.exception())}")
+
+    def join(self):
+        """Wait for every submitted job until the grace period of a stop ends."""
+        with self._condition:
+            pending = list(self.futures)
+        for future in pending:
+            while True:
+                try:
+                    future.result(timeout=1)
+                except FutureTimeout:
+                    if self._deadline and time.time() > self._deadline:
+                        running = sum(1 for f in pending if not f.done())
+                        logger.warning(f"Grace period over, abandoning {running} running jobs")
+                        return
+                    continue
+                except CancelledError:
+                    pass
+                except Exception:
+                    # Logged when the job finished
+                    pass
+                break
@@ -0,0 +1,164 @@
+"""
+Selector registry.
+The CSS selectors of every scraped field live in selectors.yaml next to
+this module, with ordered fallbacks per field. A deployment can override
+fields from its own YAML file, which is re-read when it changes, so a
+Google UI change can be fixed without a release. The registry counts
+which selector matched for every field, so the success rate of each field
+and how often it needed a fallback can be reported after a run.
+"""
+
+import hashlib
+import json
+import logging
+import os
+import threading
+from typing import Dict, List, Optional
+
+import yaml
+from selenium.webdriver.common.by import By
+
+from .errors import SelectorMissingError
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_SELECTORS_FILE = os.path.join(os.path.dirname(__file__), 'selectors.yaml')
+# Fields every place or search page has, so a low match rate means a broken selector
+REQUIRED_FIELDS = {'feed_card', 'feed_card_link', 'place_name', 'address', 'category', 'review', 'review_rating'}
+
+def _read(path: str) -> Dict[str, List[str]]:
+    with open(path, encoding='utf-8') as f:
+        data = yaml.safe_load(f) or {}
+    selectors = {}
+    for field, value in data.items():
+        values = [value] if isinstance(value, str) else list(value or [])
+        if not values or not all(isinstance(v, str) for v in values):
+            raise ValueError(f"Selectors of '{field}' in {path} must be a string or a list of strings")
+        selectors[field] = values
+    return selectors
+
+class SelectorRegistry:
+    """Fallback chains of CSS selectors by field, with per-field match metrics."""
+
+    def __init__(self, selectors: Dict[str, List[str]], override_file: Optional[str] = None):
+        self.defaults = selectors
+        self.override_file = override_file
+        self.selectors = dict(selectors)
+        self._override_mtime = None
+        self._metrics: Dict[str, Dict] = {}
+        self._lock = threading.Lock()
+        self.reload()
+
+    @classmethod
+    def load(cls, override_file: Optional[str] = None) -> 'SelectorRegistry':
+        """Load the embedded selectors, overridden by the fields of `override_file` if given."""
+        return cls(_read(DEFAULT_SELECTORS_FILE), override_file)
+
+    def reload(self) -> bool:
+        """Re-read the override file if it changed; returns True if the selectors were replaced."""
+        if not self.override_file:
+            return False
+        try:
+            mtime = os.path.getmtime(self.override_file)
+            if mtime == self._override_mtime:
+                return False
+            overrides = _read(self.override_file)
+        except Exception as e:
+            # Keep the last good selectors while the file is broken
+            logger.error(f"Could not load selectors from {self.override_file}: {str(e)}")
+            return False
+        with self._lock:
+            self.selectors = {**self.defaults, **overrides}
+            self._override_mtime = mtime
+        logger.info(f"Loaded {len(overrides)} selector overrides from {self.override_file}")
+        return True
+
+    def chain(self, field: str) -> List[str]:
+        """Return the selectors of a field in the order they are tried."""
+        if field not in self.selectors:
+            raise KeyError(f"No selectors registered for '{field}'")
+        return self.selectors[field]
+
+    def css(self, field: str) -> str:
+        """Return the field's selectors as one selector list, for waits that accept any of them."""
+        return ', '.join(self.chain(field))
+
+    def _record(self, field: str, index: Optional[int]):
+        with self._lock:
+            metrics = self._metrics.setdefault(field, {'found': 0, 'missing': 0, 'fallback': 0, 'by_selector': {}})
+            if index is None:
+                metrics['missing'] += 1
+                return
+            metrics['found'] += 1
+            if index > 0:
+                metrics['fallback'] += 1
+            selector = self.chain(field)[index]
+            metrics['by_selector'][selector] = metrics['by_selector'].get(selector, 0) + 1
+
+    def select_one(self, soup, field: str, record: bool = True):
+        """Return the first element matched by the field's selectors in a BeautifulSoup tree."""
+        for index, selector in enumerate(self.chain(field)):
+            element = soup.select_one(selector)
+            if element is not None:
+                if record:
+                    self._record(field, index)
+                return element
+        if record:
+            self._record(field, None)
+        return None
+
+    def select(self, soup, field: str, record: bool = True) -> list:
+        """Return the elements matched by the first of the field's selectors that matches any."""
+        for index, selector in enumerate(self.chain(field)):
+            elements = soup.select(selector)
+            if elements:
+                if record:
+                    self._record(field, index)
+                return elements
+        if record:
+            self._record(field, None)
+        return []
+
+    def find_element(self, root, field: str, url: Optional[str] = None):
+        """Return the first Selenium element matched by the field's selectors under a driver or element,
+        raising SelectorMissingError with the field and page URL when none matches."""
+        for index, selector in enumerate(self.chain(field)):
+            elements = root.find_elements(By.CSS_SELECTOR, selector)
+            if elements:
+                self._record(field, index)
+                return elements[0]
+        self._record(field, None)
+        raise SelectorMissingError(field, url)
+
+    def find_elements(self, root, field: str) -> list:
+        """Return the Selenium elements matched by the first of the field's selectors that matches any."""
+        for selector in self.chain(field):
+            elements = root.find_elements(By.CSS_SELECTOR, selector)
+            if elements:
+                return elements
+        return []
+
+    def version(self) -> str:
+        """Return a short hash of the selectors in use, overrides included."""
+        with self._lock:
+            data = json.dumps(self.selectors, sort_keys=True)
+        return hashlib.sha256(data.encode('utf-8')).hexdigest()[:12]
+
+    def metrics(self) -> Dict[str, Dict]:
+        """Return match counts by field, with the share of lookups that found the field."""
+        with self._lock:
+            report = json.loads(json.dumps(self._metrics))
+        for field, metrics in report.items():
+            total = metrics['found'] + metrics['missing']
+            metrics['success_rate'] = round(metrics['found'] / total, 3) if total else None
+        return report
+
+    def save_metrics(self, path: str):
+        """Write the match metrics as JSON and log the required fields that were often missing."""
+        report = self.metrics()
+        with open(path, 'w', encoding='utf-8') as f:
+            json.dump(report, f, indent=2)
+        for field, metrics in sorted(report.items()):
+            if field in REQUIRED_FIELDS and metrics['success_rate'] is not None and metrics['success_rate'] < 0.5:
+                logger.warning(f"Selector field '{field}' matched only {metrics['found']} of "
+                               f"{metrics['found'] + metrics['missing']} lookups")
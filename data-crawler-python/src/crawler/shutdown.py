@@ -0,0 +1,63 @@
+"""
+Graceful shutdown on SIGINT and SIGTERM.
+The first signal asks the crawl to stop: no new place jobs are started,
+running ones get a bounded grace period, and buffered output and the
+resume checkpoint are written as the crawl unwinds. A second signal stops
+immediately.
+"""
+
+import logging
+import signal
+import threading
+from typing import Callable, List, Optional
+
+logger = logging.getLogger(__name__)
+
+SIGNALS = (signal.SIGINT, signal.SIGTERM)
+
+class ShutdownSignal:
+    """Stop request set by SIGINT/SIGTERM, with callbacks run when it arrives."""
+
+    def __init__(self, event: Optional[threading.Event] = None):
+        self.event = event or threading.Event()
+        self._callbacks: List[Callable[[], None]] = []
+        self._previous = {}
+
+    def __enter__(self):
+        self.install()
+        return self
+
+    def __exit__(self, exc_type, exc_value, tb):
+        self.restore()
+
+    @property
+    def requested(self) -> bool:
+        return self.event.is_set()
+
+    def on_stop(self, callback: Callable[[], None]):
+        """Run `callback` in the signal handler when a stop is requested."""
+        self._callbacks.append(callback)
+
+    def install(self):
+        """Handle SIGINT and SIGTERM; only possible from the main thread."""
+        for signum in SIGNALS:
+            self._previous[signum] = signal.signal(signum, self._handle)
+
+    def restore(self):
+        """Put back the handlers that were installed before."""
+        for signum, handler in self._previous.items():
+            signal.signal(signum, handler)
+        self._previous = {}
+
+    def _handle(self, signum, frame):
+        if self.requested:
+            logger.warning("Second stop signal, exiting immediately")
+            raise KeyboardInterrupt
+        logger.warning(f"Received {signal.Signals(signum).name}, finishing running jobs before exiting "
+                       f"(send it again to exit immediately)")
+        self.event.set()
+        for callback in self._callbacks:
+            try:
+                callback()
+            except Exception as e:
+                logger.error(f"Shutdown callback failed: {str(e)}")
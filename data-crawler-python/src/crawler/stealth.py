@@ -0,0 +1,89 @@
+"""
+Stealth browser profiles.
+Patches the properties headless Chrome gives away (navigator.webdriver,
+languages, plugins, WebGL vendor) and randomizes the user agent, viewport
+and timezone per browser so crawls look less like automation.
+"""
+
+import json
+import random
+from dataclasses import dataclass
+from typing import List, Tuple
+
+USER_AGENTS = [
+    'Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36',
+    'Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/123.0.0.0 Safari/537.36',
+    'Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36',
+    'Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/122.0.0.0 Safari/537.36',
+    'Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36',
+]
+
+VIEWPORTS = [(1920, 1080), (1680, 1050), (1536, 864), (1440, 900), (1366, 768)]
+
+TIMEZONES = ['America/Los_Angeles', 'America/Denver', 'America/Chicago', 'America/New_York']
+
+WEBGL_VENDORS = [
+    ('Intel Inc.', 'Intel Iris OpenGL Engine'),
+    ('Google Inc. (NVIDIA)', 'ANGLE (NVIDIA, NVIDIA GeForce GTX 1660 Direct3D11 vs_5_0 ps_5_0, D3D11)'),
+    ('Google Inc. (AMD)', 'ANGLE (AMD, AMD Radeon RX 580 Direct3D11 vs_5_0 ps_5_0, D3D11)'),
+]
+
+# Runs before any page script; 37445/37446 are UNMASKED_VENDOR/RENDERER_WEBGL
+PATCH_SCRIPT = """
+Object.defineProperty(navigator, 'webdriver', {get: () => undefined});
+Object.defineProperty(navigator, 'languages', {get: () => %(languages)s});
+Object.defineProperty(navigator, 'plugins', {get: () => [1, 2, 3, 4, 5]});
+window.chrome = window.chrome || {runtime: {}};
+const getParameter = WebGLRenderingContext.prototype.getParameter;
+WebGLRenderingContext.prototype.getParameter = function(parameter) {
+    if (parameter === 37445) return %(vendor)s;
+    if (parameter === 37446) return %(renderer)s;
+    return getParameter.call(this, parameter);
+};
+"""
+
+@dataclass
+class StealthProfile:
+    """Browser fingerprint used for one browser session."""
+    user_agent: str
+    viewport: Tuple[int, int]
+    timezone: str
+    languages: List[str]
+    webgl_vendor: str
+    webgl_renderer: str
+
+    @classmethod
+    def random(cls) -> 'StealthProfile':
+        """Pick a random, internally consistent profile."""
+        vendor, renderer = random.choice(WEBGL_VENDORS)
+        return cls(
+            user_agent=random.choice(USER_AGENTS),
+            viewport=random.choice(VIEWPORTS),
+            timezone=random.choice(TIMEZONES),
+            languages=['en-US', 'en'],
+            webgl_vendor=vendor,
+            webgl_renderer=renderer,
+        )
+
+    def apply_options(self, options):
+        """Add the Chrome flags of this profile to the driver options."""
+        options.add_argument(f'--user-agent={self.user_agent}')
+        options.add_argument(f'--window-size={self.viewport[0]},{self.viewport[1]}')
+        options.add_argument(f'--lang={self.languages[0]}')
+        options.add_argument('--disable-blink-features=AutomationControlled')
+        options.add_experimental_option('excludeSwitches', ['enable-automation'])
+        options.add_experimental_option('useAutomationExtension', False)
+
+    def apply_driver(self, driver):
+        """Install the fingerprint patches and timezone on a running driver."""
+        source = PATCH_SCRIPT % {
+            'languages': json.dumps(self.languages),
+            'vendor': json.dumps(self.webgl_vendor),
+            'renderer': json.dumps(self.webgl_renderer),
+        }
+        driver.execute_cdp_cmd('Page.addScriptToEvaluateOnNewDocument', {'source': source})
+        driver.execute_cdp_cmd('Emulation.setTimezoneOverride', {'timezoneId': self.timezone})
+        driver.execute_cdp_cmd('Network.setUserAgentOverride', {
+            'userAgent': self.user_agent,
+            'acceptLanguage': ','.join(self.languages),
+        })
@@ -0,0 +1,75 @@
+"""
+Producer/consumer bridge between search and detail scraping.
+The search scraper runs in a background thread and queues result cards
+as they are extracted, so detail scraping starts with the first card
+instead of waiting for the whole feed to be scrolled. Setting the `stop`
+event ends the hand-over; cards not handed over yet are dropped. With
+`max_places` the search itself stops once that many places were handed
+over.
+"""
+
+import logging
+import queue
+import threading
+from typing import Callable, Dict, Iterable, Optional
+
+from .tracing import bind, span
+
+logger = logging.getLogger(__name__)
+
+_DONE = object()
+
+def stream_search_to_details(search_scraper, cards_by_key: Dict[str, Dict], search_url: str, max_results: int,
+                             handle_url: Callable[[str], None], stop: Optional[threading.Event] = None,
+                             max_places: Optional[int] = None) -> int:
+    """Search with one scraper while another processes each result; return the number processed."""
+    return stream_cards_to_details(
+        lambda: search_scraper.iter_search_cards(search_url, max_results), cards_by_key, handle_url, stop, max_places
+    )
+
+def stream_cards_to_details(produce_cards: Callable[[], Iterable[Dict]], cards_by_key: Dict[str, Dict],
+                            handle_url: Callable[[str], None], stop: Optional[threading.Event] = None,
+                            max_places: Optional[int] = None) -> int:
+    """Process each card from `produce_cards` as soon as the background search yields it."""
+    cards: queue.Queue = queue.Queue()
+    enough = threading.Event()
+
+    def produce():
+        try:
+            with span('search_job'):
+                for card in produce_cards():
+                    # Leaving the generator stops scrolling the feed
+                    if enough.is_set():
+                        break
+                    cards.put(card)
+        except Exception as e:
+            logger.error(f"Search failed: {str(e)}")
+        finally:
+            cards.put(_DONE)
+
+    producer = threading.Thread(target=bind(produce), name='search-producer', daemon=True)
+    producer.start()
+
+    processed = 0
+    while True:
+        if stop and stop.is_set():
+            logger.info(f"Stopped handing over search results after {processed}")
+            return processed
+        try:
+            card = cards.get(timeout=1)
+        except queue.Empty:
+            continue
+        if card is _DONE:
+            break
+        # Share the card so the detail pass can reconcile against it
+        cards_by_key[card['cid'] or card['url']] = card
+        handle_url(card['url'])
+        processed += 1
+        if max_places and processed >= max_places:
+            logger.info(f"Reached {max_places} places, stopping the search")
+            enough.set()
+            break
+
+    producer.join()
+    logger.info(f"Processed {processed} restaurants from search")
+    return processed
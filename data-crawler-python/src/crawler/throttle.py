@@ -0,0 +1,74 @@
+"""
+Adaptive throttling driven by response signals.
+Tracks page load times, partial renders and block interstitials over a
+sliding window. The pause before each navigation grows while these soft
+signs of throttling show up and shrinks back once pages load cleanly;
+the allowed concurrency follows the same health score.
+"""
+
+import logging
+import threading
+import time
+from collections import deque
+from typing import Deque, Tuple
+
+logger = logging.getLogger(__name__)
+
+WINDOW = 20
+SLOW_LOAD_SECONDS = 8.0
+MIN_DELAY = 1.0
+MAX_DELAY = 60.0
+BACKOFF_FACTOR = 2.0
+RECOVERY_FACTOR = 0.8
+UNHEALTHY_RATIO = 0.2
+
+class AdaptiveThrottle:
+    """Shared pacing for all browsers of a crawl."""
+
+    def __init__(self, min_delay: float = MIN_DELAY, max_delay: float = MAX_DELAY,
+                 slow_load: float = SLOW_LOAD_SECONDS, window: int = WINDOW):
+        self.min_delay = min_delay
+        self.max_delay = max_delay
+        self.slow_load = slow_load
+        self.delay = min_delay
+        # (load seconds, partial render, blocked) per navigation
+        self.samples: Deque[Tuple[float, bool, bool]] = deque(maxlen=window)
+        self._last_navigation = 0.0
+        self._lock = threading.Lock()
+
+    def wait(self):
+        """Sleep until the current delay has passed since the previous navigation."""
+        with self._lock:
+            now = time.time()
+            start = max(now, self._last_navigation + self.delay)
+            self._last_navigation = start
+        if start > now:
+            time.sleep(start - now)
+
+    def unhealthy_ratio(self) -> float:
+        """Share of recent navigations showing a throttling signal."""
+        if not self.samples:
+            return 0.0
+        bad = sum(1 for seconds, partial, blocked in self.samples
+                  if blocked or partial or seconds >= self.slow_load)
+        return bad / len(self.samples)
+
+    def record(self, seconds: float, partial: bool = False, blocked: bool = False):
+        """Record the outcome of a navigation and adjust the delay."""
+        with self._lock:
+            self.samples.append((seconds, partial, blocked))
+            previous = self.delay
+            unhealthy = blocked or partial or seconds >= self.slow_load
+            if blocked or (unhealthy and self.unhealthy_ratio() > UNHEALTHY_RATIO):
+                self.delay = min(self.delay * BACKOFF_FACTOR, self.max_delay)
+            elif not unhealthy:
+                self.delay = max(self.delay * RECOVERY_FACTOR, self.min_delay)
+            if abs(self.delay - previous) >= 1:
+                logger.info(f"Throttle delay {previous:.1f}s -> {self.delay:.1f}s "
+                            f"({self.unhealthy_ratio():.0%} of recent loads unhealthy)")
+
+    def allowed_concurrency(self, maximum: int) -> int:
+        """Scale the number of parallel browsers down as the delay grows."""
+        if self.delay <= self.min_delay:
+            return maximum
+        return max(1, int(maximum * self.min_delay / self.delay + 0.5))
@@ -0,0 +1,93 @@
+"""
+OpenTelemetry tracing of crawl jobs.
+With CRAWLER_TRACING on, search and place jobs are recorded as spans with
+child spans for navigation, scrolling, extraction and sink writes, and
+exported over OTLP to OTEL_EXPORTER_OTLP_ENDPOINT. The trace context
+follows jobs onto scheduler threads and through the Redis job queue.
+Without the OpenTelemetry packages, or with tracing off, every helper is
+a no-op.
+"""
+
+import logging
+from contextlib import contextmanager
+from typing import Callable, Dict, Iterator, Optional
+
+logger = logging.getLogger(__name__)
+
+try:
+    from opentelemetry import context, propagate, trace
+    from opentelemetry.exporter.otlp.proto.http.trace_exporter import OTLPSpanExporter
+    from opentelemetry.sdk.resources import Resource
+    from opentelemetry.sdk.trace import TracerProvider
+    from opentelemetry.sdk.trace.export import BatchSpanProcessor
+except ImportError:
+    trace = None
+
+_provider = None
+_tracer = None
+
+def setup_tracing(service_name: str = 'smart-dine-crawler') -> bool:
+    """Export spans over OTLP; returns False when OpenTelemetry is not installed."""
+    global _provider, _tracer
+    if trace is None:
+        logger.warning("Tracing is on but the opentelemetry packages are not installed")
+        return False
+    if _tracer is not None:
+        return True
+    _provider = TracerProvider(resource=Resource.create({'service.name': service_name}))
+    _provider.add_span_processor(BatchSpanProcessor(OTLPSpanExporter()))
+    trace.set_tracer_provider(_provider)
+    _tracer = trace.get_tracer('smart_dine.crawler')
+    logger.info(f"Exporting traces over OTLP as '{service_name}'")
+    return True
+
+def shutdown_tracing():
+    """Flush the spans not exported yet."""
+    global _provider, _tracer
+    if _provider is not None:
+        _provider.shutdown()
+    _provider, _tracer = None, None
+
+@contextmanager
+def span(name: str, **attributes) -> Iterator[Optional[object]]:
+    """Record the enclosed block as a span; attributes that are None are left out."""
+    if _tracer is None:
+        yield None
+        return
+    # Attribute keys use dots, e.g. place_url becomes place.url
+    attributes = {key.replace('_', '.'): value for key, value in attributes.items() if value is not None}
+    with _tracer.start_as_current_span(name, attributes=attributes) as current:
+        yield current
+
+def bind(fn: Callable) -> Callable:
+    """Return `fn` running in the current trace context, for handing work to another thread."""
+    if _tracer is None:
+        return fn
+    ctx = context.get_current()
+
+    def run(*args, **kwargs):
+        token = context.attach(ctx)
+        try:
+            return fn(*args, **kwargs)
+        finally:
+            context.detach(token)
+    return run
+
+def inject() -> Dict[str, str]:
+    """Return the current trace context as headers, e.g. {"traceparent": ...}, to store with a job."""
+    carrier: Dict[str, str] = {}
+    if _tracer is not None:
+        propagate.inject(carrier)
+    return carrier
+
+@contextmanager
+def extracted(carrier: Optional[Dict[str, str]]) -> Iterator[None]:
+    """Continue the trace whose context was stored with a job by `inject`."""
+    if _tracer is None or not carrier:
+        yield
+        return
+    token = context.attach(propagate.extract(carrier))
+    try:
+        yield
+    finally:
+        context.detach(token)
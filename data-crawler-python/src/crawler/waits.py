@@ -0,0 +1,89 @@
+"""
+Condition-based waits for the browser.
+Instead of sleeping a fixed time after clicks and scrolls, the scraper
+waits for what it actually needs: an element to appear, a count of
+elements to stop changing, or the page's network traffic to go idle, so
+fast networks are not penalized and slow ones do not flake. Every
+timeout is multiplied by the run's slow-mo factor (CRAWLER_SLOW_MO) to
+give slow proxies more time without touching the code.
+"""
+
+import logging
+import time
+
+from selenium.webdriver.common.by import By
+
+logger = logging.getLogger(__name__)
+
+POLL_INTERVAL = 0.25
+# Resource Timing entries are counted in the page, which leaves the CDP
+# performance log to the byte counting of the run costs
+RESOURCE_COUNT_JS = "return [document.readyState, performance.getEntriesByType('resource').length];"
+
+class Waits:
+    """Waits on a driver's page with timeouts scaled by a slow-mo factor."""
+
+    def __init__(self, slow_mo: float = 1.0):
+        self.slow_mo = slow_mo
+
+    def scaled(self, seconds: float) -> float:
+        return seconds * self.slow_mo
+
+    def pause(self, seconds: float):
+        """Sleep for a short pause that has no condition to wait on, e.g. between scroll steps."""
+        time.sleep(self.scaled(seconds))
+
+    def for_present(self, driver, css: str, timeout: float) -> bool:
+        """Wait until an element matches `css`; returns False on timeout."""
+        deadline = time.time() + self.scaled(timeout)
+        while not driver.find_elements(By.CSS_SELECTOR, css):
+            if time.time() >= deadline:
+                return False
+            time.sleep(POLL_INTERVAL)
+        return True
+
+    def for_count_change(self, driver, css: str, before: int, timeout: float) -> int:
+        """Wait until the number of elements matching `css` differs from `before` and return it."""
+        deadline = time.time() + self.scaled(timeout)
+        count = len(driver.find_elements(By.CSS_SELECTOR, css))
+        while count == before and time.time() < deadline:
+            time.sleep(POLL_INTERVAL)
+            count = len(driver.find_elements(By.CSS_SELECTOR, css))
+        return count
+
+    def for_stable_count(self, driver, css: str, window: float, timeout: float) -> int:
+        """Wait until the number of elements matching `css` has not changed for `window` seconds."""
+        start_time = time.time()
+        timeout, window = self.scaled(timeout), self.scaled(window)
+        last_count = len(driver.find_elements(By.CSS_SELECTOR, css))
+        stable_since = time.time()
+
+        while time.time() - stable_since < window:
+            if time.time() - start_time > timeout:
+                logger.warning(f"Element count for '{css}' not stable after {timeout:.0f}s")
+                break
+            time.sleep(POLL_INTERVAL)
+            count = len(driver.find_elements(By.CSS_SELECTOR, css))
+            if count != last_count:
+                last_count = count
+                stable_since = time.time()
+
+        return last_count
+
+    def for_network_idle(self, driver, idle: float = 0.5, timeout: float = 10) -> bool:
+        """Wait until the page has loaded and no request has finished for `idle` seconds."""
+        deadline = time.time() + self.scaled(timeout)
+        last_seen, quiet_since = None, time.time()
+        while time.time() < deadline:
+            try:
+                state, requests = driver.execute_script(RESOURCE_COUNT_JS)
+            except Exception:
+                # Pages that cannot run scripts, e.g. replayed fixtures, have nothing to wait for
+                return True
+            if state != 'complete' or requests != last_seen:
+                last_seen, quiet_since = requests, time.time()
+            elif time.time() - quiet_since >= idle:
+                return True
+            time.sleep(POLL_INTERVAL)
+        logger.debug(f"Network not idle after {self.scaled(timeout):.0f}s")
+        return False
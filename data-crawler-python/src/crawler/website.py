@@ -0,0 +1,175 @@
+"""
+Secondary crawl of restaurants' own websites.
+Fetches the home page of the website found on Maps, follows the links that
+look like menu, reservation and contact pages, and extracts emails, social
+profiles and the menu link or PDF. Requests are plain HTTP (no browser),
+respect robots.txt and are spaced out per host by their own rate limit.
+"""
+
+import logging
+import re
+import threading
+import time
+from typing import Dict, List, Optional
+from urllib import robotparser
+from urllib.parse import urljoin, urlparse
+
+import requests
+from bs4 import BeautifulSoup
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_USER_AGENT = 'Mozilla/5.0 (compatible; SmartDineBot/1.0)'
+EMAIL = re.compile(r'[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}')
+# Link text or path keywords of the pages worth following, by page kind
+PAGE_KEYWORDS = {
+    'menu': ('menu', 'carte', 'speisekarte', 'food', 'drinks'),
+    'reservation': ('reserv', 'booking', 'book a table', 'opentable', 'resy', 'tock'),
+    'contact': ('contact', 'about', 'find us', 'location'),
+}
+SOCIAL_HOSTS = {
+    'facebook.com': 'facebook',
+    'instagram.com': 'instagram',
+    'twitter.com': 'twitter',
+    'x.com': 'twitter',
+    'tiktok.com': 'tiktok',
+    'youtube.com': 'youtube',
+    'yelp.com': 'yelp',
+    'tripadvisor.com': 'tripadvisor',
+}
+# Third-party booking sites linked as the reservation page
+RESERVATION_HOSTS = ('opentable.com', 'resy.com', 'exploretock.com', 'sevenrooms.com', 'yelp.com/reservations')
+
+def _host(url: str) -> str:
+    host = (urlparse(url).hostname or '').lower()
+    return host[4:] if host.startswith('www.') else host
+
+def social_platform(url: str) -> Optional[str]:
+    """Return the social platform a link points to, e.g. "instagram"."""
+    host = _host(url)
+    for domain, platform in SOCIAL_HOSTS.items():
+        if host == domain or host.endswith('.' + domain):
+            return platform
+    return None
+
+def page_kind(text: str, url: str) -> Optional[str]:
+    """Classify a link as a menu, reservation or contact page from its text and path."""
+    if any(host in url.lower() for host in RESERVATION_HOSTS):
+        return 'reservation'
+    haystack = f"{text} {urlparse(url).path}".lower()
+    for kind, keywords in PAGE_KEYWORDS.items():
+        if any(keyword in haystack for keyword in keywords):
+            return kind
+    return None
+
+class WebsiteCrawler:
+    """Crawl a restaurant website for contact details, social links and its menu."""
+
+    def __init__(self, rate_per_host: float = 0.5, max_pages: int = 4, timeout: float = 15,
+                 user_agent: str = DEFAULT_USER_AGENT):
+        self.rate_per_host = rate_per_host
+        self.max_pages = max_pages
+        self.timeout = timeout
+        self.user_agent = user_agent
+        self._next_request: Dict[str, float] = {}
+        self._robots: Dict[str, robotparser.RobotFileParser] = {}
+        self._lock = threading.Lock()
+
+    def _wait_for_rate(self, url: str):
+        host = _host(url)
+        with self._lock:
+            now = time.time()
+            start = max(now, self._next_request.get(host, 0))
+            self._next_request[host] = start + 1 / self.rate_per_host
+        if start > now:
+            time.sleep(start - now)
+
+    def _allowed(self, url: str) -> bool:
+        parsed = urlparse(url)
+        root = f"{parsed.scheme}://{parsed.netloc}"
+        with self._lock:
+            robots = self._robots.get(root)
+        if robots is None:
+            robots = robotparser.RobotFileParser()
+            try:
+                self._wait_for_rate(url)
+                response = requests.get(f"{root}/robots.txt", timeout=self.timeout,
+                                        headers={'User-Agent': self.user_agent})
+                robots.parse(response.text.splitlines() if response.ok else [])
+            except Exception as e:
+                logger.debug(f"Could not read robots.txt of {root}: {str(e)}")
+                robots.parse([])
+            with self._lock:
+                self._robots[root] = robots
+        return robots.can_fetch(self.user_agent, url)
+
+    def _get(self, url: str) -> Optional[requests.Response]:
+        if not self._allowed(url):
+            logger.info(f"Skipping {url}, disallowed by robots.txt")
+            return None
+        self._wait_for_rate(url)
+        try:
+            response = requests.get(url, timeout=self.timeout, headers={'User-Agent': self.user_agent})
+            response.raise_for_status()
+            return response
+        except Exception as e:
+            logger.warning(f"Could not fetch {url}: {str(e)}")
+            return None
+
+    def crawl(self, website: str) -> Optional[Dict]:
+        """Crawl `website` and return {emails, social_links, menu_url, menu_pdf, reservation_url,
+        contact_url, pages}, or None if the home page could not be fetched."""
+        home = self._get(website)
+        if home is None:
+            return None
+        info = {'emails': [], 'social_links': {}, 'menu_url': None, 'menu_pdf': None,
+                'reservation_url': None, 'contact_url': None, 'pages': [home.url]}
+        follow = self._extract(home, info)
+
+        for url in follow[:self.max_pages - 1]:
+            if url.lower().split('?')[0].endswith('.pdf'):
+                continue
+            page = self._get(url)
+            if page is not None:
+                info['pages'].append(page.url)
+                self._extract(page, info)
+        logger.info(f"Crawled {len(info['pages'])} pages of {website}: {len(info['emails'])} emails, "
+                    f"{len(info['social_links'])} social links, menu {info['menu_pdf'] or info['menu_url']}")
+        return info
+
+    def _extract(self, response: requests.Response, info: Dict) -> List[str]:
+        """Record the details found on a page and return the same-site pages worth following."""
+        if 'html' not in response.headers.get('Content-Type', 'text/html'):
+            return []
+        soup = BeautifulSoup(response.text, 'html.parser')
+        site = _host(response.url)
+        follow = []
+
+        for email in EMAIL.findall(soup.get_text(' ')):
+            if email.lower() not in info['emails'] and not email.lower().endswith(('.png', '.jpg', '.webp')):
+                info['emails'].append(email.lower())
+
+        for link in soup.find_all('a', href=True):
+            href = link['href'].strip()
+            if href.lower().startswith('mailto:'):
+                email = href[7:].split('?')[0].strip().lower()
+                if email and email not in info['emails']:
+                    info['emails'].append(email)
+                continue
+            url = urljoin(response.url, href).split('#')[0]
+            if not url.startswith('http'):
+                continue
+
+            platform = social_platform(url)
+            if platform:
+                info['social_links'].setdefault(platform, url)
+                continue
+
+            kind = page_kind(link.get_text(' ', strip=True), url)
+            if kind == 'menu' and url.lower().split('?')[0].endswith('.pdf'):
+                info['menu_pdf'] = info['menu_pdf'] or url
+            if kind and not info[f"{kind}_url"]:
+                info[f"{kind}_url"] = url
+                if _host(url) == site and url not in info['pages'] and url not in follow:
+                    follow.append(url)
+        return follow
@@ -0,0 +1,143 @@
+"""
+Weekly rate-of-change feed for the analytics dashboard.
+Aggregates the place snapshot history per suburb and ISO week into new
+places, closed places and the average rating change, and writes it as
+CSV and JSON.
+
+A place counts as new in the first week it was seen. It counts as
+closed in the week after the last week it was seen when its suburb was
+crawled again that week without it. A snapshot is kept per distinct
+state of a place and lists every week that state was seen in.
+
+Usage:
+    python -m src.dashboard [--since 2024-01-01] [--output data/dashboard]
+"""
+
+import argparse
+import csv
+import json
+import logging
+import os
+import sys
+from collections import defaultdict
+from datetime import datetime, timedelta, timezone
+from typing import Dict, Iterable, List, Optional
+
+from src.config.settings import settings
+from src.database.mongodb import MongoDBClient
+from src.enrichment.localities import LocalityIndex
+from src.main import configure_logging
+
+logger = logging.getLogger(__name__)
+
+COLUMNS = ['week', 'suburb', 'places_seen', 'new_places', 'closed_places', 'avg_rating_change']
+UNKNOWN_SUBURB = 'unknown'
+
+def week_of(moment: datetime) -> str:
+    """Return the ISO week of a timestamp, e.g. 2024-W07."""
+    year, week, _ = moment.isocalendar()
+    return f"{year}-W{week:02d}"
+
+def next_week(week: str) -> str:
+    year, number = week.split('-W')
+    monday = datetime.strptime(f"{year}-W{number}-1", "%G-W%V-%u")
+    return week_of(monday + timedelta(days=7))
+
+def suburb_of(snapshot: Dict, localities: Optional[LocalityIndex]) -> str:
+    """Return the suburb of a snapshot, reverse-geocoding it when it was saved without one."""
+    if snapshot.get('locality'):
+        return snapshot['locality']
+    coordinates = snapshot.get('coordinates') or []
+    if localities and len(coordinates) == 2:
+        found = localities.lookup(coordinates[1], coordinates[0])
+        if found['locality']:
+            return found['locality']
+    return snapshot.get('city') or UNKNOWN_SUBURB
+
+def aggregate_weekly(snapshots: Iterable[Dict], localities: Optional[LocalityIndex] = None) -> List[Dict]:
+    """Aggregate snapshots into one row per week and suburb."""
+    # Last rating and suburb of every place in every week it was seen
+    seen: Dict[str, Dict[str, Dict]] = defaultdict(dict)
+    for snapshot in sorted(snapshots, key=lambda s: s['captured_at']):
+        place = snapshot.get('restaurant_id') or snapshot.get('url')
+        entry = {
+            'suburb': suburb_of(snapshot, localities),
+            'rating': snapshot.get('overall_rating'),
+        }
+        for week in snapshot.get('seen_weeks') or [week_of(snapshot['captured_at'])]:
+            seen[place][week] = entry
+
+    rows: Dict[tuple, Dict] = {}
+    crawled = set()
+
+    def row(week: str, suburb: str) -> Dict:
+        if (week, suburb) not in rows:
+            rows[(week, suburb)] = {'week': week, 'suburb': suburb, 'places_seen': 0, 'new_places': 0,
+                                    'closed_places': 0, 'rating_changes': []}
+        return rows[(week, suburb)]
+
+    for weeks in seen.values():
+        previous_rating = None
+        for index, week in enumerate(sorted(weeks)):
+            entry = weeks[week]
+            current = row(week, entry['suburb'])
+            crawled.add((week, entry['suburb']))
+            current['places_seen'] += 1
+            if index == 0:
+                current['new_places'] += 1
+            if previous_rating is not None and entry['rating'] is not None:
+                current['rating_changes'].append(entry['rating'] - previous_rating)
+            if entry['rating'] is not None:
+                previous_rating = entry['rating']
+
+    # A place disappeared when its suburb was crawled the week after its last snapshot
+    for weeks in seen.values():
+        last_week = max(weeks)
+        following = next_week(last_week)
+        suburb = weeks[last_week]['suburb']
+        if (following, suburb) in crawled:
+            rows[(following, suburb)]['closed_places'] += 1
+
+    result = []
+    for key in sorted(rows):
+        current = rows[key]
+        changes = current.pop('rating_changes')
+        current['avg_rating_change'] = round(sum(changes) / len(changes), 3) if changes else None
+        result.append(current)
+    return result
+
+def write_feed(rows: List[Dict], output: str):
+    """Write the feed as <output>.csv and <output>.json."""
+    os.makedirs(os.path.dirname(output) or '.', exist_ok=True)
+    with open(f"{output}.csv", 'w', newline='', encoding='utf-8') as f:
+        writer = csv.DictWriter(f, fieldnames=COLUMNS)
+        writer.writeheader()
+        writer.writerows(rows)
+    with open(f"{output}.json", 'w', encoding='utf-8') as f:
+        json.dump({'generated_at': datetime.now(timezone.utc).isoformat(), 'rows': rows}, f, ensure_ascii=False, indent=2)
+
+def main():
+    configure_logging()
+    parser = argparse.ArgumentParser(description='Export weekly per-suburb changes for the analytics dashboard.')
+    parser.add_argument('--since', help='Only use snapshots from this date (YYYY-MM-DD)')
+    parser.add_argument('--output', default=os.path.join(settings.output_dir, 'dashboard_weekly'),
+                        help='Output path without extension')
+    args = parser.parse_args()
+
+    try:
+        mongodb = MongoDBClient(
+            mongodb_url=settings.MONGODB_URL,
+            db_name=settings.MONGODB_DB,
+            collection_restaurants=settings.MONGODB_COLLECTION_RESTAURANTS,
+            collection_reviews=settings.MONGODB_COLLECTION_REVIEWS
+        )
+        query = {'last_seen_at': {'$gte': datetime.strptime(args.since, '%Y-%m-%d')}} if args.since else {}
+        rows = aggregate_weekly(mongodb.snapshots.find(query), LocalityIndex.from_file(settings.boundaries_file))
+        write_feed(rows, args.output)
+        logger.info(f"Wrote {len(rows)} week/suburb rows to {args.output}.csv and {args.output}.json")
+    except Exception as e:
+        logger.error(f"Dashboard export failed: {str(e)}")
+        sys.exit(1)
+
+if __name__ == "__main__":
+    main()
@@ -3,7 +3,8 @@ MongoDB database operations.
 Handles all database interactions for storing and retrieving restaurant data.
 """
 
-from typing import List, Optional
+from datetime import datetime, timezone
+from typing import List, Optional, Set
 import ssl
 import logging
 from urllib.parse import quote_plus
@@ -14,6 +15,7 @@ from pymongo.collection import Collection
 from pymongo.errors import ConnectionFailure, ServerSelectionTimeoutError
 
 from ..models.restaurant import Restaurant, Review
+from ..storage.idempotency import content_hash, idempotency_key
 from ..config.settings import settings
 
 logger = logging.getLogger(__name__)
@@ -35,6 +37,8 @@ class MongoDBClient:
             self.db = self.client[db_name]
             self.restaurants = self.db[collection_restaurants]
             self.reviews = self.db[collection_reviews]
+            self.popular_times = self.db[f"{collection_restaurants}_popular_times"]
+            self.snapshots = self.db[f"{collection_restaurants}_snapshots"]
             logger.info("MongoDB client initialized successfully")
         except Exception as e:
             logger.error(f"Failed to initialize MongoDB client: {str(e)}")
@@ -58,6 +62,7 @@ class MongoDBClient:
             logger.info("Creating new indexes")
             self.restaurants.create_index([("name", ASCENDING)])
             self.restaurants.create_index([("url", ASCENDING)])
+            self.restaurants.create_index([("cid", ASCENDING)])
             self.restaurants.create_index([("overall_rating", DESCENDING)])
             self.restaurants.create_index([("attributes.cuisine_type", ASCENDING)])
             self.restaurants.create_index([("attributes.price_level", ASCENDING)])
@@ -69,6 +74,12 @@ class MongoDBClient:
             self.reviews.create_index([("restaurant_id", ASCENDING)])
             self.reviews.create_index([("rating", DESCENDING)])
             self.reviews.create_index([("date", DESCENDING)])
+            self.popular_times.create_index([("url", ASCENDING), ("captured_at", ASCENDING)])
+            # Snapshots saved before they were deduplicated have no content hash
+            self.snapshots.create_index([("restaurant_id", ASCENDING), ("content_hash", ASCENDING)], unique=True,
+                                        partialFilterExpression={"content_hash": {"$exists": True}})
+            self.snapshots.create_index([("captured_at", ASCENDING)])
+            self.snapshots.create_index([("last_seen_at", ASCENDING)])
             
             logger.info("All indexes created successfully")
             
@@ -92,6 +103,10 @@ class MongoDBClient:
             
             # Create a copy of the data for update
             update_data = restaurant_data.copy()
+            # Upserts are keyed by _id, so a retry rewrites identical content;
+            # the key lets downstream consumers detect replayed documents
+            update_data['idempotency_key'] = idempotency_key(restaurant_data)
+            update_data['updated_at'] = datetime.now(timezone.utc)
             logger.debug(f"Created copy of update data: {update_data}")
             
             # Get the _id and url for query
@@ -150,6 +165,37 @@ class MongoDBClient:
                 upsert=True
             )
             logger.info(f"Restaurant upsert result - upserted_id: {result.upserted_id}, modified_count: {result.modified_count}, matched_count: {result.matched_count}")
+
+            # Keep one record per distinct state of the place for rate-of-change reporting;
+            # saving an unchanged place only marks the week it was seen in again
+            location = restaurant_data.get('location') or {}
+            snapshot = {
+                "url": restaurant_url,
+                "overall_rating": restaurant_data.get('overall_rating'),
+                "total_reviews": restaurant_data.get('total_reviews'),
+                "locality": location.get('locality'),
+                "city": location.get('city'),
+                "coordinates": location.get('coordinates'),
+            }
+            seen_at = update_data['updated_at']
+            year, week, _ = seen_at.isocalendar()
+            self.snapshots.update_one(
+                {"restaurant_id": query["_id"], "content_hash": content_hash(snapshot)},
+                {"$setOnInsert": {**snapshot, "captured_at": seen_at},
+                 "$set": {"last_seen_at": seen_at},
+                 "$addToSet": {"seen_weeks": f"{year}-W{week:02d}"}},
+                upsert=True
+            )
+
+            # Keep every popular times extraction for the history based summaries
+            popular_times = restaurant_data.get('popular_times')
+            if popular_times and restaurant_url:
+                self.popular_times.update_one(
+                    {"url": restaurant_url, "captured_at": popular_times['captured_at']},
+                    {"$set": {"restaurant_id": query["_id"], "histogram": popular_times['histogram'],
+                              "live": popular_times.get('live')}},
+                    upsert=True
+                )
             return result
         except Exception as e:
             logger.error(f"Failed to upsert restaurant: {str(e)}", exc_info=True)
@@ -183,6 +229,7 @@ class MongoDBClient:
                 
                 # Set id_review to _id to satisfy the unique constraint
                 review_data['id_review'] = review_data['_id']
+                review_data['idempotency_key'] = idempotency_key(review_data)
                 
                 operations.append(
                     UpdateOne(
@@ -202,6 +249,18 @@ class MongoDBClient:
             logger.error(f"Failed to upsert reviews: {str(e)}")
             raise
     
+    def popular_times_history(self, url: str) -> List[dict]:
+        """Return the stored popular times snapshots of the restaurant at `url`, oldest first."""
+        return list(self.popular_times.find({"url": url}, {"_id": 0}).sort("captured_at", ASCENDING))
+
+    def known_review_ids(self, url: str) -> Set[str]:
+        """Return the Google review IDs stored for the restaurant at `url`."""
+        restaurant = self.restaurants.find_one({"url": url}, {"_id": 1})
+        if not restaurant:
+            return set()
+        cursor = self.reviews.find({"restaurant_id": restaurant["_id"], "review_id": {"$ne": None}}, {"review_id": 1})
+        return {review["review_id"] for review in cursor}
+
     def get_restaurant(self, restaurant_id: str) -> Optional[Restaurant]:
         """Get a restaurant by ID."""
         try:
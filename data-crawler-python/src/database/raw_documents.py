@@ -0,0 +1,59 @@
+"""
+MongoDB storage for raw place documents.
+Each place is stored as scraped, keyed by its CID, with replace-on-conflict
+semantics. An optional TTL expires documents from ephemeral crawls.
+"""
+
+import logging
+from datetime import datetime, timezone
+from typing import List, Optional
+
+from pymongo import ASCENDING, MongoClient
+
+logger = logging.getLogger(__name__)
+
+class RawDocumentStorage:
+    """Store raw place documents in a MongoDB collection keyed by CID."""
+
+    def __init__(self, mongodb_url: str, db_name: str, collection: str, ttl_seconds: Optional[int] = None):
+        """Connect and, when ttl_seconds is set, create the TTL index."""
+        self.client = MongoClient(mongodb_url)
+        self.collection = self.client[db_name][collection]
+        if ttl_seconds:
+            self.collection.create_index([("crawled_at", ASCENDING)], expireAfterSeconds=ttl_seconds)
+            logger.info(f"Raw documents in '{collection}' expire after {ttl_seconds}s")
+
+    def upsert_restaurant(self, restaurant_data: dict) -> str:
+        """Replace the raw document for a place, inserting it if new."""
+        try:
+            document = restaurant_data.copy()
+            document_id = document.get('cid') or document.get('_id')
+            if not document_id:
+                raise ValueError("Restaurant data must include a CID or _id")
+            if document.get('_id') and document['_id'] != document_id:
+                document['restaurant_id'] = document['_id']
+            document['_id'] = document_id
+            document['crawled_at'] = datetime.now(timezone.utc)
+
+            self.collection.replace_one({"_id": document_id}, document, upsert=True)
+            logger.info(f"Stored raw document {document_id}")
+            return document_id
+        except Exception as e:
+            logger.error(f"Failed to store raw document: {str(e)}")
+            raise
+
+    def upsert_reviews(self, restaurant_id: str, reviews: List[dict]) -> int:
+        """Embed reviews in the raw document of their place."""
+        try:
+            result = self.collection.update_one(
+                {"$or": [{"_id": restaurant_id}, {"restaurant_id": restaurant_id}]},
+                {"$set": {"reviews": reviews}}
+            )
+            return result.modified_count
+        except Exception as e:
+            logger.error(f"Failed to store raw reviews: {str(e)}")
+            raise
+
+    def close(self):
+        """Close the MongoDB connection."""
+        self.client.close()
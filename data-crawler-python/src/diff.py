@@ -0,0 +1,46 @@
+"""
+Diff of two crawls.
+Reports the places that are new, the ones that disappeared or closed,
+and the field changes of the others (rating and review count deltas, opening
+hours, contact details), and writes them as a machine-readable changes
+file. Either crawl can be a JSON array, a JSON lines or GeoJSON export,
+or a directory of place files written by the file sink.
+
+Usage:
+    python -m src.diff old.json new.json [--output changes.json]
+"""
+
+import argparse
+import json
+import logging
+import sys
+
+from src.main import configure_logging
+from src.storage.changes import diff_places, load_places
+
+logger = logging.getLogger(__name__)
+
+def main():
+    configure_logging()
+    parser = argparse.ArgumentParser(description='Report the changes between two crawls.')
+    parser.add_argument('old', help='Places of the earlier crawl')
+    parser.add_argument('new', help='Places of the later crawl')
+    parser.add_argument('--output', default='changes.json', help='Where to write the changes')
+    args = parser.parse_args()
+
+    try:
+        changes = diff_places(load_places(args.old), load_places(args.new))
+        with open(args.output, 'w', encoding='utf-8') as f:
+            json.dump(changes, f, indent=2, ensure_ascii=False, default=str)
+        counts = changes['counts']
+        print(f"{counts['added']} new, {counts['removed']} gone, {counts['closed']} closed, {counts['changed']} changed "
+              f"({counts['old']} -> {counts['new']} places)")
+        for place in changes['changed']:
+            print(f"  {place['name']}: {', '.join(sorted(place['changes']))}")
+        logger.info(f"Wrote changes to {args.output}")
+    except Exception as e:
+        logger.error(f"Diff failed: {str(e)}")
+        sys.exit(1)
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,84 @@
+"""
+Discovery of newly opened restaurants.
+Searches an area with "new" and "coming soon" phrasings, keeps listings
+with few reviews, and flags those whose oldest review is recent as
+newly_opened, feeding smart-dine's "new in your area" feature.
+
+Usage:
+    python -m src.discover_new --area "San Francisco, CA"
+"""
+
+import argparse
+import logging
+import sys
+from typing import Dict, List
+from urllib.parse import quote_plus
+
+from src.config.settings import settings
+from src.crawler.google_maps_crawler import GoogleMapsScraper
+from src.enrichment.dates import age_in_days
+from src.main import build_storage, configure_logging
+from src.models.job_context import JobContext
+from src.storage.ids import build_id_strategy
+
+logger = logging.getLogger(__name__)
+
+SEARCH_PHRASES = ['new restaurants', 'restaurants coming soon', 'restaurant grand opening']
+MAX_REVIEWS = 25
+MAX_FIRST_REVIEW_AGE_DAYS = 180
+
+def search_url(phrase: str, area: str) -> str:
+    """Build a Maps search URL for a phrase in an area."""
+    return f"https://www.google.com/maps/search/{quote_plus(f'{phrase} in {area}')}"
+
+def find_candidates(scraper: GoogleMapsScraper, area: str, max_results: int) -> List[Dict]:
+    """Return search cards with few enough reviews to be new."""
+    candidates = {}
+    for phrase in SEARCH_PHRASES:
+        for card in scraper.iter_search_cards(search_url(phrase, area), max_results):
+            if (card.get('total_reviews') or 0) <= MAX_REVIEWS:
+                candidates[card['cid'] or card['url']] = card
+    logger.info(f"Found {len(candidates)} listings with at most {MAX_REVIEWS} reviews")
+    return list(candidates.values())
+
+def classify_new(restaurant: Dict, reviews: List[Dict]) -> bool:
+    """Flag a restaurant as newly opened from the age of its oldest review."""
+    ages = [a for a in (age_in_days(r.get('date')) for r in reviews) if a is not None]
+    restaurant['first_review_age_days'] = round(max(ages)) if ages else None
+    # A listing without any review yet is as new as it gets
+    restaurant['newly_opened'] = not ages or max(ages) <= MAX_FIRST_REVIEW_AGE_DAYS
+    return restaurant['newly_opened']
+
+def main():
+    configure_logging()
+    parser = argparse.ArgumentParser(description='Discover newly opened restaurants in an area.')
+    parser.add_argument('--area', default=settings.area, help='Area to search')
+    parser.add_argument('--max-results', type=int, default=60, help='Maximum results per search phrase')
+    args = parser.parse_args()
+
+    try:
+        storage = build_storage()
+        job = JobContext(tenant=settings.tenant, proxy=settings.proxy)
+        found = 0
+        with GoogleMapsScraper(debug=settings.headful, devtools=settings.devtools, job=job, id_strategy=build_id_strategy(settings.id_strategy)) as scraper:
+            for card in find_candidates(scraper, args.area, args.max_results):
+                scraper.cards[card['cid'] or card['url']] = card
+                result = scraper.get_account(card['url'])
+                restaurant = result.get('restaurant', {})
+                if not restaurant.get('_id'):
+                    continue
+                # Few reviews fit on the overview, so load the whole pane
+                reviews = result.get('reviews', [])
+                if scraper.open_reviews_tab(card['url']):
+                    reviews = scraper.get_reviews(0, restaurant_id=restaurant['_id']) or reviews
+                if classify_new(restaurant, reviews):
+                    storage.upsert_restaurant(restaurant)
+                    found += 1
+        storage.close()
+        logger.info(f"Stored {found} newly opened restaurants in {args.area}")
+    except Exception as e:
+        logger.error(f"Discovery failed: {str(e)}")
+        sys.exit(1)
+
+if __name__ == "__main__":
+    main()
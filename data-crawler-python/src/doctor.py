@@ -0,0 +1,141 @@
+"""
+Headless detection self-test.
+Launches a browser with the configured stealth and proxy settings, checks
+the fingerprint it exposes and visits public bot-detection pages, so an
+operator can verify the setup before spending a crawl budget.
+
+Usage:
+    python -m src.doctor
+"""
+
+import json
+import logging
+import sys
+import time
+from typing import Dict, List
+
+from selenium.webdriver.common.by import By
+
+from src.config.settings import settings
+from src.crawler.google_maps_crawler import GoogleMapsScraper
+from src.main import build_proxy_pool, configure_logging
+from src.models.job_context import JobContext
+
+logger = logging.getLogger(__name__)
+
+IP_CHECK_URL = 'https://api.ipify.org?format=json'
+SANNYSOFT_URL = 'https://bot.sannysoft.com/'
+HEADLESS_TEST_URL = 'https://arh.antoinevastel.com/bots/areyouheadless'
+PAGE_SETTLE_SECONDS = 3
+
+FINGERPRINT_SCRIPT = """
+let vendor = null, renderer = null;
+try {
+    const gl = document.createElement('canvas').getContext('webgl');
+    vendor = gl.getParameter(37445);
+    renderer = gl.getParameter(37446);
+} catch (e) {}
+return {
+    webdriver: navigator.webdriver,
+    user_agent: navigator.userAgent,
+    languages: navigator.languages,
+    plugins: navigator.plugins.length,
+    chrome_object: typeof window.chrome !== 'undefined',
+    timezone: Intl.DateTimeFormat().resolvedOptions().timeZone,
+    viewport: [window.innerWidth, window.innerHeight],
+    webgl_vendor: vendor,
+    webgl_renderer: renderer,
+};
+"""
+
+def fingerprint_findings(fingerprint: Dict) -> List[Dict]:
+    """Judge the fingerprint properties that headless detectors look at."""
+    renderer = (fingerprint.get('webgl_renderer') or '').lower()
+    checks = [
+        ('navigator.webdriver hidden', not fingerprint.get('webdriver')),
+        ('user agent not headless', 'headless' not in (fingerprint.get('user_agent') or '').lower()),
+        ('languages set', bool(fingerprint.get('languages'))),
+        ('plugins present', (fingerprint.get('plugins') or 0) > 0),
+        ('window.chrome present', bool(fingerprint.get('chrome_object'))),
+        ('WebGL renderer not software', bool(renderer) and 'swiftshader' not in renderer and 'llvmpipe' not in renderer),
+    ]
+    return [{'check': name, 'ok': ok} for name, ok in checks]
+
+def sannysoft_findings(driver) -> List[Dict]:
+    """Return the failed rows of the sannysoft fingerprint table."""
+    driver.get(SANNYSOFT_URL)
+    time.sleep(PAGE_SETTLE_SECONDS)
+    findings = []
+    for cell in driver.find_elements(By.CSS_SELECTOR, 'td.failed'):
+        row = cell.find_element(By.XPATH, './..')
+        name = row.find_elements(By.TAG_NAME, 'td')[0].text.strip()
+        findings.append({'check': f"sannysoft: {name}", 'ok': False})
+    if not findings:
+        findings.append({'check': 'sannysoft: all tests', 'ok': True})
+    return findings
+
+def headless_test_findings(driver) -> List[Dict]:
+    """Return the verdict of the "are you headless" test page."""
+    driver.get(HEADLESS_TEST_URL)
+    time.sleep(PAGE_SETTLE_SECONDS)
+    verdict = driver.find_element(By.ID, 'res').text.strip()
+    return [{'check': f"areyouheadless: {verdict}", 'ok': 'not chrome headless' in verdict.lower()}]
+
+def run_doctor(scraper: GoogleMapsScraper) -> Dict:
+    """Collect the exit IP, fingerprint and detection page findings of a scraper's browser."""
+    driver = scraper.driver
+    report = {'proxy': scraper.proxy, 'stealth': scraper.stealth}
+
+    driver.get(IP_CHECK_URL)
+    try:
+        report['ip'] = json.loads(driver.find_element(By.TAG_NAME, 'body').text).get('ip')
+    except Exception as e:
+        report['ip'] = None
+        logger.warning(f"Could not determine exit IP: {str(e)}")
+
+    report['fingerprint'] = driver.execute_script(FINGERPRINT_SCRIPT)
+    findings = fingerprint_findings(report['fingerprint'])
+    for page_check in (sannysoft_findings, headless_test_findings):
+        try:
+            findings.extend(page_check(driver))
+        except Exception as e:
+            findings.append({'check': f"{page_check.__name__}: page unavailable ({str(e)})", 'ok': None})
+    report['findings'] = findings
+    report['healthy'] = all(f['ok'] is not False for f in findings)
+    return report
+
+def format_report(report: Dict) -> str:
+    """Render a doctor report for the terminal."""
+    fingerprint = report['fingerprint']
+    lines = [
+        'Browser fingerprint: ' + ('OK' if report['healthy'] else 'DETECTABLE'),
+        f"  exit IP:   {report.get('ip') or 'unknown'} (proxy: {report.get('proxy') or 'none'})",
+        f"  stealth:   {'on' if report.get('stealth') else 'off'}",
+        f"  UA:        {fingerprint.get('user_agent')}",
+        f"  timezone:  {fingerprint.get('timezone')}, viewport {fingerprint.get('viewport')}",
+        f"  WebGL:     {fingerprint.get('webgl_vendor')} / {fingerprint.get('webgl_renderer')}",
+    ]
+    for finding in report['findings']:
+        status = {True: 'ok  ', False: 'FAIL', None: 'skip'}[finding['ok']]
+        lines.append(f"    {status} {finding['check']}")
+    return '\n'.join(lines)
+
+def main():
+    configure_logging()
+    try:
+        job = JobContext(tenant=settings.tenant, proxy=settings.proxy)
+        report = None
+        with GoogleMapsScraper(debug=settings.headful, devtools=settings.devtools, job=job, proxy_pool=build_proxy_pool(),
+                               stealth=settings.stealth) as scraper:
+            report = run_doctor(scraper)
+        if not report:
+            sys.exit(1)
+        print(format_report(report))
+        if not report['healthy']:
+            sys.exit(1)
+    except Exception as e:
+        logger.error(f"Doctor failed: {str(e)}")
+        sys.exit(1)
+
+if __name__ == "__main__":
+    main()
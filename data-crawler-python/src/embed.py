@@ -0,0 +1,158 @@
+"""
+In-process embedding of the crawler.
+Lets a host application such as the smart-dine API run light refresh
+crawls itself: start() brings up the storage sinks, browser pool and
+scheduler with the same settings as the standalone crawler, refresh()
+queues place URLs, health() reports on the running crawler and stop()
+waits for queued places and releases the browsers.
+
+Example:
+    crawler = EmbeddedCrawler(concurrency=1)
+    crawler.start()
+    crawler.refresh(["https://www.google.com/maps/place/..."])
+    ...
+    crawler.stop()
+"""
+
+import logging
+import threading
+import time
+from typing import Dict, Iterable, Optional
+
+from src.config.settings import settings
+from src.crawler.blocking import build_block_handler
+from src.crawler.browser_pool import BrowserPool
+from src.crawler.costs import RunCosts
+from src.crawler.google_maps_crawler import GoogleMapsScraper
+from src.crawler.scheduler import Scheduler
+from src.main import (build_consent, build_fields, build_identity, build_locale, build_media_downloader,
+                      build_places_enricher, build_proxy_pool, build_selectors, build_storage, build_throttle,
+                      build_website_crawler, process_restaurant, scheduler_limits)
+from src.models.job_context import JobContext
+from src.storage.fanout import FanOutStorage
+from src.storage.ids import build_id_strategy
+
+logger = logging.getLogger(__name__)
+
+class EmbeddedCrawler:
+    """Crawler wired into a host application's lifecycle."""
+
+    def __init__(self, concurrency: int = 1, storage: Optional[FanOutStorage] = None, browsers: Optional[int] = None):
+        """Configure from settings; `storage` overrides CRAWLER_SINKS and `browsers` CRAWLER_MAX_BROWSERS."""
+        self.concurrency = concurrency
+        self.browsers = browsers
+        self.storage = storage
+        self.job = JobContext(tenant=settings.tenant, proxy=settings.proxy)
+        self.pool: Optional[BrowserPool] = None
+        self.scheduler: Optional[Scheduler] = None
+        self.started_at: Optional[float] = None
+        self.stats = {'queued': 0, 'refreshed': 0, 'failed': 0}
+        self.last_error: Optional[str] = None
+        self._lock = threading.Lock()
+
+    def start(self):
+        """Start the sinks, browser pool and scheduler; browsers launch on first use."""
+        if self.scheduler:
+            return
+        self.storage = self.storage or build_storage()
+        self.media = build_media_downloader()
+        self.website = build_website_crawler()
+        self.places = build_places_enricher()
+        throttle = build_throttle()
+        identity = build_identity()
+        locale = build_locale()
+        consent = build_consent()
+        fields = build_fields()
+        selectors = build_selectors()
+        proxy_pool = build_proxy_pool()
+        block_handler = build_block_handler(settings.block_handler, settings.captcha_solver_url)
+        id_strategy = build_id_strategy(settings.id_strategy)
+        self.costs = RunCosts(
+            proxy_per_gb=settings.cost_proxy_per_gb,
+            captcha_per_solve=settings.cost_captcha_per_solve,
+            browser_per_hour=settings.cost_browser_per_hour
+        )
+        concurrency, rate_limits = scheduler_limits(self.concurrency)
+
+        def new_scraper() -> GoogleMapsScraper:
+            return GoogleMapsScraper(
+                debug=settings.headful,
+                devtools=settings.devtools,
+                job=self.job,
+                throttle=throttle,
+                costs=self.costs,
+                id_strategy=id_strategy,
+                proxy_pool=proxy_pool,
+                block_handler=block_handler,
+                stealth=settings.stealth,
+                identity=identity,
+                locale=locale,
+                consent=consent,
+                fields=fields,
+                selectors=selectors,
+                feed_snapshots=settings.feed_snapshots,
+                feed_max_seconds=settings.feed_max_seconds,
+                slow_mo=settings.slow_mo,
+                failure_dir=settings.failure_dir,
+                photo_size=settings.photo_size,
+                review_scroll_budget=settings.review_scroll_budget,
+                max_reviews=settings.max_reviews
+            )
+
+        self.pool = BrowserPool(new_scraper, size=self.browsers or concurrency, max_jobs=settings.browser_max_jobs,
+                                max_minutes=settings.browser_max_minutes)
+        self.scheduler = Scheduler(concurrency, rate_limits, throttle)
+        self.started_at = time.time()
+        logger.info(f"{self.job.log_prefix()} Embedded crawler started with concurrency {concurrency}")
+
+    def refresh(self, urls: Iterable[str]) -> int:
+        """Queue place URLs for a refresh and return how many were queued."""
+        if not self.scheduler:
+            raise RuntimeError("Embedded crawler is not started")
+        count = 0
+        for url in urls:
+            self.scheduler.submit(self._refresh_url, url)
+            count += 1
+        with self._lock:
+            self.stats['queued'] += count
+        return count
+
+    def _refresh_url(self, url: str):
+        try:
+            with self.pool.browser() as scraper:
+                ok = process_restaurant(scraper, self.storage, url, media=self.media, website=self.website,
+                                        places=self.places)
+        except Exception as e:
+            logger.error(f"{self.job.log_prefix()} Embedded refresh of {url} failed: {str(e)}")
+            ok = False
+            with self._lock:
+                self.last_error = str(e)
+        with self._lock:
+            self.stats['refreshed' if ok else 'failed'] += 1
+
+    def health(self) -> Dict:
+        """Return whether the crawler is running and how its refreshes went, for the host's health checks."""
+        with self._lock:
+            stats = dict(self.stats)
+            last_error = self.last_error
+        scheduler = self.scheduler
+        running = scheduler is not None
+        return {
+            'running': running,
+            'uptime_seconds': round(time.time() - self.started_at) if running else 0,
+            'browsers': len(self.pool.scrapers) if self.pool else 0,
+            'active': scheduler.active if running else 0,
+            'pending': stats['queued'] - stats['refreshed'] - stats['failed'],
+            **stats,
+            'last_error': last_error,
+        }
+
+    def stop(self):
+        """Wait for queued refreshes, then close the browsers and sinks."""
+        if not self.scheduler:
+            return
+        scheduler, self.scheduler = self.scheduler, None
+        scheduler.close()
+        self.pool.close()
+        self.storage.close()
+        logger.info(f"{self.job.log_prefix()} Embedded crawler stopped: {self.stats}")
@@ -0,0 +1,3 @@
+"""
+Enrichment package initialization file.
+"""
\ No newline at end of file
@@ -0,0 +1,91 @@
+"""
+Cuisine inference for places whose Google category is generic.
+Classifiers are pluggable: the embedded keyword rules work offline, and
+HttpCuisineClassifier delegates to an external model endpoint.
+"""
+
+import logging
+import re
+from typing import Dict, List, Optional
+
+import requests
+
+logger = logging.getLogger(__name__)
+
+GENERIC_CATEGORIES = {'restaurant', 'food', 'eatery', 'bar', 'cafe', 'takeout restaurant'}
+
+# Keywords are matched as whole words against name, categories, menu and reviews
+CUISINE_KEYWORDS = {
+    'Italian': ['pizza', 'pasta', 'trattoria', 'risotto', 'gnocchi', 'osteria'],
+    'Japanese': ['sushi', 'ramen', 'izakaya', 'sashimi', 'udon', 'tempura'],
+    'Chinese': ['dim sum', 'dumpling', 'szechuan', 'sichuan', 'wonton', 'peking'],
+    'Mexican': ['taco', 'tacos', 'burrito', 'taqueria', 'quesadilla', 'enchilada'],
+    'Indian': ['curry', 'tandoori', 'masala', 'biryani', 'naan', 'dosa'],
+    'Thai': ['pad thai', 'thai', 'tom yum', 'green curry'],
+    'Vietnamese': ['pho', 'banh mi', 'vietnamese'],
+    'Korean': ['bibimbap', 'bulgogi', 'kimchi', 'korean bbq'],
+    'American': ['burger', 'burgers', 'bbq', 'wings', 'diner'],
+    'French': ['bistro', 'brasserie', 'croissant', 'crepe'],
+}
+
+class CuisineClassifier:
+    """Interface for cuisine classifiers."""
+
+    def classify(self, text: Dict[str, List[str]]) -> Optional[str]:
+        """Return a cuisine for the given text fields, or None if unsure."""
+        raise NotImplementedError
+
+class RuleCuisineClassifier(CuisineClassifier):
+    """Keyword-scoring classifier; names and categories weigh more than reviews."""
+
+    WEIGHTS = {'name': 3, 'categories': 3, 'menu': 2, 'reviews': 1}
+
+    def classify(self, text: Dict[str, List[str]]) -> Optional[str]:
+        scores = {}
+        for field, values in text.items():
+            corpus = ' '.join(values).lower()
+            for cuisine, keywords in CUISINE_KEYWORDS.items():
+                hits = sum(len(re.findall(rf'\b{re.escape(k)}\b', corpus)) for k in keywords)
+                if hits:
+                    scores[cuisine] = scores.get(cuisine, 0) + hits * self.WEIGHTS.get(field, 1)
+        if not scores:
+            return None
+        return max(scores, key=scores.get)
+
+class HttpCuisineClassifier(CuisineClassifier):
+    """Classifier backed by an HTTP endpoint that answers {"cuisine": "..."}."""
+
+    def __init__(self, url: str, timeout: float = 10):
+        self.url = url
+        self.timeout = timeout
+
+    def classify(self, text: Dict[str, List[str]]) -> Optional[str]:
+        try:
+            response = requests.post(self.url, json=text, timeout=self.timeout)
+            response.raise_for_status()
+            return response.json().get('cuisine')
+        except Exception as e:
+            logger.error(f"Cuisine classifier request failed: {str(e)}")
+            return None
+
+def is_generic(categories: List[str]) -> bool:
+    """Return True if Google only gave a generic category."""
+    return not categories or all(c.strip().lower() in GENERIC_CATEGORIES for c in categories)
+
+def infer_cuisine(restaurant: Dict, reviews: List[Dict], classifier: CuisineClassifier) -> Optional[str]:
+    """Set attributes.inferred_cuisine when the category is generic and return it."""
+    attributes = restaurant.setdefault('attributes', {})
+    categories = attributes.get('cuisine_type') or []
+    if not is_generic(categories):
+        return None
+
+    text = {
+        'name': [restaurant.get('name') or ''],
+        'categories': categories,
+        'menu': [item.get('name') or '' for item in restaurant.get('menu') or []],
+        'reviews': [review.get('text') or '' for review in reviews],
+    }
+    cuisine = classifier.classify(text)
+    if cuisine:
+        attributes['inferred_cuisine'] = cuisine
+    return cuisine
@@ -0,0 +1,33 @@
+"""
+Parsing of Google's relative review dates ("3 months ago").
+"""
+
+import re
+from datetime import datetime, timedelta
+from typing import Optional
+
+UNIT_DAYS = {
+    'minute': 1 / 1440,
+    'hour': 1 / 24,
+    'day': 1,
+    'week': 7,
+    'month': 30,
+    'year': 365,
+}
+
+def parse_relative_date(text: Optional[str], now: Optional[datetime] = None) -> Optional[datetime]:
+    """Convert a relative date such as "a week ago" or "3 months ago" to an approximate datetime."""
+    if not text:
+        return None
+    now = now or datetime.now()
+    match = re.search(r'(an?|\d+)\s+(minute|hour|day|week|month|year)s?\s+ago', text.lower())
+    if not match:
+        return None
+    amount = 1 if match.group(1) in ('a', 'an') else int(match.group(1))
+    return now - timedelta(days=amount * UNIT_DAYS[match.group(2)])
+
+def age_in_days(text: Optional[str], now: Optional[datetime] = None) -> Optional[float]:
+    """Return how many days ago a relative date was."""
+    now = now or datetime.now()
+    parsed = parse_relative_date(text, now)
+    return (now - parsed).total_seconds() / 86400 if parsed else None
@@ -0,0 +1,45 @@
+"""
+Deal mining from review text.
+Finds mentions of happy hours, lunch specials and discounts and turns
+them into a structured deals list with the source snippet and date.
+"""
+
+import re
+from typing import Dict, List, Optional
+
+from .text import snippet
+
+TIME = r'\d{1,2}(?::\d{2})?\s*(?:am|pm)?'
+
+DEAL_PATTERNS = {
+    'happy_hour': re.compile(rf'happy\s*hour(?:\s*(?:is|from|between|runs)?\s*(?P<start>{TIME})\s*(?:-|–|to|until|and)\s*(?P<end>{TIME}))?', re.I),
+    'lunch_special': re.compile(r'lunch\s+(?:special|deal|menu|set)s?', re.I),
+    'discount': re.compile(r'\d{1,2}\s*%\s*off|half[\s-]price|2\s*for\s*1|two\s+for\s+one|bogo', re.I),
+}
+
+def mine_deals(reviews: List[Dict]) -> List[Dict]:
+    """Return the deals mentioned in a place's reviews."""
+    deals = []
+    seen = set()
+    for review in reviews:
+        text = review.get('text') or ''
+        for kind, pattern in DEAL_PATTERNS.items():
+            for match in pattern.finditer(text):
+                deal = {
+                    'type': kind,
+                    'snippet': snippet(text, match.start(), match.end()),
+                    'date': review.get('date'),
+                    'source': 'review',
+                    'review_id': review.get('_id'),
+                }
+                groups = match.groupdict()
+                if groups.get('start') and groups.get('end'):
+                    deal['start'] = groups['start'].strip()
+                    deal['end'] = groups['end'].strip()
+
+                key = (kind, deal.get('start'), deal.get('end'), match.group(0).lower())
+                if key in seen:
+                    continue
+                seen.add(key)
+                deals.append(deal)
+    return deals
@@ -0,0 +1,28 @@
+"""
+Owner engagement metrics.
+Derives how often and how quickly an owner answers reviews, as a signal
+for ranking partner-worthy restaurants.
+"""
+
+import statistics
+from typing import Dict, List, Optional
+
+from .dates import age_in_days
+
+def response_metrics(reviews: List[Dict]) -> Optional[Dict]:
+    """Return the response rate and median response latency in days of a place's reviews."""
+    if not reviews:
+        return None
+    responded = [r for r in reviews if r.get('owner_response')]
+    latencies = []
+    for review in responded:
+        review_age = age_in_days(review.get('date'))
+        response_age = age_in_days(review['owner_response'].get('date'))
+        if review_age is not None and response_age is not None:
+            # Relative dates are coarse, so a same-period reply counts as immediate
+            latencies.append(max(review_age - response_age, 0))
+    return {
+        'response_rate': round(len(responded) / len(reviews), 3),
+        'median_response_days': round(statistics.median(latencies), 1) if latencies else None,
+        'reviews_considered': len(reviews),
+    }
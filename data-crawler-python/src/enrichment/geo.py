@@ -0,0 +1,88 @@
+"""
+Geographic helpers.
+"""
+
+import math
+import re
+from typing import Dict, Optional, Tuple
+
+EARTH_RADIUS_M = 6371000
+
+def haversine_m(lat1: float, lng1: float, lat2: float, lng2: float) -> float:
+    """Return the great-circle distance between two points in meters."""
+    phi1, phi2 = math.radians(lat1), math.radians(lat2)
+    d_phi = math.radians(lat2 - lat1)
+    d_lambda = math.radians(lng2 - lng1)
+    a = math.sin(d_phi / 2) ** 2 + math.cos(phi1) * math.cos(phi2) * math.sin(d_lambda / 2) ** 2
+    return 2 * EARTH_RADIUS_M * math.asin(math.sqrt(a))
+
+def lat_lng(restaurant: Dict) -> Optional[Tuple[float, float]]:
+    """Return (lat, lng) of a restaurant from its GeoJSON coordinates."""
+    coordinates = (restaurant.get('location') or {}).get('coordinates') or []
+    if len(coordinates) != 2:
+        return None
+    return coordinates[1], coordinates[0]
+
+GEOHASH_ALPHABET = '0123456789bcdefghjkmnpqrstuvwxyz'
+
+def geohash(lat: float, lng: float, precision: int = 7) -> str:
+    """Encode a point as a geohash; 7 characters is a cell of about 150 m."""
+    lat_range, lng_range = [-90.0, 90.0], [-180.0, 180.0]
+    chars, bits, bit_count, even = [], 0, 0, True
+    while len(chars) < precision:
+        value, interval = (lng, lng_range) if even else (lat, lat_range)
+        mid = (interval[0] + interval[1]) / 2
+        bits <<= 1
+        if value >= mid:
+            bits |= 1
+            interval[0] = mid
+        else:
+            interval[1] = mid
+        even = not even
+        bit_count += 1
+        if bit_count == 5:
+            chars.append(GEOHASH_ALPHABET[bits])
+            bits, bit_count = 0, 0
+    return ''.join(chars)
+
+COORDINATES_IN_URL = [
+    re.compile(r'!3d(-?\d+\.\d+)!4d(-?\d+\.\d+)'),
+    re.compile(r'@(-?\d+\.\d+),(-?\d+\.\d+)'),
+]
+
+def coordinates_from_url(url: Optional[str]) -> Optional[Tuple[float, float]]:
+    """Return (lat, lng) from a Maps URL, preferring the place's "!3d…!4d…" data
+    parameters over the "@lat,lng" map viewport."""
+    for pattern in COORDINATES_IN_URL:
+        match = pattern.search(url or '')
+        if match:
+            lat, lng = float(match.group(1)), float(match.group(2))
+            if -90 <= lat <= 90 and -180 <= lng <= 180:
+                return lat, lng
+    return None
+
+PLUS_CODE_ALPHABET = '23456789CFGHJMPQRVWX'
+PLUS_CODE = re.compile(r'\b([23456789CFGHJMPQRVWX]{2,8}0*\+[23456789CFGHJMPQRVWX]*)', re.IGNORECASE)
+
+def decode_plus_code(code: str) -> Optional[Tuple[float, float]]:
+    """Return the center (lat, lng) of a full plus code such as "849VCWC8+R9";
+    short codes like "CWC8+R9 Mountain View" need a reference point and give None."""
+    match = PLUS_CODE.search(code or '')
+    if not match or match.group(1).index('+') != 8:
+        return None
+    digits = match.group(1).upper().replace('+', '').rstrip('0').replace('0', '')
+    lat, lng, resolution = -90.0, -180.0, 20.0
+    lat_resolution = lng_resolution = resolution
+    for i in range(0, min(len(digits), 10) - 1, 2):
+        lat += PLUS_CODE_ALPHABET.index(digits[i]) * resolution
+        lng += PLUS_CODE_ALPHABET.index(digits[i + 1]) * resolution
+        lat_resolution = lng_resolution = resolution
+        resolution /= 20
+    # Digits after the first ten refine a 5 x 4 grid
+    for digit in digits[10:]:
+        row, column = divmod(PLUS_CODE_ALPHABET.index(digit), 4)
+        lat_resolution /= 5
+        lng_resolution /= 4
+        lat += row * lat_resolution
+        lng += column * lng_resolution
+    return lat + lat_resolution / 2, lng + lng_resolution / 2
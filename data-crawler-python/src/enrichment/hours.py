@@ -0,0 +1,110 @@
+"""
+Structured opening hours.
+Maps shows hours as locale-dependent text such as "11 AM–3 PM, 5–10 PM",
+"Open 24 hours" or "Closed". They are parsed into intervals with the
+weekday (0=Monday) and open/close times in minutes since midnight; an
+interval closing after midnight is marked overnight and its close time
+is past 1440. The raw text is kept on every interval.
+"""
+
+import re
+from typing import Dict, List, Optional
+
+DAYS = ['Monday', 'Tuesday', 'Wednesday', 'Thursday', 'Friday', 'Saturday', 'Sunday']
+MINUTES_PER_DAY = 24 * 60
+TIME = re.compile(r'(\d{1,2})(?:[:.](\d{2}))?\s*([AaPp]\.?[Mm]\.?)?')
+
+def day_index(name: str) -> Optional[int]:
+    """Return the weekday index of a day name or abbreviation, e.g. "Tue" -> 1."""
+    name = name.strip().lower()[:3]
+    for i, day in enumerate(DAYS):
+        if day.lower().startswith(name):
+            return i
+    return None
+
+def _clean(text: str) -> str:
+    # Maps uses narrow no-break spaces and en dashes
+    text = text.replace('\u202f', ' ').replace('\xa0', ' ')
+    return re.sub(r'\s*[–—-]\s*|\s+to\s+', '–', text).strip()
+
+def _minutes(match: re.Match, meridiem: Optional[str]) -> int:
+    hour, minute = int(match.group(1)), int(match.group(2) or 0)
+    meridiem = (match.group(3) or meridiem or '').lower().replace('.', '')
+    if meridiem == 'am' and hour == 12:
+        hour = 0
+    elif meridiem == 'pm' and hour != 12:
+        hour += 12
+    return hour * 60 + minute
+
+def _format(minutes: int) -> str:
+    minutes %= MINUTES_PER_DAY
+    return f"{minutes // 60:02d}:{minutes % 60:02d}"
+
+def parse_range(text: str) -> Optional[Dict]:
+    """Parse one range such as "11:30 AM–2 PM" or "17:00–01:00" into open/close minutes."""
+    parts = _clean(text).split('–')
+    if len(parts) != 2:
+        return None
+    start, end = TIME.fullmatch(parts[0].strip()), TIME.fullmatch(parts[1].strip())
+    if not start or not end:
+        return None
+    close = _minutes(end, None)
+    # "5–10 PM" shares the closing meridiem unless that would open after closing
+    open_ = _minutes(start, end.group(3))
+    if not start.group(3) and end.group(3) and open_ > close:
+        open_ = _minutes(start, 'am' if end.group(3).lower().startswith('p') else 'pm')
+    if close == 0 and open_ > 0:
+        close = MINUTES_PER_DAY
+    overnight = close <= open_
+    if overnight:
+        close += MINUTES_PER_DAY
+    return {'open': open_, 'close': close, 'overnight': overnight}
+
+def parse_day(day: int, text: str) -> List[Dict]:
+    """Parse the hours text of one weekday into intervals, a closed marker or an all-day interval."""
+    raw = text.strip()
+    lowered = raw.lower()
+    if not raw:
+        return []
+    if 'closed' in lowered:
+        return [{'day': day, 'closed': True, 'raw': raw}]
+    if '24 hours' in lowered or 'open 24' in lowered:
+        return [{'day': day, 'open': 0, 'close': MINUTES_PER_DAY, 'overnight': False, 'open_24h': True,
+                 'open_time': '00:00', 'close_time': '24:00', 'raw': raw}]
+    intervals = []
+    for part in re.split(r'\s*[,;]\s*', raw):
+        interval = parse_range(part)
+        if interval:
+            intervals.append({
+                'day': day,
+                **interval,
+                'open_time': _format(interval['open']),
+                'close_time': _format(interval['close']),
+                'raw': raw,
+            })
+    return intervals
+
+def parse_opening_hours(rows: Dict[str, str]) -> List[Dict]:
+    """Parse {day name: hours text} rows into intervals ordered by weekday."""
+    hours = []
+    for name, text in rows.items():
+        day = day_index(name)
+        if day is not None:
+            hours.extend(parse_day(day, text))
+    return sorted(hours, key=lambda h: (h['day'], h.get('open', -1)))
+
+def is_open(hours: List[Dict], weekday: int, minute: int) -> Optional[bool]:
+    """Return whether the place is open at `minute` past midnight local time on `weekday`,
+    counting intervals that run over from the day before; None if the hours are unknown.
+    Use timezones.local_weekday_minute to get the place's local time."""
+    if not hours:
+        return None
+    previous = (weekday - 1) % 7
+    for interval in hours:
+        if interval.get('closed'):
+            continue
+        if interval['day'] == weekday and interval['open'] <= minute < interval['close']:
+            return True
+        if interval['day'] == previous and interval['overnight'] and minute + MINUTES_PER_DAY < interval['close']:
+            return True
+    return False
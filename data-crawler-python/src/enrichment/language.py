@@ -0,0 +1,67 @@
+"""
+Review language detection.
+Non-Latin scripts are recognised by their Unicode ranges, Latin-script
+languages by the share of their most common words. Returns ISO 639-1
+codes, or None when a text is too short or ambiguous to tell.
+"""
+
+import re
+from typing import Dict, Optional
+
+SCRIPT_RANGES = [
+    ('ja', r'[぀-ヿ]'),
+    ('ko', r'[가-힯]'),
+    ('zh', r'[一-鿿]'),
+    ('th', r'[฀-๿]'),
+    ('ar', r'[؀-ۿ]'),
+    ('he', r'[֐-׿]'),
+    ('hi', r'[ऀ-ॿ]'),
+    ('el', r'[Ͱ-Ͽ]'),
+    ('ru', r'[Ѐ-ӿ]'),
+]
+
+STOPWORDS = {
+    'en': {'the', 'and', 'was', 'is', 'it', 'to', 'of', 'for', 'with', 'this', 'very', 'but', 'we', 'food', 'great'},
+    'es': {'el', 'la', 'de', 'que', 'y', 'muy', 'es', 'con', 'los', 'las', 'por', 'para', 'comida', 'pero', 'una'},
+    'fr': {'le', 'la', 'les', 'et', 'est', 'très', 'de', 'un', 'une', 'pour', 'avec', 'nous', 'pas', 'mais', 'bien'},
+    'de': {'der', 'die', 'das', 'und', 'ist', 'sehr', 'ein', 'eine', 'mit', 'nicht', 'wir', 'auch', 'essen', 'gut', 'war'},
+    'it': {'il', 'la', 'di', 'e', 'che', 'molto', 'è', 'un', 'una', 'per', 'con', 'non', 'ma', 'buono', 'cibo'},
+    'pt': {'o', 'a', 'de', 'que', 'e', 'muito', 'é', 'um', 'uma', 'para', 'com', 'não', 'mas', 'comida', 'bom'},
+    'nl': {'de', 'het', 'een', 'en', 'is', 'van', 'zeer', 'met', 'niet', 'we', 'was', 'heel', 'lekker', 'eten', 'goed'},
+}
+
+# Language names Maps shows in "See original (...)"
+LANGUAGE_NAMES: Dict[str, str] = {
+    'english': 'en', 'spanish': 'es', 'french': 'fr', 'german': 'de', 'italian': 'it',
+    'portuguese': 'pt', 'dutch': 'nl', 'japanese': 'ja', 'korean': 'ko', 'chinese': 'zh',
+    'thai': 'th', 'arabic': 'ar', 'hebrew': 'he', 'hindi': 'hi', 'greek': 'el', 'russian': 'ru',
+}
+
+MIN_WORDS = 3
+
+def detect_language(text: Optional[str]) -> Optional[str]:
+    """Return the ISO 639-1 code of a text's language, or None if it cannot be told."""
+    if not text or not text.strip():
+        return None
+    letters = [c for c in text if c.isalpha()]
+    if not letters:
+        return None
+    for code, pattern in SCRIPT_RANGES:
+        if len(re.findall(pattern, text)) / len(letters) > 0.3:
+            return code
+
+    words = re.findall(r"[^\W\d_]+", text.lower())
+    if len(words) < MIN_WORDS:
+        return None
+    scores = {code: sum(1 for word in words if word in stopwords) for code, stopwords in STOPWORDS.items()}
+    best = max(scores, key=scores.get)
+    ranked = sorted(scores.values(), reverse=True)
+    if ranked[0] == 0 or ranked[0] == ranked[1]:
+        return None
+    return best
+
+def language_code(name: Optional[str]) -> Optional[str]:
+    """Map a language name shown by Maps to its ISO 639-1 code."""
+    if not name:
+        return None
+    return LANGUAGE_NAMES.get(name.strip().lower())
@@ -0,0 +1,110 @@
+"""
+Offline reverse geocoding of places to suburb/locality and city.
+Boundaries are read from a GeoJSON FeatureCollection of Polygon or
+MultiPolygon features with "name", "kind" ("locality" or "city") and, for
+localities, "city" properties. The embedded dataset only holds coarse
+boundaries for the areas the crawler is usually run on; point
+CRAWLER_BOUNDARIES_FILE at a full admin-boundary export for other regions.
+"""
+
+import json
+import logging
+from pathlib import Path
+from typing import Dict, List, Optional, Tuple
+
+from .geo import lat_lng
+
+logger = logging.getLogger(__name__)
+
+EMBEDDED_BOUNDARIES = Path(__file__).parent / 'data' / 'localities.geojson'
+
+Ring = List[Tuple[float, float]]
+
+def _in_ring(lng: float, lat: float, ring: Ring) -> bool:
+    """Ray casting test of a point against one (lng, lat) ring."""
+    inside = False
+    j = len(ring) - 1
+    for i in range(len(ring)):
+        xi, yi = ring[i]
+        xj, yj = ring[j]
+        if (yi > lat) != (yj > lat) and lng < (xj - xi) * (lat - yi) / (yj - yi) + xi:
+            inside = not inside
+        j = i
+    return inside
+
+def _in_polygon(lng: float, lat: float, polygon: List[Ring]) -> bool:
+    """Return True if the point is inside the outer ring and outside every hole."""
+    return _in_ring(lng, lat, polygon[0]) and not any(_in_ring(lng, lat, hole) for hole in polygon[1:])
+
+def _ring_area(ring: Ring) -> float:
+    return abs(sum(x1 * y2 - x2 * y1 for (x1, y1), (x2, y2) in zip(ring, ring[1:] + ring[:1]))) / 2
+
+class Boundary:
+    """One named area with its polygons and bounding box."""
+
+    def __init__(self, properties: Dict, geometry: Dict):
+        self.name = properties['name']
+        self.kind = properties.get('kind', 'locality')
+        self.city = properties.get('city')
+        if geometry['type'] == 'Polygon':
+            self.polygons = [geometry['coordinates']]
+        elif geometry['type'] == 'MultiPolygon':
+            self.polygons = geometry['coordinates']
+        else:
+            raise ValueError(f"Unsupported geometry type {geometry['type']} for {self.name}")
+        points = [point for polygon in self.polygons for point in polygon[0]]
+        self.bbox = (min(p[1] for p in points), min(p[0] for p in points),
+                     max(p[1] for p in points), max(p[0] for p in points))
+        self.area = sum(_ring_area(polygon[0]) for polygon in self.polygons)
+
+    def contains(self, lat: float, lng: float) -> bool:
+        south, west, north, east = self.bbox
+        if not (south <= lat <= north and west <= lng <= east):
+            return False
+        return any(_in_polygon(lng, lat, polygon) for polygon in self.polygons)
+
+class LocalityIndex:
+    """Point lookups against a set of locality and city boundaries."""
+
+    def __init__(self, boundaries: List[Boundary]):
+        # Smallest areas first, so nested localities win over the ones around them
+        self.boundaries = sorted(boundaries, key=lambda b: b.area)
+
+    @classmethod
+    def from_file(cls, path: Optional[str] = None) -> 'LocalityIndex':
+        """Load boundaries from a GeoJSON file, the embedded dataset by default."""
+        with open(path or EMBEDDED_BOUNDARIES, 'r', encoding='utf-8') as f:
+            collection = json.load(f)
+        boundaries = [Boundary(feature['properties'], feature['geometry']) for feature in collection['features']]
+        logger.info(f"Loaded {len(boundaries)} boundaries from {path or EMBEDDED_BOUNDARIES}")
+        return cls(boundaries)
+
+    def lookup(self, lat: float, lng: float) -> Dict[str, Optional[str]]:
+        """Return the locality and city containing a point."""
+        locality = city = None
+        for boundary in self.boundaries:
+            if not boundary.contains(lat, lng):
+                continue
+            if boundary.kind == 'city':
+                city = city or boundary.name
+            elif locality is None:
+                locality = boundary.name
+                city = city or boundary.city
+            if locality and city:
+                break
+        return {'locality': locality, 'city': city}
+
+def tag_locality(restaurant: Dict, index: LocalityIndex) -> bool:
+    """Set location.locality, and location.city when the address had none; returns True if tagged."""
+    point = lat_lng(restaurant)
+    if not point:
+        return False
+    found = index.lookup(*point)
+    if not found['locality'] and not found['city']:
+        return False
+    location = restaurant.setdefault('location', {})
+    if found['locality']:
+        location['locality'] = found['locality']
+    if found['city'] and not location.get('city'):
+        location['city'] = found['city']
+    return True
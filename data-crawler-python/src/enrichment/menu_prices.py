@@ -0,0 +1,49 @@
+"""
+Menu price statistics.
+Turns the dish prices of a scraped menu into min/median/max figures and
+a per-person estimate, which are usable for budget filtering where the
+price range text is not.
+"""
+
+import statistics
+from typing import Dict, List, Optional
+
+from .prices import AMOUNT, parse_amount
+
+# A typical diner orders a main plus a share of starters and drinks
+DISHES_PER_PERSON = 1.5
+
+CURRENCY_SYMBOLS = {'$': 'USD', '€': 'EUR', '£': 'GBP', '¥': 'JPY', 'A$': 'AUD'}
+
+def parse_price(text: str) -> Optional[float]:
+    """Parse a price such as "$12.50", "12,50 €" or "¥1,200" into a float."""
+    if text is None:
+        return None
+    if isinstance(text, (int, float)):
+        return float(text)
+    match = AMOUNT.search(text)
+    return parse_amount(match.group(0)) if match else None
+
+def detect_currency(items: List[Dict]) -> Optional[str]:
+    """Return the currency code of the first price carrying a known symbol."""
+    for item in items:
+        price = str(item.get('price') or '')
+        for symbol in sorted(CURRENCY_SYMBOLS, key=len, reverse=True):
+            if symbol in price:
+                return CURRENCY_SYMBOLS[symbol]
+    return None
+
+def menu_price_stats(items: List[Dict]) -> Optional[Dict]:
+    """Compute price statistics for menu items with a 'price' field."""
+    prices = [p for p in (parse_price(item.get('price')) for item in items) if p]
+    if not prices:
+        return None
+    median = statistics.median(prices)
+    return {
+        'min': min(prices),
+        'median': median,
+        'max': max(prices),
+        'per_person': round(median * DISHES_PER_PERSON, 2),
+        'currency': detect_currency(items),
+        'item_count': len(prices),
+    }
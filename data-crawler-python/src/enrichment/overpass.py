@@ -0,0 +1,135 @@
+"""
+OpenStreetMap places through the Overpass API.
+An alternative provider for seeding the database without Google at all:
+queries Overpass for amenity=restaurant (or other amenities) nodes, ways
+and relations in a bounding box and maps their tags into restaurant
+documents like the scraper's, with source "osm". OSM has no ratings,
+reviews or photos. Opening hours in OSM syntax, e.g.
+"Mo-Fr 11:00-22:00; Sa 12:00-23:00", are converted for the common forms
+and always kept as written.
+"""
+
+import logging
+import re
+from typing import Dict, Iterable, List, Optional, Tuple
+
+import requests
+
+from .hours import parse_opening_hours
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_OVERPASS_URL = 'https://overpass-api.de/api/interpreter'
+DEFAULT_AMENITIES = ['restaurant']
+QUERY_TIMEOUT = 120
+OSM_DAYS = ['Mo', 'Tu', 'We', 'Th', 'Fr', 'Sa', 'Su']
+DAY_NAMES = ['Monday', 'Tuesday', 'Wednesday', 'Thursday', 'Friday', 'Saturday', 'Sunday']
+OSM_RULE = re.compile(r'^((?:(?:Mo|Tu|We|Th|Fr|Sa|Su)(?:-(?:Mo|Tu|We|Th|Fr|Sa|Su))?,?)+)?\s*(.*)$')
+
+BoundingBox = Tuple[float, float, float, float]
+
+def build_query(bbox: BoundingBox, amenities: Iterable[str] = DEFAULT_AMENITIES) -> str:
+    """Return the Overpass QL query of the amenities in a south,west,north,east bounding box."""
+    area = ','.join(str(v) for v in bbox)
+    amenity = '|'.join(re.escape(a) for a in amenities)
+    return (f'[out:json][timeout:{QUERY_TIMEOUT}];\n'
+            f'nwr["amenity"~"^({amenity})$"]({area});\n'
+            'out center tags;')
+
+def _days(spec: Optional[str]) -> List[int]:
+    """Expand "Mo-Fr,Su" into weekday indexes; no day spec means every day."""
+    if not spec:
+        return list(range(7))
+    days = []
+    for part in spec.strip(',').split(','):
+        first, _, last = part.partition('-')
+        start = OSM_DAYS.index(first)
+        end = OSM_DAYS.index(last) if last else start
+        # Ranges may wrap around the week, e.g. "Fr-Mo"
+        days.extend((start + i) % 7 for i in range((end - start) % 7 + 1))
+    return days
+
+def opening_hours_rows(text: Optional[str]) -> Dict[str, str]:
+    """Convert OSM opening_hours into {day name: hours text}; rules it cannot read are skipped."""
+    if not text:
+        return {}
+    if text.strip() == '24/7':
+        return {name: 'Open 24 hours' for name in DAY_NAMES}
+    rows = {}
+    # Later rules override earlier ones for the days they name
+    for rule in text.split(';'):
+        match = OSM_RULE.match(rule.strip())
+        if not match or not match.group(2):
+            continue
+        hours = match.group(2).strip()
+        if hours in ('off', 'closed'):
+            hours = 'Closed'
+        elif not re.fullmatch(r'\d{1,2}:\d{2}-\d{1,2}:\d{2}(,\s*\d{1,2}:\d{2}-\d{1,2}:\d{2})*', hours):
+            continue
+        for day in _days(match.group(1)):
+            rows[DAY_NAMES[day]] = hours
+    return rows
+
+def _address(tags: Dict[str, str]) -> Optional[str]:
+    street = ' '.join(v for v in (tags.get('addr:housenumber'), tags.get('addr:street')) if v)
+    city = ' '.join(v for v in (tags.get('addr:state'), tags.get('addr:postcode')) if v)
+    parts = [p for p in (street, tags.get('addr:city'), city, tags.get('addr:country')) if p]
+    return ', '.join(parts) or tags.get('addr:full')
+
+def element_to_restaurant(element: Dict) -> Optional[Dict]:
+    """Map an Overpass element into a restaurant document; None for unnamed places.
+    `_id` is left to the ID strategy."""
+    tags = element.get('tags') or {}
+    if not tags.get('name'):
+        return None
+    osm_id = f"{element['type']}/{element['id']}"
+    center = element.get('center') or element
+    restaurant = {
+        'url': f"https://www.openstreetmap.org/{osm_id}",
+        'name': tags['name'],
+        'location': {
+            'type': 'Point',
+            'coordinates': [],
+            'address': _address(tags),
+            'postal_code': tags.get('addr:postcode'),
+            'city': tags.get('addr:city'),
+            'state': tags.get('addr:state'),
+            'country': tags.get('addr:country'),
+        },
+        'osm_id': osm_id,
+        'phone': tags.get('phone') or tags.get('contact:phone'),
+        'website': tags.get('website') or tags.get('contact:website'),
+        'attributes': {},
+        'opening_hours': [],
+        'photos': [],
+        'source': 'osm',
+    }
+    if center.get('lat') is not None and center.get('lon') is not None:
+        restaurant['location']['coordinates'] = [center['lon'], center['lat']]
+    cuisines = [c.strip().replace('_', ' ') for c in (tags.get('cuisine') or '').split(';') if c.strip()]
+    restaurant['attributes']['cuisine_type'] = cuisines or [tags['amenity'].replace('_', ' ')]
+    if tags.get('opening_hours'):
+        restaurant['osm_opening_hours'] = tags['opening_hours']
+        rows = opening_hours_rows(tags['opening_hours'])
+        if rows:
+            restaurant['opening_hours'] = parse_opening_hours(rows)
+            restaurant['opening_hours_raw'] = rows
+    return restaurant
+
+class OverpassClient:
+    """Fetches OSM places from an Overpass API endpoint."""
+
+    def __init__(self, url: str = DEFAULT_OVERPASS_URL, user_agent: Optional[str] = None):
+        self.url = url
+        self.user_agent = user_agent
+
+    def places(self, bbox: BoundingBox, amenities: Iterable[str] = DEFAULT_AMENITIES) -> List[Dict]:
+        """Return the named amenities in a bounding box as restaurant documents."""
+        headers = {'User-Agent': self.user_agent} if self.user_agent else {}
+        response = requests.post(self.url, data={'data': build_query(bbox, amenities)}, headers=headers,
+                                 timeout=QUERY_TIMEOUT + 30)
+        response.raise_for_status()
+        elements = response.json().get('elements', [])
+        restaurants = [r for r in (element_to_restaurant(e) for e in elements) if r]
+        logger.info(f"Overpass returned {len(elements)} elements, {len(restaurants)} named places")
+        return restaurants
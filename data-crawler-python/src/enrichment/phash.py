@@ -0,0 +1,82 @@
+"""
+Perceptual hashing of place thumbnails.
+Nearby places sharing a near-identical image are a strong signal of a
+duplicate or rebranded listing.
+"""
+
+import io
+import logging
+from typing import Dict, List, Optional
+
+import requests
+from PIL import Image
+
+from .geo import haversine_m, lat_lng
+from .photos import photo_url
+
+logger = logging.getLogger(__name__)
+
+HASH_SIZE = 8
+MAX_HAMMING_DISTANCE = 6
+MAX_PAIR_DISTANCE_M = 200
+
+def dhash(image: Image.Image, hash_size: int = HASH_SIZE) -> str:
+    """Return the difference hash of an image as a hex string."""
+    gray = image.convert('L').resize((hash_size + 1, hash_size), Image.LANCZOS)
+    pixels = list(gray.getdata())
+    bits = 0
+    for row in range(hash_size):
+        for col in range(hash_size):
+            left = pixels[row * (hash_size + 1) + col]
+            right = pixels[row * (hash_size + 1) + col + 1]
+            bits = (bits << 1) | (1 if left > right else 0)
+    return f"{bits:0{hash_size * hash_size // 4}x}"
+
+def hamming(hash_a: str, hash_b: str) -> int:
+    """Return the number of differing bits between two hex hashes."""
+    return bin(int(hash_a, 16) ^ int(hash_b, 16)).count('1')
+
+def thumbnail_url(restaurant: Dict) -> Optional[str]:
+    """Return the image that represents a place."""
+    if restaurant.get('thumbnail'):
+        return restaurant['thumbnail']
+    photos = restaurant.get('photos') or []
+    return photo_url(photos[0]) if photos else None
+
+def hash_thumbnail(url: str, timeout: float = 10) -> Optional[str]:
+    """Download an image and return its perceptual hash."""
+    try:
+        response = requests.get(url, timeout=timeout)
+        response.raise_for_status()
+        return dhash(Image.open(io.BytesIO(response.content)))
+    except Exception as e:
+        logger.error(f"Could not hash thumbnail {url}: {str(e)}")
+        return None
+
+def flag_duplicate_thumbnails(restaurants: List[Dict]) -> List[Dict]:
+    """Hash thumbnails and flag nearby pairs with near-identical images.
+
+    Sets thumbnail_hash on each restaurant and possible_duplicates on both
+    members of a flagged pair; returns the flagged pairs.
+    """
+    for restaurant in restaurants:
+        url = thumbnail_url(restaurant)
+        if url and not restaurant.get('thumbnail_hash'):
+            restaurant['thumbnail_hash'] = hash_thumbnail(url)
+
+    hashed = [r for r in restaurants if r.get('thumbnail_hash') and lat_lng(r)]
+    pairs = []
+    for i, first in enumerate(hashed):
+        for second in hashed[i + 1:]:
+            distance = haversine_m(*lat_lng(first), *lat_lng(second))
+            if distance > MAX_PAIR_DISTANCE_M:
+                continue
+            bits = hamming(first['thumbnail_hash'], second['thumbnail_hash'])
+            if bits > MAX_HAMMING_DISTANCE:
+                continue
+            pairs.append({'a': first['_id'], 'b': second['_id'], 'distance_m': round(distance), 'hamming': bits})
+            first.setdefault('possible_duplicates', []).append(second['_id'])
+            second.setdefault('possible_duplicates', []).append(first['_id'])
+    if pairs:
+        logger.warning(f"Found {len(pairs)} nearby places with near-identical thumbnails")
+    return pairs
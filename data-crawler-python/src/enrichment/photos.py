@@ -0,0 +1,63 @@
+"""
+Photo classification hook.
+Sends a place's photos to a pluggable classifier (food, interior, menu,
+exterior) and stores the tags, then picks a hero image from them.
+"""
+
+import logging
+from typing import Dict, List, Optional
+
+import requests
+
+logger = logging.getLogger(__name__)
+
+PHOTO_TAGS = ['food', 'interior', 'exterior', 'menu']
+
+# Preferred tags for the hero image, best first
+HERO_PREFERENCE = ['food', 'interior', 'exterior']
+
+class PhotoClassifier:
+    """Interface for photo classifiers."""
+
+    def classify(self, photo_url: str) -> Optional[str]:
+        """Return one of PHOTO_TAGS for the photo, or None if unsure."""
+        raise NotImplementedError
+
+class HttpPhotoClassifier(PhotoClassifier):
+    """Classifier backed by an HTTP endpoint that answers {"tag": "..."}."""
+
+    def __init__(self, url: str, timeout: float = 20):
+        self.url = url
+        self.timeout = timeout
+
+    def classify(self, photo_url: str) -> Optional[str]:
+        try:
+            response = requests.post(self.url, json={'url': photo_url}, timeout=self.timeout)
+            response.raise_for_status()
+            tag = response.json().get('tag')
+            return tag if tag in PHOTO_TAGS else None
+        except Exception as e:
+            logger.error(f"Photo classifier request failed for {photo_url}: {str(e)}")
+            return None
+
+def photo_url(photo) -> Optional[str]:
+    """Return the URL of a photo stored either as a string or a dict."""
+    return photo.get('url') if isinstance(photo, dict) else photo
+
+def tag_photos(restaurant: Dict, classifier: PhotoClassifier) -> List[Dict]:
+    """Classify the photos of a restaurant and choose its hero image."""
+    tags = []
+    for photo in restaurant.get('photos') or []:
+        url = photo_url(photo)
+        if url:
+            tags.append({'url': url, 'tag': classifier.classify(url)})
+    if not tags:
+        return tags
+
+    restaurant['photo_tags'] = tags
+    for preferred in HERO_PREFERENCE:
+        hero = next((t['url'] for t in tags if t['tag'] == preferred), None)
+        if hero:
+            restaurant['hero_photo'] = hero
+            break
+    return tags
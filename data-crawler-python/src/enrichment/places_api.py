@@ -0,0 +1,218 @@
+"""
+Google Places API.
+With an API key (CRAWLER_PLACES_API_KEY) the official Places API (New)
+either fills the fields the scraper missed on a place page (phone,
+website, opening hours and place_id), or replaces the scraper entirely
+for users who prefer compliance over cost (python -m src.places). Both
+map the API's places into the same restaurant documents the scraper
+produces, with source "places_api", so every sink and enrichment step
+works unchanged. Every request is billed by Google per field mask.
+"""
+
+import logging
+from typing import Dict, Iterator, List, Optional, Tuple
+
+import requests
+
+from src.catalog import normalize_name
+from .geo import haversine_m, lat_lng
+from .hours import parse_opening_hours
+from .status import CLOSED_NOW, OPEN, PERMANENTLY_CLOSED, TEMPORARILY_CLOSED, UNKNOWN
+from ..storage.idempotency import extract_cid
+
+logger = logging.getLogger(__name__)
+
+API_URL = 'https://places.googleapis.com/v1'
+FIELD_MASK = ','.join([
+    'id', 'displayName', 'formattedAddress', 'addressComponents', 'location', 'plusCode', 'googleMapsUri',
+    'nationalPhoneNumber', 'internationalPhoneNumber', 'websiteUri', 'regularOpeningHours', 'businessStatus',
+    'rating', 'userRatingCount', 'priceLevel', 'primaryTypeDisplayName',
+])
+# Text search returns at most 20 places per page and 60 per query
+PAGE_SIZE = 20
+MAX_BIAS_RADIUS_M = 50000
+# A looked-up place further than this from the scraped one is another branch
+MAX_MATCH_DISTANCE_M = 200
+PRICE_LEVELS = {
+    'PRICE_LEVEL_INEXPENSIVE': 1,
+    'PRICE_LEVEL_MODERATE': 2,
+    'PRICE_LEVEL_EXPENSIVE': 3,
+    'PRICE_LEVEL_VERY_EXPENSIVE': 4,
+}
+# Fields the enricher fills when the scraper left them empty
+FILL_FIELDS = ['phone', 'website', 'opening_hours', 'place_id']
+
+class PlacesApiClient:
+    """Text search and place details of the Places API (New)."""
+
+    def __init__(self, api_key: str, language: Optional[str] = None, timeout: float = 10):
+        self.api_key = api_key
+        self.language = language
+        self.timeout = timeout
+        self.session = requests.Session()
+        self.requests = 0
+
+    def __headers(self, field_mask: str) -> Dict[str, str]:
+        return {'X-Goog-Api-Key': self.api_key, 'X-Goog-FieldMask': field_mask}
+
+    def details(self, place_id: str) -> Dict:
+        """Return a place by its place_id."""
+        params = {'languageCode': self.language} if self.language else {}
+        response = self.session.get(f"{API_URL}/places/{place_id}", params=params,
+                                    headers=self.__headers(FIELD_MASK), timeout=self.timeout)
+        self.requests += 1
+        response.raise_for_status()
+        return response.json()
+
+    def text_search(self, query: str, max_results: int = PAGE_SIZE,
+                    center: Optional[Tuple[float, float]] = None, radius_m: Optional[float] = None) -> Iterator[Dict]:
+        """Yield up to `max_results` places matching a query, biased towards a circle if given."""
+        body = {'textQuery': query}
+        if self.language:
+            body['languageCode'] = self.language
+        if center:
+            body['locationBias'] = {'circle': {
+                'center': {'latitude': center[0], 'longitude': center[1]},
+                'radius': min(radius_m or MAX_BIAS_RADIUS_M, MAX_BIAS_RADIUS_M),
+            }}
+        found = 0
+        while found < max_results:
+            body['pageSize'] = min(PAGE_SIZE, max_results - found)
+            response = self.session.post(f"{API_URL}/places:searchText", json=body, timeout=self.timeout,
+                                         headers=self.__headers(','.join(
+                                             ['nextPageToken'] + [f"places.{f}" for f in FIELD_MASK.split(',')])))
+            self.requests += 1
+            response.raise_for_status()
+            data = response.json()
+            for place in data.get('places', [])[:max_results - found]:
+                found += 1
+                yield place
+            if not data.get('nextPageToken'):
+                return
+            body['pageToken'] = data['nextPageToken']
+
+def _address_component(place: Dict, kind: str, text: str = 'longText') -> Optional[str]:
+    for component in place.get('addressComponents') or []:
+        if kind in component.get('types', []):
+            return component.get(text)
+    return None
+
+def _status(place: Dict) -> Dict:
+    business_status = place.get('businessStatus')
+    if business_status == 'CLOSED_PERMANENTLY':
+        return {'status': PERMANENTLY_CLOSED, 'is_operational': False}
+    if business_status == 'CLOSED_TEMPORARILY':
+        return {'status': TEMPORARILY_CLOSED, 'is_operational': False}
+    open_now = (place.get('regularOpeningHours') or {}).get('openNow')
+    status = UNKNOWN if open_now is None else OPEN if open_now else CLOSED_NOW
+    return {'status': status, 'is_operational': True if business_status == 'OPERATIONAL' else None}
+
+def opening_hours_rows(place: Dict) -> Dict[str, str]:
+    """Return the place's hours as {day name: hours text}, like the scraper's opening_hours_raw."""
+    rows = {}
+    for description in (place.get('regularOpeningHours') or {}).get('weekdayDescriptions') or []:
+        day, _, text = description.partition(': ')
+        if text:
+            rows[day] = text
+    return rows
+
+def place_to_restaurant(place: Dict) -> Dict:
+    """Map a Places API place into a restaurant document; `_id` is left to the ID strategy."""
+    place_id = place.get('id')
+    url = place.get('googleMapsUri') or f"https://www.google.com/maps/place/?q=place_id:{place_id}"
+    restaurant = {
+        'url': url,
+        'name': (place.get('displayName') or {}).get('text'),
+        'location': {
+            'type': 'Point',
+            'coordinates': [],
+            'address': place.get('formattedAddress'),
+            'postal_code': _address_component(place, 'postal_code'),
+            'city': _address_component(place, 'locality'),
+            'state': _address_component(place, 'administrative_area_level_1', 'shortText'),
+            'country': _address_component(place, 'country'),
+        },
+        'place_id': place_id,
+        'cid': extract_cid(url),
+        'phone': place.get('nationalPhoneNumber') or place.get('internationalPhoneNumber'),
+        'website': place.get('websiteUri'),
+        'plus_code': (place.get('plusCode') or {}).get('globalCode'),
+        'overall_rating': place.get('rating'),
+        'total_reviews': place.get('userRatingCount'),
+        'review_count': place.get('userRatingCount'),
+        'attributes': {},
+        'opening_hours': [],
+        'photos': [],
+        'source': 'places_api',
+    }
+    location = place.get('location') or {}
+    if location.get('latitude') is not None and location.get('longitude') is not None:
+        restaurant['location']['coordinates'] = [location['longitude'], location['latitude']]
+    category = (place.get('primaryTypeDisplayName') or {}).get('text')
+    if category:
+        restaurant['attributes']['cuisine_type'] = [category]
+    if place.get('priceLevel') in PRICE_LEVELS:
+        restaurant['attributes']['price_level'] = PRICE_LEVELS[place['priceLevel']]
+    rows = opening_hours_rows(place)
+    if rows:
+        restaurant['opening_hours'] = parse_opening_hours(rows)
+        restaurant['opening_hours_raw'] = rows
+    restaurant.update(_status(place))
+    return restaurant
+
+class PlacesApiEnricher:
+    """Fills the fields a scraped place is missing from its Places API place."""
+
+    def __init__(self, client: PlacesApiClient):
+        self.client = client
+
+    def missing(self, restaurant: Dict) -> List[str]:
+        return [field for field in FILL_FIELDS if not restaurant.get(field)]
+
+    def lookup(self, restaurant: Dict) -> Optional[Dict]:
+        """Return the API place of a scraped place: by its place_id, else the best text search match."""
+        if restaurant.get('place_id'):
+            return self.client.details(restaurant['place_id'])
+        name = restaurant.get('name')
+        if not name:
+            return None
+        address = (restaurant.get('location') or {}).get('address')
+        coordinates = lat_lng(restaurant)
+        query = f"{name}, {address}" if address else name
+        for place in self.client.text_search(query, max_results=1, center=coordinates,
+                                             radius_m=MAX_MATCH_DISTANCE_M if coordinates else None):
+            if normalize_name((place.get('displayName') or {}).get('text') or '') != normalize_name(name):
+                continue
+            location = place.get('location') or {}
+            if coordinates and location.get('latitude') is not None and haversine_m(
+                    coordinates[0], coordinates[1], location['latitude'], location['longitude']) > MAX_MATCH_DISTANCE_M:
+                continue
+            return place
+        return None
+
+    def fill(self, restaurant: Dict) -> List[str]:
+        """Fill the place's missing fields in place and return the names of the filled ones."""
+        missing = self.missing(restaurant)
+        if not missing:
+            return []
+        try:
+            place = self.lookup(restaurant)
+        except Exception as e:
+            logger.error(f"Places API lookup failed for {restaurant.get('name')}: {str(e)}")
+            return []
+        if not place:
+            logger.info(f"No Places API match for {restaurant.get('name')}")
+            return []
+        found = place_to_restaurant(place)
+        filled = []
+        for field in missing:
+            if not found.get(field):
+                continue
+            restaurant[field] = found[field]
+            if field == 'opening_hours':
+                restaurant['opening_hours_raw'] = found['opening_hours_raw']
+            filled.append(field)
+        if filled:
+            restaurant.setdefault('enrichment', {})['places_api'] = {'place_id': found['place_id'], 'filled': filled}
+            logger.info(f"Filled {', '.join(filled)} of {restaurant.get('name')} from the Places API")
+        return filled
@@ -0,0 +1,59 @@
+"""
+Popular times summaries.
+Averages the stored popular times snapshots of a place hour by hour and
+derives the busiest day, the busiest hour and a weekly footfall index
+(mean busyness across the open hours of the week, 0-100), used for ranking
+and for "best time to visit" hints. Also parses the "Live" busyness of
+the current hour, e.g. "Currently 45% busy, usually 60% busy.".
+"""
+
+import re
+from typing import Dict, List, Optional
+
+LIVE_LABEL = re.compile(r'currently\s+(\d+)%\s+busy(?:,\s*usually\s+(\d+)%)?', re.IGNORECASE)
+
+def parse_live(label: str) -> Optional[Dict]:
+    """Parse the live bar's label into {'busyness': 45, 'usual': 60}; usual is None when not shown."""
+    match = LIVE_LABEL.search(label or '')
+    if not match:
+        return None
+    return {'busyness': int(match.group(1)), 'usual': int(match.group(2)) if match.group(2) else None}
+
+def hourly(histogram: Dict[str, List[Optional[int]]]) -> List[Dict]:
+    """Flatten a histogram into [{'day': 'Monday', 'hour': 12, 'busyness': 55}, ...], skipping unknown hours."""
+    return [{'day': day, 'hour': hour, 'busyness': value}
+            for day, hours in histogram.items() for hour, value in enumerate(hours[:24]) if value is not None]
+
+def average_histogram(snapshots: List[Dict]) -> Dict[str, List[Optional[float]]]:
+    """Average the hourly values of several {'histogram': {day: [24 values]}} snapshots."""
+    totals: Dict[str, List[List[int]]] = {}
+    for snapshot in snapshots:
+        for day, hours in (snapshot.get('histogram') or {}).items():
+            day_totals = totals.setdefault(day, [[] for _ in range(24)])
+            for hour, value in enumerate(hours[:24]):
+                if value is not None:
+                    day_totals[hour].append(value)
+    return {day: [sum(values) / len(values) if values else None for values in hours]
+            for day, hours in totals.items()}
+
+def summarize(snapshots: List[Dict]) -> Optional[Dict]:
+    """Return busiest day and hour, quietest open hour and footfall index over `snapshots`."""
+    histogram = average_histogram(snapshots)
+    hourly = [(day, hour, value) for day, hours in histogram.items()
+              for hour, value in enumerate(hours) if value is not None]
+    if not hourly:
+        return None
+
+    day_totals = {day: sum(v for v in hours if v is not None) for day, hours in histogram.items()}
+    busiest_day = max(day_totals, key=day_totals.get)
+    busiest = max(hourly, key=lambda item: item[2])
+    # Hours at 0% are usually closed hours, so the quietest hour is taken among busy ones
+    open_hours = [item for item in hourly if item[2] > 0]
+    quietest = min(open_hours, key=lambda item: item[2]) if open_hours else None
+    return {
+        'busiest_day': busiest_day,
+        'busiest_hour': {'day': busiest[0], 'hour': busiest[1], 'busyness': round(busiest[2])},
+        'quietest_hour': {'day': quietest[0], 'hour': quietest[1], 'busyness': round(quietest[2])} if quietest else None,
+        'footfall_index': round(sum(item[2] for item in open_hours) / len(open_hours), 1) if open_hours else 0,
+        'snapshots': len(snapshots),
+    }
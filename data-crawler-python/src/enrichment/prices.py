@@ -0,0 +1,88 @@
+"""
+Price range normalization.
+Maps shows price as locale-dependent text: "$$", "€10–20", "USD 20-30",
+"£50+" or "¥1,000–2,000". It is normalized into a 1-4 price level plus the
+per-person min/max and an ISO currency code when the text has amounts, so
+budgets can be filtered the same way in every city.
+"""
+
+import re
+from typing import Dict, Optional
+
+SYMBOLS = {'€': 'EUR', '£': 'GBP', '¥': 'JPY', '₩': 'KRW', '₹': 'INR', '฿': 'THB', '₫': 'VND', '₱': 'PHP'}
+DOLLAR_PREFIXES = {'A$': 'AUD', 'AU$': 'AUD', 'C$': 'CAD', 'CA$': 'CAD', 'NZ$': 'NZD', 'S$': 'SGD',
+                   'HK$': 'HKD', 'US$': 'USD', 'MX$': 'MXN', 'R$': 'BRL'}
+# Currency of a bare "$" by country
+DOLLAR_BY_COUNTRY = {'Australia': 'AUD', 'Canada': 'CAD', 'New Zealand': 'NZD', 'Singapore': 'SGD',
+                     'Hong Kong': 'HKD', 'Mexico': 'MXN'}
+# Rough units per US dollar, only used to bucket amounts into levels
+UNITS_PER_USD = {'USD': 1, 'EUR': 0.9, 'GBP': 0.8, 'AUD': 1.5, 'CAD': 1.35, 'NZD': 1.65, 'SGD': 1.35,
+                 'HKD': 7.8, 'MXN': 17, 'BRL': 5, 'JPY': 150, 'KRW': 1350, 'INR': 83, 'THB': 36,
+                 'VND': 25000, 'PHP': 56}
+# Upper bound in US dollars per person of levels 1-3; anything above is level 4
+LEVEL_LIMITS_USD = [15, 30, 60]
+AMOUNT = re.compile(r'\d[\d,.\s]*')
+
+def _currency(text: str, country: Optional[str]) -> Optional[str]:
+    code = re.search(r'\b([A-Z]{3})\b', text)
+    if code and code.group(1) in UNITS_PER_USD:
+        return code.group(1)
+    for prefix, currency in DOLLAR_PREFIXES.items():
+        if prefix in text:
+            return currency
+    for symbol, currency in SYMBOLS.items():
+        if symbol in text:
+            return currency
+    if '$' in text:
+        return DOLLAR_BY_COUNTRY.get(country or '', 'USD')
+    return None
+
+def parse_amount(text: str) -> Optional[float]:
+    """Parse an amount whatever its locale, e.g. "1,200", "1.000,50", "12,50" or "12.50".
+    A separator followed by 1-2 final digits is the decimal mark, any other is a thousands separator."""
+    digits = re.sub(r'[\s\u00a0\u202f]', '', text or '').strip('.,')
+    if not re.fullmatch(r'\d[\d.,]*', digits):
+        return None
+    decimal = re.search(r'[.,](\d{1,2})$', digits)
+    whole = digits[:decimal.start()] if decimal else digits
+    # "12.5000" is neither a grouped number nor a price
+    if re.search(r'[.,]', whole) and not re.fullmatch(r'\d{1,3}([.,]\d{3})+', whole):
+        return None
+    whole = re.sub(r'[.,]', '', whole)
+    return float(f"{whole}.{decimal.group(1)}" if decimal else whole)
+
+def level_for_amount(amount: float, currency: str) -> int:
+    """Return the 1-4 price level of a per-person amount."""
+    usd = amount / UNITS_PER_USD.get(currency, 1)
+    for level, limit in enumerate(LEVEL_LIMITS_USD, start=1):
+        if usd <= limit:
+            return level
+    return 4
+
+def normalize_price_range(text: Optional[str], country: Optional[str] = None) -> Optional[Dict]:
+    """Normalize a price text into {level, min, max, currency, raw}; amounts are None for "$$"-style text."""
+    raw = (text or '').replace('\u202f', ' ').replace('\xa0', ' ').strip()
+    if not raw:
+        return None
+    currency = _currency(raw, country)
+    amounts = [a for a in (parse_amount(m) for m in AMOUNT.findall(raw)) if a is not None]
+    if not amounts:
+        # "$$" or "€€€": the number of symbols is the level
+        symbols = re.fullmatch(r'([$€£¥₩₹])\1{0,3}', raw.replace(' ', ''))
+        if not symbols:
+            return None
+        return {'level': len(raw.replace(' ', '')), 'min': None, 'max': None, 'currency': currency, 'raw': raw}
+    if not currency:
+        return None
+
+    price_min = amounts[0]
+    price_max = amounts[1] if len(amounts) > 1 else None
+    # "£50+" is open-ended
+    if price_max is None and '+' not in raw:
+        price_max = price_min
+    level = level_for_amount(price_max if price_max is not None else price_min * 1.5, currency)
+    return {'level': level, 'min': price_min, 'max': price_max, 'currency': currency, 'raw': raw}
+
+def is_price_text(text: str) -> bool:
+    """Whether a category span holds a price rather than a cuisine."""
+    return bool(re.search(r'[$€£¥₩₹฿₫₱]', text) or re.match(r'^[A-Z]{3}\s*\d', text))
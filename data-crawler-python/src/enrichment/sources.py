@@ -0,0 +1,86 @@
+"""
+External enrichment sources.
+A source looks a place up in a third-party dataset and returns fields to
+attach to it. The first implementation matches places against municipal
+food-inspection open data (Socrata APIs) to attach hygiene scores.
+"""
+
+import logging
+import re
+from typing import Dict, Optional
+
+import requests
+
+from src.catalog import normalize_name
+
+logger = logging.getLogger(__name__)
+
+# Column names of the San Francisco restaurant scores dataset; other
+# cities publish the same data under different names
+DEFAULT_INSPECTION_FIELDS = {
+    'name': 'business_name',
+    'address': 'business_address',
+    'score': 'inspection_score',
+    'date': 'inspection_date',
+    'risk': 'risk_category',
+}
+
+class EnrichmentSource:
+    """Interface for enrichment sources."""
+
+    name = 'source'
+
+    def lookup(self, restaurant: Dict) -> Optional[Dict]:
+        """Return fields to attach to the restaurant, or None if it is not found."""
+        raise NotImplementedError
+
+def street_number(address: Optional[str]) -> Optional[str]:
+    """Return the leading street number of an address."""
+    match = re.match(r'\s*(\d+)', address or '')
+    return match.group(1) if match else None
+
+class FoodInspectionSource(EnrichmentSource):
+    """Hygiene scores from a Socrata food-inspection dataset, matched by name and address."""
+
+    name = 'food_inspection'
+
+    def __init__(self, url: str, fields: Optional[Dict[str, str]] = None, timeout: float = 10):
+        self.url = url
+        self.fields = {**DEFAULT_INSPECTION_FIELDS, **(fields or {})}
+        self.timeout = timeout
+
+    def lookup(self, restaurant: Dict) -> Optional[Dict]:
+        name = restaurant.get('name')
+        if not name:
+            return None
+        address = (restaurant.get('location') or {}).get('address')
+
+        try:
+            response = requests.get(
+                self.url,
+                params={'$q': name, '$limit': 50, '$order': f"{self.fields['date']} DESC"},
+                timeout=self.timeout
+            )
+            response.raise_for_status()
+            rows = response.json()
+        except Exception as e:
+            logger.error(f"Food inspection lookup failed for {name}: {str(e)}")
+            return None
+
+        wanted_name = normalize_name(name)
+        wanted_number = street_number(address)
+        for row in rows:
+            if normalize_name(row.get(self.fields['name'], '')) != wanted_name:
+                continue
+            # Chains share names, so the street number must agree when both are known
+            row_number = street_number(row.get(self.fields['address']))
+            if wanted_number and row_number and wanted_number != row_number:
+                continue
+            score = row.get(self.fields['score'])
+            return {
+                'score': float(score) if score not in (None, '') else None,
+                'date': row.get(self.fields['date']),
+                'risk': row.get(self.fields['risk']),
+                'source': self.url,
+            }
+        return None
@@ -0,0 +1,43 @@
+"""
+Business status of a place.
+The place header shows the status as text such as "Permanently closed",
+"Temporarily closed", "Open 24 hours", "Closes soon ⋅ 10 PM" or
+"Open ⋅ Closes 10 PM". It is normalized into one of the status values
+below plus is_operational, which is False only for closed businesses, so
+the app can filter them out. Closed-now places are still operational.
+"""
+
+import re
+from typing import Dict, Optional
+
+PERMANENTLY_CLOSED = 'permanently_closed'
+TEMPORARILY_CLOSED = 'temporarily_closed'
+OPEN_24_HOURS = 'open_24_hours'
+CLOSES_SOON = 'closes_soon'
+OPENS_SOON = 'opens_soon'
+OPEN = 'open'
+CLOSED_NOW = 'closed_now'
+UNKNOWN = 'unknown'
+
+# Checked in order, so "Closes soon" is not taken for "Closed" and "Open 24 hours" not for "Open"
+PATTERNS = [
+    (PERMANENTLY_CLOSED, re.compile(r'permanently closed|closed permanently', re.I)),
+    (TEMPORARILY_CLOSED, re.compile(r'temporarily closed|closed temporarily', re.I)),
+    (OPEN_24_HOURS, re.compile(r'open 24 hours|24 hours', re.I)),
+    (CLOSES_SOON, re.compile(r'closes soon|closing soon', re.I)),
+    (OPENS_SOON, re.compile(r'opens soon|opening soon', re.I)),
+    (CLOSED_NOW, re.compile(r'^\s*closed\b', re.I)),
+    (OPEN, re.compile(r'^\s*open\b', re.I)),
+]
+
+def normalize_status(raw: Optional[str]) -> Dict:
+    """Return {"status": ..., "is_operational": ...} of a raw status text."""
+    status = UNKNOWN
+    if raw:
+        # Maps uses narrow no-break spaces
+        text = raw.replace('\u202f', ' ').replace('\xa0', ' ')
+        for value, pattern in PATTERNS:
+            if pattern.search(text):
+                status = value
+                break
+    return {'status': status, 'is_operational': status not in (PERMANENTLY_CLOSED, TEMPORARILY_CLOSED)}
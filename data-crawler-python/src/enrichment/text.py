@@ -0,0 +1,12 @@
+"""
+Text helpers shared by the review mining modules.
+"""
+
+SNIPPET_RADIUS = 60
+
+def snippet(text: str, start: int, end: int, radius: int = SNIPPET_RADIUS) -> str:
+    """Return the text around a match, marking truncated ends."""
+    left = max(0, start - radius)
+    right = min(len(text), end + radius)
+    excerpt = text[left:right].strip()
+    return ('…' if left > 0 else '') + excerpt + ('…' if right < len(text) else '')
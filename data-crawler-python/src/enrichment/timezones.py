@@ -0,0 +1,47 @@
+"""
+Timezone resolution.
+Looks up the IANA timezone of a place from its coordinates using the
+boundary data bundled with timezonefinder, so no network is needed, and
+converts instants to the place's local weekday and minute for reading
+opening hours and popular times.
+"""
+
+import logging
+from datetime import datetime, timezone
+from typing import Dict, Optional, Tuple
+from zoneinfo import ZoneInfo
+
+from .geo import lat_lng
+
+logger = logging.getLogger(__name__)
+
+_finder = None
+
+def timezone_at(lat: float, lng: float) -> Optional[str]:
+    """Return the IANA timezone name at a point, e.g. "Australia/Sydney"."""
+    global _finder
+    if _finder is None:
+        from timezonefinder import TimezoneFinder
+        _finder = TimezoneFinder()
+    return _finder.timezone_at(lat=lat, lng=lng)
+
+def tag_timezone(restaurant: Dict) -> Optional[str]:
+    """Set `timezone` from the restaurant's coordinates unless already known, and return it."""
+    if restaurant.get('timezone'):
+        return restaurant['timezone']
+    point = lat_lng(restaurant)
+    if not point:
+        return None
+    try:
+        name = timezone_at(*point)
+    except Exception as e:
+        logger.error(f"Timezone lookup failed for {restaurant.get('name')}: {str(e)}")
+        return None
+    if name:
+        restaurant['timezone'] = name
+    return name
+
+def local_weekday_minute(tz_name: str, now: Optional[datetime] = None) -> Tuple[int, int]:
+    """Return the weekday (0=Monday) and minutes since midnight at `now` in the timezone."""
+    local = (now or datetime.now(timezone.utc)).astimezone(ZoneInfo(tz_name))
+    return local.weekday(), local.hour * 60 + local.minute
@@ -0,0 +1,213 @@
+"""
+Synthetic restaurant and review datasets for downstream development.
+Generates places and reviews with the same fields as crawled ones and
+roughly the distributions seen in real crawls (ratings skewed towards 4-5
+stars, a long tail of review counts, J-shaped review ratings), so app
+developers can work without running a crawl.
+
+Usage:
+    python -m src.fake -n 500 --city Sydney --seed 1
+"""
+
+import argparse
+import logging
+import random
+import re
+import sys
+from datetime import datetime, timezone
+from typing import Dict, List, Tuple
+
+from src.config.settings import settings
+from src.crawler.google_maps_crawler import WEEKDAYS
+from src.enrichment.hours import DAYS, parse_opening_hours
+from src.enrichment.timezones import tag_timezone
+from src.main import build_storage, configure_logging
+from src.storage.fanout import FanOutStorage
+from src.storage.jsonl_storage import JsonlStorage
+
+logger = logging.getLogger(__name__)
+
+# Center, spread in degrees, state, country, postal code range and street names by city
+CITIES = {
+    'san francisco': ((37.7749, -122.4194), 0.04, 'CA', 'United States', (94102, 94134),
+                      ['Mission St', 'Valencia St', 'Divisadero St', 'Geary Blvd', 'Irving St', 'Columbus Ave']),
+    'new york': ((40.7306, -73.9866), 0.05, 'NY', 'United States', (10001, 10040),
+                 ['Broadway', '2nd Ave', 'Bleecker St', 'Houston St', 'Lexington Ave', 'Canal St']),
+    'sydney': ((-33.8688, 151.2093), 0.06, 'NSW', 'Australia', (2000, 2050),
+               ['George St', 'Crown St', 'King St', 'Oxford St', 'Glebe Point Rd', 'Military Rd']),
+    'melbourne': ((-37.8136, 144.9631), 0.06, 'VIC', 'Australia', (3000, 3070),
+                  ['Lygon St', 'Smith St', 'Chapel St', 'Brunswick St', 'Flinders Ln', 'Sydney Rd']),
+    'london': ((51.5072, -0.1276), 0.06, 'England', 'United Kingdom', None,
+               ['Brick Ln', 'Upper St', 'Dean St', 'Exmouth Market', 'Kingsland Rd', 'Borough High St']),
+}
+CUISINES = ['Italian', 'Japanese', 'Chinese', 'Mexican', 'Thai', 'Vietnamese', 'Indian', 'Korean', 'French',
+            'Greek', 'Lebanese', 'American', 'Seafood', 'Pizza', 'Cafe', 'Vegan', 'Burger', 'Ramen', 'Tapas']
+NAME_PATTERNS = ['{word} {cuisine}', 'The {word} Kitchen', '{word} & {word2}', "{person}'s", '{word} House',
+                 'Little {word}', '{cuisine} {word}', 'Bar {word}']
+WORDS = ['Golden', 'Olive', 'Lotus', 'Harbour', 'Copper', 'Saffron', 'Juniper', 'Ember', 'Maple', 'Bamboo',
+         'Salt', 'Fig', 'Pepper', 'Sunset', 'Willow', 'Cedar', 'Basil', 'Coral']
+PEOPLE = ['Rosa', 'Marco', 'Ling', 'Priya', 'Jules', 'Kenji', 'Amir', 'Sofia', 'Nadia', 'Tom']
+REVIEWER_FIRST = ['Alex', 'Sam', 'Jordan', 'Taylor', 'Chris', 'Mei', 'Ravi', 'Ana', 'Lucas', 'Emma', 'Noah', 'Yuki']
+REVIEWER_LAST = ['S.', 'Nguyen', 'Smith', 'Garcia', 'Chen', 'K.', 'Patel', 'Brown', 'Rossi', 'M.']
+# Share of each star rating among individual reviews
+REVIEW_RATING_WEIGHTS = {5: 0.56, 4: 0.2, 3: 0.08, 2: 0.05, 1: 0.11}
+PRICE_LEVEL_WEIGHTS = {1: 0.3, 2: 0.45, 3: 0.18, 4: 0.07}
+RELATIVE_DATES = ['a day ago', '3 days ago', 'a week ago', '2 weeks ago', '3 weeks ago', 'a month ago',
+                  '2 months ago', '4 months ago', '6 months ago', '9 months ago', 'a year ago', '2 years ago']
+REVIEW_OPENERS = {
+    5: ['Absolutely loved it.', 'One of the best meals I have had in a while.', 'Fantastic spot.'],
+    4: ['Really good food.', 'Solid choice for dinner.', 'Nice place, would come back.'],
+    3: ['It was okay.', 'Decent but nothing special.', 'Mixed experience.'],
+    2: ['Pretty disappointing.', 'Expected more.', 'Not great this time.'],
+    1: ['Terrible experience.', 'Would not recommend.', 'Avoid.'],
+}
+REVIEW_DETAILS = ['The {dish} was {adjective}.', 'Service was {service}.', 'We waited {wait} for a table.',
+                  'Portions were {portion}.', 'Prices are {price} for the area.']
+DISHES = ['pasta', 'ramen', 'tacos', 'curry', 'dumplings', 'steak', 'salad', 'pizza', 'pho', 'fish']
+ADJECTIVES = {'good': ['delicious', 'perfectly cooked', 'full of flavour'], 'bad': ['bland', 'cold', 'overcooked']}
+
+def _weighted(rng: random.Random, weights: Dict) -> int:
+    return rng.choices(list(weights), weights=list(weights.values()))[0]
+
+def _city(name: str) -> Tuple:
+    key = name.strip().lower()
+    if key not in CITIES:
+        raise ValueError(f"Unknown city '{name}', expected one of: {', '.join(sorted(CITIES))}")
+    return CITIES[key]
+
+def _restaurant_name(rng: random.Random, cuisine: str) -> str:
+    pattern = rng.choice(NAME_PATTERNS)
+    word, word2 = rng.sample(WORDS, 2)
+    return pattern.format(word=word, word2=word2, cuisine=cuisine, person=rng.choice(PEOPLE))
+
+def _opening_hours(rng: random.Random) -> Dict[str, str]:
+    text = rng.choice(['7 AM–3 PM', '11 AM–10 PM', '11:30 AM–2:30 PM, 5:30–10 PM', '5 PM–12 AM', '6 PM–2 AM'])
+    closed_day = rng.choice([None, 'Monday', 'Tuesday'])
+    return {day: 'Closed' if day == closed_day else text for day in DAYS}
+
+def _popular_times(rng: random.Random) -> Dict:
+    histogram = {}
+    for day in WEEKDAYS:
+        weekend = day in ('Friday', 'Saturday', 'Sunday')
+        histogram[day] = {
+            str(hour): max(0, min(100, int(rng.gauss(70 if weekend else 50, 15) *
+                                           (1.0 if hour in (12, 13, 18, 19, 20) else 0.5))))
+            for hour in range(11, 23)
+        }
+    return {'captured_at': datetime.now(timezone.utc).isoformat(), 'histogram': histogram}
+
+def fake_review(rng: random.Random, restaurant: Dict) -> Dict:
+    """Generate one review of `restaurant`."""
+    rating = _weighted(rng, REVIEW_RATING_WEIGHTS)
+    tone = 'good' if rating >= 4 else 'bad'
+    details = [d.format(dish=rng.choice(DISHES), adjective=rng.choice(ADJECTIVES[tone]),
+                        service='friendly and quick' if tone == 'good' else 'slow',
+                        wait=rng.choice(['no time', '10 minutes', 'half an hour']),
+                        portion='generous' if tone == 'good' else 'small',
+                        price='fair' if tone == 'good' else 'steep')
+               for d in rng.sample(REVIEW_DETAILS, rng.randint(1, 3))]
+    review_id = f"fake{rng.getrandbits(48):012x}"
+    review = {
+        '_id': f"{restaurant['_id']}_review_{review_id}",
+        'review_id': review_id,
+        'restaurant_id': restaurant['_id'],
+        'text': ' '.join([rng.choice(REVIEW_OPENERS[rating])] + details),
+        'date': rng.choice(RELATIVE_DATES),
+        'rating': float(rating),
+        'reviewer': {
+            'name': f"{rng.choice(REVIEWER_FIRST)} {rng.choice(REVIEWER_LAST)}",
+            'review_count': int(rng.lognormvariate(2.5, 1.2)),
+            'photo_count': int(rng.lognormvariate(1.5, 1.5)),
+            'url': f"https://www.google.com/maps/contrib/{rng.getrandbits(64)}",
+        },
+        'language': 'en',
+    }
+    review['id_review'] = review['_id']
+    # Owners answer complaints more often than praise
+    if rng.random() < (0.35 if rating <= 2 else 0.1):
+        review['owner_response'] = {'text': 'Thank you for your feedback, we hope to see you again.',
+                                    'date': rng.choice(RELATIVE_DATES[:6])}
+    return review
+
+def fake_place(rng: random.Random, city: str) -> Tuple[Dict, List[Dict]]:
+    """Generate one place in `city` and its reviews."""
+    (lat, lng), spread, state, country, postal_range, streets = _city(city)
+    cuisines = rng.sample(CUISINES, rng.choice([1, 1, 2]))
+    name = _restaurant_name(rng, cuisines[0])
+    hours = _opening_hours(rng)
+    point = (round(lat + rng.gauss(0, spread), 7), round(lng + rng.gauss(0, spread), 7))
+    postal_code = str(rng.randint(*postal_range)) if postal_range else None
+    address = f"{rng.randint(1, 999)} {rng.choice(streets)}, {city.title()}, {state} {postal_code or ''}".strip()
+    cid = rng.getrandbits(63)
+    slug = re.sub(r'[^A-Za-z0-9]+', '+', name)
+    url = f"https://www.google.com/maps/place/{slug}/data=!4m7!3m6!1s0x0:{cid:#x}!8m2!3d{point[0]}!4d{point[1]}"
+
+    restaurant = {
+        '_id': f"cid_{cid}",
+        'url': url,
+        'name': name,
+        'location': {
+            'type': 'Point',
+            'coordinates': [point[1], point[0]],
+            'address': f"{address}, {country}",
+            'postal_code': postal_code,
+            'city': city.title(),
+            'state': state,
+            'country': country,
+        },
+        'phone': f"+{rng.randint(1, 99)} {rng.randint(100, 999)} {rng.randint(100, 999)} {rng.randint(1000, 9999)}",
+        'attributes': {'cuisine_type': cuisines, 'price_level': _weighted(rng, PRICE_LEVEL_WEIGHTS)},
+        'opening_hours_raw': hours,
+        'opening_hours': parse_opening_hours(hours),
+        'photos': [],
+        'review_count': max(1, int(rng.lognormvariate(5, 1.3))),
+        'fake': True,
+    }
+    tag_timezone(restaurant)
+    if rng.random() < 0.7:
+        restaurant['website'] = f"https://www.{re.sub(r'[^a-z0-9]', '', name.lower())}.example"
+    if rng.random() < 0.6:
+        restaurant['popular_times'] = _popular_times(rng)
+
+    reviews = [fake_review(rng, restaurant) for _ in range(min(restaurant['review_count'], rng.randint(3, 40)))]
+    ratings = [review['rating'] for review in reviews]
+    restaurant['overall_rating'] = round(sum(ratings) / len(ratings), 1)
+    restaurant['total_reviews'] = len(ratings)
+    return restaurant, reviews
+
+def generate(storage, count: int, city: str, seed: int = None) -> int:
+    """Write `count` fake places and their reviews to `storage`; returns the number of reviews."""
+    _city(city)
+    rng = random.Random(seed)
+    total_reviews = 0
+    for _ in range(count):
+        restaurant, reviews = fake_place(rng, city)
+        storage.upsert_restaurant(restaurant)
+        storage.upsert_reviews(restaurant['_id'], reviews)
+        total_reviews += len(reviews)
+    return total_reviews
+
+def main():
+    configure_logging()
+    parser = argparse.ArgumentParser(description='Generate a synthetic restaurant and review dataset.')
+    parser.add_argument('-n', type=int, default=100, help='Number of places')
+    parser.add_argument('--city', default='San Francisco', help=f"One of: {', '.join(sorted(CITIES))}")
+    parser.add_argument('--seed', type=int, help='Random seed for a reproducible dataset')
+    parser.add_argument('--output', help='Directory for the JSONL files (default: <output dir>/fake)')
+    parser.add_argument('--to-sinks', action='store_true', help='Write to CRAWLER_SINKS instead of JSONL files')
+    args = parser.parse_args()
+
+    try:
+        if args.to_sinks:
+            storage = build_storage()
+        else:
+            storage = FanOutStorage({'jsonl': JsonlStorage(base_dir=args.output or f"{settings.output_dir}/fake")})
+        reviews = generate(storage, args.n, args.city, args.seed)
+        storage.close()
+        logger.info(f"Generated {args.n} places and {reviews} reviews in {args.city}")
+    except Exception as e:
+        logger.error(f"Fake data generation failed: {str(e)}")
+        sys.exit(1)
+
+if __name__ == "__main__":
+    main()
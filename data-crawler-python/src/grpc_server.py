@@ -0,0 +1,167 @@
+"""
+gRPC streaming API of the crawler.
+Serves the Crawler service of proto/crawler.proto next to the REST daemon:
+StreamSearch searches Maps and streams each place, with its reviews, as
+soon as it has been scraped and saved to the configured sinks, so other
+smart-dine services get typed results without polling. The proto file is
+loaded at runtime, which needs grpcio-tools installed.
+
+Usage:
+    python -m src.grpc_server [--port 50051] [--concurrency 2]
+"""
+
+import argparse
+import logging
+import os
+import queue
+import sys
+import threading
+from concurrent import futures
+from typing import Dict, List
+
+import grpc
+
+from src.config.settings import settings
+from src.crawler.shutdown import ShutdownSignal
+from src.embed import EmbeddedCrawler
+from src.main import configure_logging, process_restaurant
+from src.serve import job_search_url
+
+logger = logging.getLogger(__name__)
+
+PROTO_DIR = os.path.join(os.path.dirname(os.path.dirname(os.path.abspath(__file__))), 'proto')
+sys.path.append(PROTO_DIR)
+crawler_pb2, crawler_pb2_grpc = grpc.protos_and_services('crawler.proto')
+
+_DONE = object()
+
+def to_review(review: Dict):
+    reviewer = review.get('reviewer') or {}
+    owner_response = review.get('owner_response')
+    return crawler_pb2.Review(
+        id=review.get('_id') or '',
+        review_id=review.get('review_id') or '',
+        text=review.get('text') or '',
+        date=review.get('date') or '',
+        rating=review.get('rating') or 0,
+        reviewer_name=reviewer.get('name') or '',
+        reviewer_review_count=reviewer.get('total_reviews') or 0,
+        language=review.get('language') or '',
+        photos=[photo if isinstance(photo, str) else photo.get('url', '') for photo in review.get('photos') or []],
+        owner_response=crawler_pb2.OwnerResponse(
+            text=owner_response.get('text') or '', date=owner_response.get('date') or ''
+        ) if owner_response else None,
+    )
+
+def to_place(restaurant: Dict, reviews: List[Dict]):
+    """Convert a saved restaurant and its reviews into a Place message."""
+    location = restaurant.get('location') or {}
+    coordinates = location.get('coordinates') or [0, 0]
+    attributes = restaurant.get('attributes') or {}
+    return crawler_pb2.Place(
+        id=restaurant.get('_id') or '',
+        cid=restaurant.get('cid') or '',
+        place_id=restaurant.get('place_id') or '',
+        url=restaurant.get('url') or '',
+        name=restaurant.get('name') or '',
+        location=crawler_pb2.Location(
+            address=location.get('address') or '',
+            postal_code=location.get('postal_code') or '',
+            city=location.get('city') or '',
+            state=location.get('state') or '',
+            country=location.get('country') or '',
+            lng=coordinates[0],
+            lat=coordinates[1],
+            plus_code=restaurant.get('plus_code') or '',
+            timezone=restaurant.get('timezone') or '',
+        ),
+        phone=restaurant.get('phone') or '',
+        website=restaurant.get('website') or '',
+        rating=restaurant.get('overall_rating') or 0,
+        review_count=restaurant.get('review_count') or restaurant.get('total_reviews') or 0,
+        categories=attributes.get('cuisine_type') or [],
+        price_level=attributes.get('price_level') or 0,
+        thumbnail=restaurant.get('thumbnail') or '',
+        reviews=[to_review(review) for review in reviews],
+    )
+
+class CrawlerService(crawler_pb2_grpc.CrawlerServicer):
+    """Runs StreamSearch calls on the embedded crawler's browsers and scheduler."""
+
+    def __init__(self, crawler: EmbeddedCrawler):
+        self.crawler = crawler
+
+    def StreamSearch(self, request, context):
+        if not request.query.strip():
+            context.abort(grpc.StatusCode.INVALID_ARGUMENT, "query is required")
+        spec = {
+            'query': request.query,
+            'lat': request.lat if request.HasField('lat') else None,
+            'lng': request.lng if request.HasField('lng') else None,
+            'zoom': request.zoom or None,
+        }
+        max_results = request.max_results or settings.max_restaurants
+        places: queue.Queue = queue.Queue()
+
+        def scrape(url: str):
+            # Nothing to do once the client has gone away
+            if not context.is_active():
+                return
+            with self.crawler.pool.browser() as scraper:
+                process_restaurant(scraper, self.crawler.storage, url, media=self.crawler.media,
+                                   website=self.crawler.website,
+                                   on_saved=lambda restaurant, reviews: places.put(to_place(restaurant, reviews)))
+
+        def search():
+            jobs = []
+            try:
+                with self.crawler.pool.browser() as scraper:
+                    for card in scraper.iter_search_cards(job_search_url(spec), max_results):
+                        if not context.is_active():
+                            break
+                        # Share the card so the detail pass can reconcile against it
+                        self.crawler.pool.cards[card['cid'] or card['url']] = card
+                        jobs.append(self.crawler.scheduler.submit(scrape, card['url']))
+            except Exception as e:
+                logger.error(f"StreamSearch for '{request.query}' failed: {str(e)}")
+                places.put(e)
+            finally:
+                futures.wait([job for job in jobs if job])
+                places.put(_DONE)
+
+        threading.Thread(target=search, name='grpc-search', daemon=True).start()
+        while True:
+            place = places.get()
+            if place is _DONE:
+                return
+            if isinstance(place, Exception):
+                context.abort(grpc.StatusCode.UNAVAILABLE, str(place))
+            yield place
+
+def main():
+    configure_logging()
+    parser = argparse.ArgumentParser(description='Run the crawler gRPC service.')
+    parser.add_argument('--port', type=int, default=50051, help='Port to listen on')
+    parser.add_argument('--concurrency', type=int, default=settings.concurrency, help='Places processed at once')
+    args = parser.parse_args()
+
+    # One browser searches while the others scrape places
+    crawler = EmbeddedCrawler(concurrency=args.concurrency, browsers=max(settings.max_browsers, args.concurrency + 1))
+    try:
+        crawler.start()
+        server = grpc.server(futures.ThreadPoolExecutor(max_workers=8))
+        crawler_pb2_grpc.add_CrawlerServicer_to_server(CrawlerService(crawler), server)
+        server.add_insecure_port(f'[::]:{args.port}')
+        server.start()
+        logger.info(f"Serving the crawler gRPC API on port {args.port}")
+        with ShutdownSignal() as shutdown:
+            shutdown.on_stop(lambda: server.stop(grace=settings.shutdown_grace))
+            server.wait_for_termination()
+    except Exception as e:
+        logger.error(f"Crawler gRPC service failed: {str(e)}")
+        sys.exit(1)
+    finally:
+        crawler.stop()
+
+if __name__ == "__main__":
+    main()
@@ -3,85 +3,558 @@ Main script for running the Google Maps crawler.
 """
 
 import logging
+import os
 import sys
-from typing import Dict
+from contextlib import ExitStack
+from typing import Callable, Dict, List, Optional, Tuple
 
+from src.crawler.anomaly import ResultCountHistory
+from src.crawler.artifacts import prune_failures
+from src.crawler.blocking import build_block_handler
+from src.crawler.browser_pool import BrowserPool
+from src.crawler.canary import format_report, run_canary
+from src.crawler.card_filter import CardFilter
+from src.crawler.checkpoint import CrawlCheckpoint
+from src.crawler.consent import ConsentHandler
+from src.crawler.costs import RunCosts
+from src.crawler.errors import classify
+from src.crawler.fields import FieldSelection
 from src.crawler.google_maps_crawler import GoogleMapsScraper
+from src.crawler.identified import POLITE_CONCURRENCY, POLITE_MIN_DELAY, CrawlerIdentity, polite_rate_limits
+from src.crawler.locale import CrawlLocale
+from src.crawler.manifest import RunManifest
+from src.crawler.grid import bbox_around, grid_cells, iter_grid_cards, parse_bbox
+from src.crawler.place_job import scrape_menu, scrape_place
+from src.crawler.progress import ProgressReporter
+from src.crawler.proxy_pool import ProxyPool
+from src.crawler.rephrase import DEFAULT_PHRASINGS, iter_cards_with_rephrasing, parse_phrasings, search_area
+from src.crawler.scheduler import Scheduler, parse_rate_limits
+from src.crawler.shutdown import ShutdownSignal
+from src.crawler.selectors import SelectorRegistry
+from src.crawler.streaming import stream_cards_to_details, stream_search_to_details
+from src.crawler.throttle import AdaptiveThrottle
+from src.crawler.tracing import setup_tracing, shutdown_tracing, span
+from src.crawler.webhooks import JOB_COMPLETED, JOB_FAILED, ErrorSummary, WebhookNotifier
+from src.crawler.website import DEFAULT_USER_AGENT, WebsiteCrawler
 from src.database.mongodb import MongoDBClient
+from src.enrichment.menu_prices import menu_price_stats
+from src.enrichment.places_api import PlacesApiClient, PlacesApiEnricher
+from src.enrichment.popular_times import summarize as summarize_popular_times
+from src.database.raw_documents import RawDocumentStorage
+from src.storage.csv_storage import CsvStorage
+from src.storage.dedupe import DedupeIndex
+from src.storage.diff_storage import DiffStorage
+from src.storage.fanout import FanOutStorage
+from src.storage.file_storage import FileStorage
+from src.storage.geojson_storage import GeoJsonStorage
+from src.storage.ids import build_id_strategy
+from src.storage.jsonl_storage import JsonlStorage
+from src.storage.kafka_storage import KafkaStorage
+from src.storage.media import LocalMediaStore, MediaDownloader, S3MediaStore
+from src.storage.parquet_storage import ParquetStorage
+from src.storage.postgres_storage import PostgresStorage
+from src.storage.redaction import RedactionPolicy
+from src.storage.transform import Transform
 from src.config.settings import settings
-
-# Configure logging
-logging.basicConfig(
-    level=logging.INFO,
-    format='%(asctime)s - %(levelname)s - %(message)s',
-    handlers=[logging.StreamHandler(sys.stdout)]
-)
+from src.models.job_context import JobContext
 
 logger = logging.getLogger(__name__)
 
-def process_restaurant(scraper: GoogleMapsScraper, mongodb_client: MongoDBClient, url: str):
-    """Process a single restaurant."""
-    try:
-        logger.info(f"Processing restaurant URL: {url}")
+def configure_logging():
+    """Log to stdout; called by the command line entry points, never on import, so embedders keep their own setup."""
+    logging.basicConfig(
+        level=logging.INFO,
+        format='%(asctime)s - %(levelname)s - %(message)s',
+        handlers=[logging.StreamHandler(sys.stdout)]
+    )
+
+def process_restaurant(scraper: GoogleMapsScraper, storage: FanOutStorage, url: str,
+                       review_scraper: Optional[GoogleMapsScraper] = None,
+                       media: Optional[MediaDownloader] = None,
+                       website: Optional[WebsiteCrawler] = None,
+                       places: Optional[PlacesApiEnricher] = None,
+                       on_saved: Optional[Callable[[Dict, List[Dict]], None]] = None) -> bool:
+    """Process a single restaurant, scraping the reviews pane in parallel when a review scraper is given.
+    With CRAWLER_INCREMENTAL_REVIEWS only reviews newer than the stored ones are fetched.
+    Photos are downloaded into the media store when a downloader is given.
+    With CRAWLER_SCRAPE_MENUS the Menu tab and its price statistics are scraped as well,
+    and the restaurant's own website is crawled when a website crawler is given. Fields
+    the page did not show are filled from the Places API when an enricher is given.
+    `on_saved` is called with the saved restaurant and its reviews.
+    Only the fields selected by `scraper.fields` are scraped.
+    Returns True once the restaurant has been saved; otherwise the classified
+    failure, if any, is left in `scraper.last_error`."""
+    prefix = scraper.job.log_prefix()
+    fields = scraper.fields
+    with span('place_job', place_url=url, job_id=scraper.job.job_id):
+        try:
+            logger.info(f"{prefix} Processing restaurant URL: {url}")
         
-        # Get restaurant data
-        result = scraper.get_account(url)
-        if not result:
-            logger.error(f"Failed to get data for URL: {url}")
-            return
+            # In incremental mode only reviews newer than the stored ones are fetched
+            wants_reviews = fields.wants('reviews')
+            known_review_ids = storage.known_review_ids(url) if settings.incremental_reviews and wants_reviews else None
+            if known_review_ids:
+                logger.info(f"{prefix} {len(known_review_ids)} reviews already stored, fetching newer ones only")
+
+            # Get restaurant data
+            if wants_reviews and (review_scraper or known_review_ids):
+                result = scrape_place(scraper, review_scraper or scraper, url, known_review_ids)
+            else:
+                result = scraper.get_account(url)
+            if not result:
+                logger.error(f"{prefix} Failed to get data for URL: {url}")
+                return False
+            if result.get('error'):
+                logger.error(f"{prefix} Failed to get data for URL: {url} ({result['error']['type']})")
+                return False
             
-        restaurant_data = result.get('restaurant')
-        reviews_data = result.get('reviews', [])
+            restaurant_data = result.get('restaurant')
+            reviews_data = result.get('reviews', [])
         
-        if not restaurant_data:
-            logger.error(f"No restaurant data found for URL: {url}")
-            return
+            if not restaurant_data:
+                logger.error(f"{prefix} No restaurant data found for URL: {url}")
+                return False
             
-        logger.info(f"Saving restaurant: {restaurant_data.get('name')}")
+            if settings.scrape_menus and fields.wants('menu') and scrape_menu(scraper, restaurant_data, url):
+                menu_prices = menu_price_stats(restaurant_data['menu'])
+                if menu_prices:
+                    restaurant_data['menu_prices'] = menu_prices
+            if website and fields.wants('website') and restaurant_data.get('website'):
+                site_info = website.crawl(restaurant_data['website'])
+                if site_info:
+                    restaurant_data['website_info'] = site_info
+            if places:
+                places.fill(restaurant_data)
+
+            # Summarize popular times over every stored snapshot plus this one
+            if restaurant_data.get('popular_times'):
+                history = storage.popular_times_history(url)
+                restaurant_data['popular_times_summary'] = summarize_popular_times(history + [restaurant_data['popular_times']])
+
+            if media:
+                media.fetch(restaurant_data, reviews_data)
+
+            # Record how much of the listed review count was actually captured
+            listed = restaurant_data.get('review_count')
+            restaurant_data['review_coverage'] = {'captured': len(reviews_data), 'listed': listed}
+            if listed and len(reviews_data) < listed:
+                logger.info(f"{prefix} Captured {len(reviews_data)} of {listed} listed reviews")
+            logger.info(f"{prefix} Saving restaurant: {restaurant_data.get('name')}")
         
-        # Save restaurant data
-        result = mongodb_client.upsert_restaurant(restaurant_data)
-        if not result:
-            logger.error(f"Failed to save restaurant data for URL: {url}")
-            return
-            
-        # Save reviews if any
-        if reviews_data:
-            logger.info(f"Saving {len(reviews_data)} reviews")
-            mongodb_client.upsert_reviews(restaurant_data['_id'], reviews_data)
+            # Save restaurant data
+            with span('sink_write', sinks=','.join(storage.sinks), reviews=len(reviews_data)):
+                result = storage.upsert_restaurant(restaurant_data)
+                if not any(result.values()):
+                    logger.error(f"{prefix} Failed to save restaurant data for URL: {url}")
+                    return False
+
+                # Save reviews if any
+                if reviews_data:
+                    logger.info(f"{prefix} Saving {len(reviews_data)} reviews")
+                    storage.upsert_reviews(restaurant_data['_id'], reviews_data)
+            if on_saved:
+                on_saved(restaurant_data, reviews_data)
+            return True
         
-    except Exception as e:
-        logger.error(f"Error processing restaurant {url}: {str(e)}")
+        except Exception as e:
+            scraper.last_error = classify(e, url)
+            logger.error(f"{prefix} Error processing restaurant {url}: {str(e)}")
+            scraper.capture_failure(url, scraper.last_error.to_dict())
+            return False
+
+def apply_retention(storage: FanOutStorage):
+    """Prune the file sink's output and the failure artifacts with CRAWLER_KEEP_DAYS and CRAWLER_KEEP_RUNS."""
+    if settings.keep_days is None and settings.keep_runs is None:
+        return
+    file_sink = storage.sinks.get('file')
+    if file_sink:
+        file_sink.prune(keep_days=settings.keep_days, keep_runs=settings.keep_runs)
+    if settings.failure_dir:
+        prune_failures(settings.failure_dir, keep_days=settings.keep_days, keep_runs=settings.keep_runs)
+
+def build_storage() -> FanOutStorage:
+    """Create the configured storage sinks."""
+    sinks = {}
+    for name in settings.sinks:
+        if name == 'mongodb':
+            mongodb = MongoDBClient(
+                mongodb_url=settings.MONGODB_URL,
+                db_name=settings.MONGODB_DB,
+                collection_restaurants=settings.MONGODB_COLLECTION_RESTAURANTS,
+                collection_reviews=settings.MONGODB_COLLECTION_REVIEWS
+            )
+            mongodb.create_indexes()
+            sinks[name] = mongodb
+        elif name == 'mongodb_raw':
+            sinks[name] = RawDocumentStorage(
+                mongodb_url=settings.MONGODB_URL,
+                db_name=settings.MONGODB_DB,
+                collection=settings.MONGODB_COLLECTION_RAW,
+                ttl_seconds=settings.raw_ttl_seconds
+            )
+        elif name == 'file':
+            sinks[name] = FileStorage(base_dir=settings.output_dir)
+        elif name == 'jsonl':
+            sinks[name] = JsonlStorage(base_dir=settings.output_dir)
+        elif name == 'csv':
+            sinks[name] = CsvStorage(base_dir=settings.output_dir)
+        elif name == 'geojson':
+            sinks[name] = GeoJsonStorage(base_dir=settings.output_dir)
+        elif name == 'diff':
+            sinks[name] = DiffStorage(base_dir=settings.output_dir)
+        elif name == 'parquet':
+            sinks[name] = ParquetStorage(base_dir=settings.output_dir)
+        elif name == 'postgres':
+            sinks[name] = PostgresStorage(settings.postgres_dsn)
+        elif name == 'kafka':
+            sinks[name] = KafkaStorage(
+                bootstrap_servers=settings.kafka_bootstrap_servers,
+                places_topic=settings.kafka_places_topic,
+                reviews_topic=settings.kafka_reviews_topic
+            )
+        else:
+            raise ValueError(f"Unknown sink: {name}")
+
+    transform = Transform.from_file(settings.transform_config) if settings.transform_config else None
+    if settings.redaction_config:
+        redaction = RedactionPolicy.from_file(settings.redaction_config, tenant=settings.tenant,
+                                              extra_fields=settings.redact_fields)
+    else:
+        redaction = RedactionPolicy(fields=settings.redact_fields) if settings.redact_fields else None
+    dedupe = None
+    if settings.dedupe:
+        dedupe = DedupeIndex(settings.dedupe_index or os.path.join(settings.output_dir, 'places_index.json'))
+    return FanOutStorage(sinks, transform=transform, redaction=redaction, dedupe=dedupe)
+
+def build_media_downloader() -> Optional[MediaDownloader]:
+    """Create the photo downloader from CRAWLER_MEDIA_S3_BUCKET or CRAWLER_MEDIA_DIR, if configured."""
+    if settings.media_s3_bucket:
+        store = S3MediaStore(settings.media_s3_bucket, prefix=settings.media_s3_prefix)
+    elif settings.media_dir:
+        store = LocalMediaStore(settings.media_dir)
+    else:
+        return None
+    return MediaDownloader(store, workers=settings.media_workers)
+
+def build_website_crawler() -> Optional[WebsiteCrawler]:
+    """Create the restaurant website crawler when CRAWLER_CRAWL_WEBSITES is on."""
+    if not settings.crawl_websites:
+        return None
+    identity = build_identity()
+    return WebsiteCrawler(
+        rate_per_host=settings.website_requests_per_minute / 60,
+        max_pages=settings.website_max_pages,
+        user_agent=identity.user_agent(DEFAULT_USER_AGENT) if identity else DEFAULT_USER_AGENT
+    )
+
+def build_locale() -> Optional[CrawlLocale]:
+    """Create the crawl locale from CRAWLER_HL and CRAWLER_GL, if either is set."""
+    if not settings.hl and not settings.gl:
+        return None
+    return CrawlLocale(hl=settings.hl or 'en', gl=settings.gl)
+
+def build_places_enricher() -> Optional[PlacesApiEnricher]:
+    """Create the Places API enricher from CRAWLER_PLACES_API_KEY, if set."""
+    if not settings.places_api_key:
+        return None
+    return PlacesApiEnricher(PlacesApiClient(settings.places_api_key, language=settings.hl))
+
+def build_consent() -> ConsentHandler:
+    """Create the cookie consent handler for CRAWLER_CONSENT."""
+    return ConsentHandler(settings.consent)
+
+def build_selectors() -> SelectorRegistry:
+    """Load the selector registry shared by every browser, with CRAWLER_SELECTORS_FILE overrides."""
+    return SelectorRegistry.load(settings.selectors_file)
+
+def build_fields() -> FieldSelection:
+    """Create the place field selection from CRAWLER_FIELDS or CRAWLER_DEPTH."""
+    return FieldSelection.parse(settings.fields, settings.depth)
+
+def build_identity() -> Optional[CrawlerIdentity]:
+    """Create the crawler identity when CRAWLER_IDENTIFIED is on."""
+    if not settings.identified:
+        return None
+    return CrawlerIdentity(settings.contact_url, agent=settings.identified_agent)
+
+def build_throttle() -> AdaptiveThrottle:
+    """Create the adaptive throttle, never faster than the identified mode allows."""
+    min_delay = settings.throttle_min_delay
+    if settings.identified:
+        min_delay = max(min_delay, POLITE_MIN_DELAY)
+    return AdaptiveThrottle(min_delay=min_delay, max_delay=max(settings.throttle_max_delay, min_delay))
+
+def scheduler_limits(concurrency: int) -> Tuple[int, Dict[str, float]]:
+    """Return the concurrency and per-domain rate limits to schedule with."""
+    rate_limits = parse_rate_limits(settings.domain_rate_limits)
+    if settings.identified:
+        if concurrency > POLITE_CONCURRENCY:
+            logger.info(f"Identified crawler mode: lowering concurrency from {concurrency} to {POLITE_CONCURRENCY}")
+        return POLITE_CONCURRENCY, polite_rate_limits(rate_limits)
+    return concurrency, rate_limits
+
+def build_proxy_pool() -> Optional[ProxyPool]:
+    """Create the proxy pool from CRAWLER_PROXY_FILE or CRAWLER_PROXIES, if configured."""
+    if settings.proxy_file:
+        return ProxyPool.from_file(settings.proxy_file)
+    if settings.proxies:
+        return ProxyPool(settings.proxies)
+    return None
+
+def build_webhooks() -> Optional[WebhookNotifier]:
+    """Create the job webhook notifier from CRAWLER_WEBHOOK_URLS, if configured."""
+    if not settings.webhook_urls:
+        return None
+    return WebhookNotifier(settings.webhook_urls, secret=settings.webhook_secret)
+
+def job_report(job: Optional[JobContext], checkpoint: Optional[CrawlCheckpoint], errors: ErrorSummary) -> Dict:
+    """Summarize a crawl job for its webhook: counts, where the output went and its errors."""
+    return {
+        'job_id': job.job_id if job else None,
+        'tenant': settings.tenant,
+        'counts': {
+            'completed': len(checkpoint.completed) if checkpoint else 0,
+            'pending': len(checkpoint.pending) if checkpoint else 0,
+            'failed': errors.total(),
+        },
+        'output': {
+            'sinks': settings.sinks,
+            'output_dir': os.path.abspath(settings.output_dir),
+            'checkpoint': str(checkpoint.path) if checkpoint else None,
+        },
+        'errors': errors.to_dict(),
+    }
 
 def main():
     """Main function to run the crawler."""
+    configure_logging()
+    # The first Ctrl-C or SIGTERM stops starting places and lets running ones finish
+    shutdown = ShutdownSignal()
+    webhooks = build_webhooks()
+    errors = ErrorSummary()
+    job, checkpoint, manifest = None, None, None
     try:
-        # Initialize MongoDB client
-        mongodb = MongoDBClient(
-            mongodb_url=settings.MONGODB_URL,
-            db_name=settings.MONGODB_DB,
-            collection_restaurants=settings.MONGODB_COLLECTION_RESTAURANTS,
-            collection_reviews=settings.MONGODB_COLLECTION_REVIEWS
-        )
+        shutdown.install()
+        if settings.tracing:
+            setup_tracing()
+        storage = build_storage()
+        media = build_media_downloader()
+        website = build_website_crawler()
+        places = build_places_enricher()
         
-        # Create indexes
-        mongodb.create_indexes()
+        job = JobContext(tenant=settings.tenant, proxy=settings.proxy)
+        logger.info(f"{job.log_prefix()} Starting crawl job")
+        manifest = RunManifest(job.job_id, settings)
+
+        proxy_pool = build_proxy_pool()
+        block_handler = build_block_handler(settings.block_handler, settings.captcha_solver_url)
+
+        checkpoint = CrawlCheckpoint(settings.resume or os.path.join(settings.output_dir, f"checkpoint_{job.job_id}.json"))
+        logger.info(f"{job.log_prefix()} Checkpointing to {checkpoint.path}")
+
+        progress = ProgressReporter(job, interval=settings.progress_interval)
+        id_strategy = build_id_strategy(settings.id_strategy)
+        throttle = build_throttle()
+        identity = build_identity()
+        locale = build_locale()
+        consent = build_consent()
+        fields = build_fields()
+        card_filter = CardFilter(settings.min_rating, settings.min_reviews,
+                                 settings.include_categories, settings.exclude_categories)
+        selectors = build_selectors()
+        manifest.selectors_version = selectors.version()
+        costs = RunCosts(
+            proxy_per_gb=settings.cost_proxy_per_gb,
+            captcha_per_solve=settings.cost_captcha_per_solve,
+            browser_per_hour=settings.cost_browser_per_hour
+        )
+
+        def new_scraper(**kwargs) -> GoogleMapsScraper:
+            return GoogleMapsScraper(
+                debug=settings.headful,
+                devtools=settings.devtools,
+                job=job,
+                progress=progress,
+                throttle=throttle,
+                costs=costs,
+                id_strategy=id_strategy,
+                record_dir=settings.record_dir,
+                replay_from=settings.replay_file,
+                fixture_dir=settings.fixture_dir,
+                replay_fixtures=settings.replay_fixtures,
+                proxy_pool=proxy_pool,
+                block_handler=block_handler,
+                stealth=settings.stealth,
+                identity=identity,
+                locale=locale,
+                consent=consent,
+                fields=fields,
+                card_filter=card_filter,
+                selectors=selectors,
+                feed_snapshots=settings.feed_snapshots,
+                feed_max_seconds=settings.feed_max_seconds,
+                slow_mo=settings.slow_mo,
+                failure_dir=settings.failure_dir,
+                photo_size=settings.photo_size,
+                **kwargs
+            )
+
+        # Verify selectors against known places before the real run
+        if settings.canary:
+            with new_scraper() as canary_scraper:
+                report = run_canary(canary_scraper)
+            if not report['healthy']:
+                logger.error(format_report(report))
+                sys.exit(1)
+            logger.info(format_report(report))
+
+        # Initialize scrapers
+        with ExitStack() as stack:
+            pool = stack.enter_context(BrowserPool(
+                lambda: new_scraper(
+                    feed_stable_window=settings.feed_stable_window,
+                    review_scroll_budget=settings.review_scroll_budget,
+                    max_reviews=settings.max_reviews
+                ),
+                size=settings.max_browsers,
+                max_jobs=settings.browser_max_jobs,
+                max_minutes=settings.browser_max_minutes
+            ))
+            # Each job holds one browser, or two when reviews are scraped in parallel
+            browsers_per_job = 2 if settings.parallel_reviews else 1
+            concurrency, rate_limits = scheduler_limits(settings.concurrency)
+            if settings.max_browsers < concurrency * browsers_per_job:
+                raise ValueError(f"CRAWLER_MAX_BROWSERS must be at least {concurrency * browsers_per_job} "
+                                 f"for a concurrency of {concurrency}")
+            scheduler = stack.enter_context(Scheduler(
+                concurrency=concurrency,
+                rate_limits=rate_limits,
+                throttle=throttle
+            ))
+            shutdown.on_stop(lambda: scheduler.stop(settings.shutdown_grace))
+
+            def schedule(url: str):
+                """Queue a place, recording it as pending first so a stopped crawl resumes with it."""
+                if checkpoint.is_done(url):
+                    logger.info(f"Skipping {url}, already completed")
+                    return
+                checkpoint.add_pending(url)
+                scheduler.submit(crawl_url, url)
+
+            def crawl_url(url: str):
+                with ExitStack() as browsers:
+                    scraper = browsers.enter_context(pool.browser())
+                    # Under throttling the reviews pane is scraped in the same browser instead
+                    parallel = (settings.parallel_reviews and fields.wants('reviews')
+                                and throttle.allowed_concurrency(2) >= 2 and not identity)
+                    review_scraper = browsers.enter_context(pool.browser()) if parallel else None
+                    done = process_restaurant(scraper, storage, url, review_scraper, media=media, website=website,
+                                              places=places)
+                if done:
+                    checkpoint.mark_done(url)
+                error = None if done or not scraper.last_error else scraper.last_error.to_dict()
+                if not done:
+                    # Places that were not saved without a classified error still count as failed
+                    errors.add(error or {'type': 'not_saved', 'message': f"{url} was not saved"})
+                progress.emit('place_done', force=True, url=url, ok=done, error=error,
+                              completed=len(checkpoint.completed), pending=len(checkpoint.pending))
+
+            # Places left pending by an interrupted attempt go first
+            for url in list(checkpoint.pending):
+                schedule(url)
+
+            if settings.grid_bbox or settings.grid_center:
+                # Cover the whole area cell by cell, deduplicating places across cells
+                if settings.grid_bbox:
+                    center, bbox = None, parse_bbox(settings.grid_bbox)
+                else:
+                    center = tuple(float(v) for v in settings.grid_center.split(','))
+                    bbox = bbox_around(*center, settings.radius_km)
+                cells = grid_cells(bbox, settings.grid_cell_km, center, settings.radius_km)
+                logger.info(f"{job.log_prefix()} Searching '{settings.search_query}' in {len(cells)} grid cells")
+                search_scraper = stack.enter_context(new_scraper(feed_stable_window=settings.feed_stable_window))
+                stream_cards_to_details(
+                    lambda: iter_grid_cards(search_scraper, settings.search_query, cells),
+                    pool.cards,
+                    schedule,
+                    stop=shutdown.event,
+                    max_places=settings.max_places
+                )
+            elif settings.search_url:
+                # Search in another browser and process places as they are found
+                search_scraper = stack.enter_context(new_scraper(
+                    feed_stable_window=settings.feed_stable_window,
+                    result_history=ResultCountHistory(os.path.join(settings.output_dir, 'result_counts.json'))
+                ))
+                # Rephrasings must search the same area as the URL, not the default one
+                area = search_area(settings.search_url) or (settings.area if settings.area_set else None)
+                if settings.rephrase_sparse and not area:
+                    logger.info(f"{job.log_prefix()} No area in the search URL and CRAWLER_AREA is not set, "
+                                f"sparse results will not be rephrased")
+                if settings.rephrase_sparse and area:
+                    # Sparse results are retried with alternate phrasings of the query
+                    phrasings = parse_phrasings(settings.rephrase_phrasings) or DEFAULT_PHRASINGS
+                    stream_cards_to_details(
+                        lambda: iter_cards_with_rephrasing(search_scraper, settings.search_url, settings.max_restaurants,
+                                                           area, phrasings, settings.rephrase_min_results),
+                        pool.cards,
+                        schedule,
+                        stop=shutdown.event,
+                        max_places=settings.max_places
+                    )
+                else:
+                    stream_search_to_details(
+                        search_scraper,
+                        pool.cards,
+                        settings.search_url,
+                        settings.max_restaurants,
+                        schedule,
+                        stop=shutdown.event,
+                        max_places=settings.max_places
+                    )
+            else:
+                # Example restaurant URLs
+                urls = [
+                    "https://www.google.com/maps/place/Rich+Table/data=!4m7!3m6!1s0x80858093eabc4f2d:0x68f428012b5db354!8m2!3d37.7743021!4d-122.4212768!16s%2Fg%2F1q5bmz5wy!19sChIJLfK8rJOAhYARVLNbKwGIb2g?authuser=0&hl=en&rclk=1",
+                    "https://www.google.com/maps/place/Delancey+Street+Restaurant/data=!4m7!3m6!1s0x808580770df6174d:0x8be3ee157d693ab2!8m2!3d37.7843599!4d-122.3884342!16s%2Fm%2F04fjq0y!19sChIJTRf2DXeAhYARsjppfRXu44s?authuser=0&hl=en&rclk=1"
+                ]
+                
+                # Process each restaurant
+                for url in urls:
+                    schedule(url)
         
-        # Initialize scraper
-        with GoogleMapsScraper(debug=True) as scraper:
-            # Example restaurant URLs
-            urls = [
-                "https://www.google.com/maps/place/Rich+Table/data=!4m7!3m6!1s0x80858093eabc4f2d:0x68f428012b5db354!8m2!3d37.7743021!4d-122.4212768!16s%2Fg%2F1q5bmz5wy!19sChIJLfK8rJOAhYARVLNbKwGIb2g?authuser=0&hl=en&rclk=1",
-                "https://www.google.com/maps/place/Delancey+Street+Restaurant/data=!4m7!3m6!1s0x808580770df6174d:0x8be3ee157d693ab2!8m2!3d37.7843599!4d-122.3884342!16s%2Fm%2F04fjq0y!19sChIJTRf2DXeAhYARsjppfRXu44s?authuser=0&hl=en&rclk=1"
-            ]
-            
-            # Process each restaurant
-            for url in urls:
-                process_restaurant(scraper, mongodb, url)
+        # Partial results of a stopped crawl are flushed like a finished one's
+        storage.close()
+        if shutdown.requested:
+            logger.warning(f"{job.log_prefix()} Crawl stopped with {len(checkpoint.pending)} places pending, "
+                           f"resume with CRAWLER_RESUME={checkpoint.path}")
+
+        # Report what the run cost, including the canary
+        os.makedirs(settings.output_dir, exist_ok=True)
+        costs.save(os.path.join(settings.output_dir, f"costs_{job.job_id}.json"),
+                   extra={'job_id': job.job_id, 'tenant': job.tenant, 'area': settings.area})
+        logger.info(f"{job.log_prefix()} Run costs: {costs.summary()}")
+        selectors.save_metrics(os.path.join(settings.output_dir, f"selectors_{job.job_id}.json"))
+
+        # Apply the retention policy to file output and failure artifacts
+        apply_retention(storage)
+
+        status = 'stopped' if shutdown.requested else 'completed'
+        manifest.save(settings.output_dir, status, job_report(job, checkpoint, errors))
+        if webhooks:
+            webhooks.notify(JOB_COMPLETED, {**job_report(job, checkpoint, errors), 'status': status})
                 
     except Exception as e:
         logger.error(f"Error in main: {str(e)}")
+        if manifest:
+            manifest.save(settings.output_dir, 'failed', job_report(job, checkpoint, errors))
+        if webhooks:
+            webhooks.notify(JOB_FAILED, {**job_report(job, checkpoint, errors), 'status': 'failed',
+                                         'error': classify(e).to_dict()})
         sys.exit(1)
+    finally:
+        shutdown.restore()
+        shutdown_tracing()
 
 if __name__ == "__main__":
     main() 
\ No newline at end of file
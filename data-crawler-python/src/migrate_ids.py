@@ -0,0 +1,94 @@
+"""
+Migration of stored place IDs to another ID strategy.
+Re-keys restaurants and their reviews in MongoDB, keeps the previous ID in
+legacy_ids and writes an old -> new mapping CSV for downstream systems.
+
+Usage:
+    python -m src.migrate_ids --to stable --mapping data/id_mapping.csv [--dry-run]
+"""
+
+import argparse
+import csv
+import logging
+import sys
+from typing import List, Tuple
+
+from src.config.settings import settings
+from src.database.mongodb import MongoDBClient
+from src.main import configure_logging
+from src.storage.ids import IdStrategy, build_id_strategy
+
+logger = logging.getLogger(__name__)
+
+def plan_migration(mongodb: MongoDBClient, strategy: IdStrategy) -> List[Tuple[str, str]]:
+    """Return (old_id, new_id) pairs for every restaurant whose ID changes."""
+    mapping = []
+    for restaurant in mongodb.restaurants.find({}):
+        new_id = strategy.place_id(restaurant)
+        if new_id and new_id != restaurant['_id']:
+            mapping.append((restaurant['_id'], new_id))
+    return mapping
+
+def migrate_restaurant(mongodb: MongoDBClient, old_id: str, new_id: str) -> int:
+    """Move a restaurant and its reviews to a new ID; returns the number of reviews moved."""
+    restaurant = mongodb.restaurants.find_one({'_id': old_id})
+    if not restaurant:
+        return 0
+    if mongodb.restaurants.find_one({'_id': new_id}):
+        # The place was already crawled under its new ID, keep the newer copy
+        mongodb.restaurants.update_one({'_id': new_id}, {'$addToSet': {'legacy_ids': old_id}})
+    else:
+        restaurant['_id'] = new_id
+        restaurant['legacy_ids'] = sorted(set(restaurant.get('legacy_ids', [])) | {old_id})
+        mongodb.restaurants.insert_one(restaurant)
+    mongodb.restaurants.delete_one({'_id': old_id})
+
+    moved = 0
+    for review in mongodb.reviews.find({'restaurant_id': old_id}):
+        old_review_id = review['_id']
+        suffix = review.get('review_id') or old_review_id.split('_review_')[-1]
+        review['_id'] = f"{new_id}_review_{suffix}"
+        review['id_review'] = review['_id']
+        review['restaurant_id'] = new_id
+        mongodb.reviews.replace_one({'_id': review['_id']}, review, upsert=True)
+        mongodb.reviews.delete_one({'_id': old_review_id})
+        moved += 1
+    return moved
+
+def write_mapping(path: str, mapping: List[Tuple[str, str]]):
+    with open(path, 'w', newline='', encoding='utf-8') as f:
+        writer = csv.writer(f)
+        writer.writerow(['old_id', 'new_id'])
+        writer.writerows(mapping)
+
+def main():
+    configure_logging()
+    parser = argparse.ArgumentParser(description='Re-key stored places with another ID strategy.')
+    parser.add_argument('--to', default=settings.id_strategy, help='Target ID strategy (stable or legacy)')
+    parser.add_argument('--mapping', default='id_mapping.csv', help='CSV file for the old -> new ID mapping')
+    parser.add_argument('--dry-run', action='store_true', help='Only write the mapping')
+    args = parser.parse_args()
+
+    try:
+        mongodb = MongoDBClient(
+            mongodb_url=settings.MONGODB_URL,
+            db_name=settings.MONGODB_DB,
+            collection_restaurants=settings.MONGODB_COLLECTION_RESTAURANTS,
+            collection_reviews=settings.MONGODB_COLLECTION_REVIEWS
+        )
+        mapping = plan_migration(mongodb, build_id_strategy(args.to))
+        write_mapping(args.mapping, mapping)
+        logger.info(f"{len(mapping)} places change ID, mapping written to {args.mapping}")
+        if args.dry_run:
+            return
+
+        reviews = 0
+        for old_id, new_id in mapping:
+            reviews += migrate_restaurant(mongodb, old_id, new_id)
+        logger.info(f"Migrated {len(mapping)} places and {reviews} reviews to '{args.to}' IDs")
+    except Exception as e:
+        logger.error(f"ID migration failed: {str(e)}")
+        sys.exit(1)
+
+if __name__ == "__main__":
+    main()
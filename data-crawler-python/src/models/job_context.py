@@ -0,0 +1,18 @@
+"""
+Structured context identifying a crawl job.
+"""
+
+import uuid
+from typing import Optional
+from pydantic import BaseModel, Field
+
+class JobContext(BaseModel):
+    """Identity of a crawl job, attached to logs and stored records."""
+    job_id: str = Field(default_factory=lambda: uuid.uuid4().hex, description="Unique job identifier")
+    tenant: Optional[str] = Field(None, description="Tenant the job runs for")
+    attempt: int = Field(1, description="Attempt number, starting at 1")
+    proxy: Optional[str] = Field(None, description="Proxy identity used by the browser")
+
+    def log_prefix(self) -> str:
+        """Short prefix for log lines."""
+        return f"[job={self.job_id} tenant={self.tenant or '-'} attempt={self.attempt}]"
@@ -17,6 +17,20 @@ class Review(BaseModel):
     n_review_user: Optional[int] = Field(None, description="Number of reviews by this user")
     n_photo_user: Optional[int] = Field(None, description="Number of photos by this user")
     url_user: Optional[str] = Field(None, description="URL to user's profile")
+    language: Optional[str] = Field(None, description="ISO 639-1 code of the review text's language")
+    translation: Optional[Dict] = Field(None, description="Source language when the text was translated by Google")
+
+class Photo(BaseModel):
+    """Model for a gallery photo."""
+    url: str = Field(..., description="Photo URL at the requested resolution")
+    thumbnail_url: Optional[str] = Field(None, description="Photo URL as shown on the page")
+
+class MenuItem(BaseModel):
+    """Model for an item of the Menu tab."""
+    section: Optional[str] = Field(None, description="Menu section, e.g. \"Starters\"")
+    name: str = Field(..., description="Item name")
+    price: Optional[str] = Field(None, description="Price as displayed, e.g. \"$12.50\"")
+    description: Optional[str] = Field(None, description="Item description")
 
 class Location(BaseModel):
     """Model for restaurant location."""
@@ -31,13 +45,41 @@ class Location(BaseModel):
 class OpeningHours(BaseModel):
     """Model for restaurant opening hours."""
     day: Optional[int] = Field(None, description="Day of week (0=Monday, 6=Sunday)")
+    open: Optional[int] = Field(None, description="Opening time in minutes since midnight")
+    close: Optional[int] = Field(None, description="Closing time in minutes since midnight, past 1440 when overnight")
+    overnight: bool = Field(False, description="Whether the interval closes after midnight")
+    open_24h: bool = Field(False, description="Whether the place is open all day")
+    closed: bool = Field(False, description="Whether the place is closed all day")
     open_time: Optional[str] = Field(None, description="Opening time (HH:MM)")
     close_time: Optional[str] = Field(None, description="Closing time (HH:MM)")
+    raw: Optional[str] = Field(None, description="Hours text as displayed, e.g. \"11 AM–10 PM\"")
+
+class PopularHour(BaseModel):
+    """Model for the usual busyness of one hour of the week."""
+    day: str = Field(..., description="Day name, e.g. \"Monday\"")
+    hour: int = Field(..., description="Hour of the day (0-23)")
+    busyness: int = Field(..., description="Usual busyness in percent of the week's peak")
+
+class LiveBusyness(BaseModel):
+    """Model for the live busyness shown for the current hour."""
+    day: str = Field(..., description="Day name, e.g. \"Monday\"")
+    hour: Optional[int] = Field(None, description="Hour of the day (0-23)")
+    busyness: int = Field(..., description="Current busyness in percent of the week's peak")
+    usual: Optional[int] = Field(None, description="Usual busyness of the hour, when shown")
+    captured_at: Optional[str] = Field(None, description="When the live busyness was captured (ISO 8601)")
+
+class PopularTimes(BaseModel):
+    """Model for one capture of the popular times chart."""
+    captured_at: str = Field(..., description="When the chart was captured (ISO 8601)")
+    histogram: Dict[str, List[Optional[int]]] = Field(default_factory=dict, description="24 hourly values by day name")
+    hours: List[PopularHour] = Field(default_factory=list, description="Known hourly values")
+    live: Optional[LiveBusyness] = Field(None, description="Live busyness, when the place showed it")
 
 class RestaurantAttributes(BaseModel):
     """Model for restaurant attributes and features."""
     cuisine_type: Optional[List[str]] = Field(default_factory=list, description="Types of cuisine served")
-    price_level: Optional[str] = Field(None, description="Price level ($-$$$$)")
+    price_level: Optional[int] = Field(None, description="Price level (1-4)")
+    price_range: Optional[Dict] = Field(None, description="Normalized price: level, min, max, currency and raw text")
     service_speed: Optional[str] = Field(None, description="Speed of service")
     ambiance: Optional[str] = Field(None, description="Restaurant ambiance")
     noise_level: Optional[str] = Field(None, description="Noise level")
@@ -54,10 +96,26 @@ class Restaurant(BaseModel):
     location: Optional[Dict] = Field(None, description="Restaurant location")
     phone: Optional[str] = Field(None, description="Contact phone number")
     website: Optional[str] = Field(None, description="Restaurant website")
-    opening_hours: Optional[List[Dict]] = Field(default_factory=list, description="Opening hours")
+    place_id: Optional[str] = Field(None, description="Google Places API place_id")
+    osm_id: Optional[str] = Field(None, description="OpenStreetMap element, e.g. \"node/123\"")
+    osm_opening_hours: Optional[str] = Field(None, description="Opening hours in OpenStreetMap syntax, as tagged")
+    source: Optional[str] = Field(None, description="\"places_api\" or \"osm\" for places not from the scraper")
+    plus_code: Optional[str] = Field(None, description="Plus code as shown on the place page")
+    timezone: Optional[str] = Field(None, description="IANA timezone of the location, e.g. \"America/Los_Angeles\"")
+    opening_hours: Optional[List[OpeningHours]] = Field(default_factory=list, description="Opening hours intervals")
+    opening_hours_raw: Optional[Dict[str, str]] = Field(default_factory=dict, description="Hours text by day name")
+    popular_times: Optional[PopularTimes] = Field(None, description="Popular times chart with live busyness")
+    status: Optional[str] = Field(None, description="Business status, e.g. \"permanently_closed\" or \"open\"")
+    status_raw: Optional[str] = Field(None, description="Status text as displayed, e.g. \"Open ⋅ Closes 10 PM\"")
+    is_operational: Optional[bool] = Field(None, description="False when the place is permanently or temporarily closed")
     overall_rating: Optional[float] = Field(None, description="Overall rating (1-5)")
     total_reviews: Optional[int] = Field(None, description="Total number of reviews")
+    category: Optional[str] = Field(None, description="Primary category, e.g. \"Noodle shop\"")
     attributes: Optional[Dict] = Field(default_factory=dict, description="Restaurant attributes")
-    photos: Optional[List[str]] = Field(default_factory=list, description="Photo URLs")
+    about: Optional[Dict[str, List[str]]] = Field(default_factory=dict, description="About tab attributes by section, e.g. service options or accessibility")
+    photos: Optional[List[Photo]] = Field(default_factory=list, description="Gallery photos")
+    menu: Optional[List[MenuItem]] = Field(default_factory=list, description="Menu items from the Menu tab")
+    menu_images: Optional[List[str]] = Field(default_factory=list, description="Menu photo URLs when there is no structured menu")
+    menu_url: Optional[str] = Field(None, description="Link to the restaurant's own menu page")
     reviews: Optional[List[Dict]] = Field(default_factory=list, description="Restaurant reviews")
     raw_data: Optional[Dict] = Field(None, description="Raw scraped data")
\ No newline at end of file
@@ -0,0 +1,71 @@
+"""
+Seed the database from OpenStreetMap.
+Fetches restaurants in a bounding box through the Overpass API and stores
+them as restaurant documents, without scraping Google at all. The places
+have no ratings or reviews, so only the search's category filters apply.
+
+Usage:
+    python -m src.osm --bbox 37.70,-122.52,37.82,-122.35
+    python -m src.osm --center 37.77,-122.42 --radius-km 2 --amenities restaurant,cafe
+"""
+
+import argparse
+import logging
+import sys
+
+from src.config.settings import settings
+from src.crawler.card_filter import CardFilter
+from src.crawler.grid import bbox_around, parse_bbox
+from src.enrichment.overpass import OverpassClient
+from src.enrichment.timezones import tag_timezone
+from src.main import build_storage, configure_logging
+from src.models.job_context import JobContext
+from src.storage.ids import build_id_strategy
+
+logger = logging.getLogger(__name__)
+
+def main():
+    configure_logging()
+    parser = argparse.ArgumentParser(description='Seed restaurants from OpenStreetMap through Overpass.')
+    parser.add_argument('--bbox', default=settings.grid_bbox, help='Area as "south,west,north,east"')
+    parser.add_argument('--center', default=settings.grid_center, help='Area around "lat,lng" instead')
+    parser.add_argument('--radius-km', type=float, default=settings.radius_km, help='Radius around the center')
+    parser.add_argument('--amenities', default=','.join(settings.osm_amenities),
+                        help='Comma-separated OSM amenity values, e.g. restaurant,cafe,fast_food')
+    args = parser.parse_args()
+
+    if args.bbox:
+        bbox = parse_bbox(args.bbox)
+    elif args.center:
+        bbox = bbox_around(*(float(v) for v in args.center.split(',')), args.radius_km)
+    else:
+        logger.error("Give an area with --bbox or --center")
+        sys.exit(1)
+
+    try:
+        storage = build_storage()
+        job = JobContext(tenant=settings.tenant)
+        id_strategy = build_id_strategy(settings.id_strategy)
+        card_filter = CardFilter(include_categories=settings.include_categories,
+                                 exclude_categories=settings.exclude_categories)
+        client = OverpassClient(settings.overpass_url, user_agent=settings.identified_agent)
+
+        stored, skipped = 0, 0
+        for restaurant in client.places(bbox, [a.strip() for a in args.amenities.split(',') if a.strip()]):
+            card = {**restaurant, 'category': ', '.join(restaurant['attributes']['cuisine_type'])}
+            if card_filter.active and not card_filter.accepts(card):
+                skipped += 1
+                continue
+            tag_timezone(restaurant)
+            restaurant['_id'] = id_strategy.place_id(restaurant)
+            restaurant['job'] = job.dict()
+            if any(storage.upsert_restaurant(restaurant).values()):
+                stored += 1
+        storage.close()
+        logger.info(f"{job.log_prefix()} Stored {stored} places from OpenStreetMap ({skipped} filtered out)")
+    except Exception as e:
+        logger.error(f"OpenStreetMap seeding failed: {str(e)}")
+        sys.exit(1)
+
+if __name__ == "__main__":
+    main()
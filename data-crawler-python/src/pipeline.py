@@ -0,0 +1,219 @@
+"""
+Multi-stage crawl pipeline: crawl -> enrich -> validate -> export.
+Each stage reads the checkpoint file of the stage before it and writes
+its own JSON lines file under <output_dir>/pipeline, so any stage can be
+re-run without repeating the earlier ones.
+
+Usage:
+    python -m src.pipeline --urls urls.txt         # run every stage
+    python -m src.pipeline --from-stage validate   # re-run validate and export
+"""
+
+import argparse
+import json
+import logging
+import re
+import sys
+from pathlib import Path
+from typing import Dict, Iterable, List
+
+from src.config.settings import settings
+from src.crawler.google_maps_crawler import GoogleMapsScraper
+from src.crawler.place_job import scrape_menu
+from src.enrichment.cuisine import HttpCuisineClassifier, RuleCuisineClassifier, infer_cuisine
+from src.enrichment.deals import mine_deals
+from src.enrichment.engagement import response_metrics
+from src.enrichment.geo import coordinates_from_url, decode_plus_code
+from src.enrichment.localities import LocalityIndex, tag_locality
+from src.enrichment.menu_prices import menu_price_stats
+from src.enrichment.phash import flag_duplicate_thumbnails
+from src.enrichment.photos import HttpPhotoClassifier, tag_photos
+from src.enrichment.popular_times import summarize as summarize_popular_times
+from src.enrichment.sources import FoodInspectionSource
+from src.enrichment.timezones import tag_timezone
+from src.main import build_places_enricher, build_storage, configure_logging
+from src.models.job_context import JobContext
+from src.storage.ids import build_id_strategy
+
+logger = logging.getLogger(__name__)
+
+STAGES = ['crawl', 'enrich', 'validate', 'export']
+
+def checkpoint_path(stage: str) -> Path:
+    """Return the checkpoint file written by a stage."""
+    return Path(settings.output_dir) / 'pipeline' / f"{stage}.jsonl"
+
+def read_checkpoint(stage: str) -> List[Dict]:
+    """Read the records written by a stage."""
+    path = checkpoint_path(stage)
+    if not path.exists():
+        raise FileNotFoundError(f"No checkpoint for stage '{stage}' at {path}, run it first")
+    with open(path, 'r', encoding='utf-8') as f:
+        return [json.loads(line) for line in f if line.strip()]
+
+def write_checkpoint(stage: str, records: Iterable[Dict]) -> int:
+    """Write a stage's records and return how many were written."""
+    path = checkpoint_path(stage)
+    path.parent.mkdir(parents=True, exist_ok=True)
+    count = 0
+    with open(path, 'w', encoding='utf-8') as f:
+        for record in records:
+            f.write(json.dumps(record, ensure_ascii=False, default=str) + '\n')
+            count += 1
+    logger.info(f"Stage '{stage}' wrote {count} records to {path}")
+    return count
+
+def crawl(urls: List[str]) -> List[Dict]:
+    """Scrape every URL into {'restaurant', 'reviews'} records, with the Menu tab when CRAWLER_SCRAPE_MENUS is set."""
+    records = []
+    job = JobContext(tenant=settings.tenant, proxy=settings.proxy)
+    with GoogleMapsScraper(debug=settings.headful, devtools=settings.devtools, job=job, id_strategy=build_id_strategy(settings.id_strategy)) as scraper:
+        for url in urls:
+            result = scraper.get_account(url)
+            restaurant = result.get('restaurant', {})
+            if restaurant.get('name'):
+                if settings.scrape_menus:
+                    scrape_menu(scraper, restaurant, url)
+                records.append(result)
+    return records
+
+def enrich(records: List[Dict]) -> List[Dict]:
+    """Normalize text fields, fill missing coordinates, tag the locality and timezone, derive menu
+    prices, cuisine, deals, the popular times summary and owner response metrics,
+    tag photos, fill missing contact details and hours from the Places API, attach data from
+    the configured enrichment sources, and flag nearby places with near-identical thumbnails."""
+    if settings.cuisine_classifier_url:
+        classifier = HttpCuisineClassifier(settings.cuisine_classifier_url)
+    else:
+        classifier = RuleCuisineClassifier()
+    sources = []
+    if settings.food_inspection_url:
+        sources.append(FoodInspectionSource(settings.food_inspection_url))
+    places = build_places_enricher()
+    photo_classifier = HttpPhotoClassifier(settings.photo_classifier_url) if settings.photo_classifier_url else None
+    localities = LocalityIndex.from_file(settings.boundaries_file)
+
+    for record in records:
+        restaurant = record['restaurant']
+        if restaurant.get('name'):
+            restaurant['name'] = re.sub(r'\s+', ' ', restaurant['name']).strip()
+
+        location = restaurant.setdefault('location', {})
+        if location.get('city'):
+            location['city'] = location['city'].strip().title()
+        if not location.get('coordinates'):
+            coordinates = coordinates_from_url(restaurant.get('url')) or decode_plus_code(restaurant.get('plus_code'))
+            if coordinates:
+                location['type'] = 'Point'
+                location['coordinates'] = [coordinates[1], coordinates[0]]
+        tag_locality(restaurant, localities)
+        tag_timezone(restaurant)
+
+        if restaurant.get('menu'):
+            stats = menu_price_stats(restaurant['menu'])
+            if stats:
+                restaurant['menu_prices'] = stats
+
+        infer_cuisine(restaurant, record.get('reviews', []), classifier)
+
+        deals = mine_deals(record.get('reviews', []))
+        if deals:
+            restaurant['deals'] = deals
+
+        if restaurant.get('popular_times') and not restaurant.get('popular_times_summary'):
+            restaurant['popular_times_summary'] = summarize_popular_times([restaurant['popular_times']])
+
+        engagement = response_metrics(record.get('reviews', []))
+        if engagement:
+            restaurant['engagement'] = engagement
+
+        if photo_classifier:
+            tag_photos(restaurant, photo_classifier)
+
+        if places:
+            places.fill(restaurant)
+
+        for source in sources:
+            found = source.lookup(restaurant)
+            if found:
+                restaurant.setdefault('enrichment', {})[source.name] = found
+
+    flag_duplicate_thumbnails([record['restaurant'] for record in records])
+    return records
+
+def validation_errors(restaurant: Dict) -> List[str]:
+    """Return the reasons a restaurant record is unusable."""
+    errors = []
+    if not restaurant.get('_id'):
+        errors.append('missing _id')
+    if not restaurant.get('name'):
+        errors.append('missing name')
+    if len(restaurant.get('location', {}).get('coordinates') or []) != 2:
+        errors.append('missing coordinates')
+    rating = restaurant.get('overall_rating')
+    if rating is not None and not 1 <= rating <= 5:
+        errors.append(f"rating out of range: {rating}")
+    return errors
+
+def validate(records: List[Dict]) -> List[Dict]:
+    """Keep valid records and write the rejected ones next to the checkpoint."""
+    valid, rejected = [], []
+    for record in records:
+        errors = validation_errors(record['restaurant'])
+        if errors:
+            rejected.append({**record, 'errors': errors})
+        else:
+            valid.append(record)
+    write_checkpoint('validate_rejected', rejected)
+    return valid
+
+def export(records: List[Dict]) -> List[Dict]:
+    """Write validated records to the configured sinks."""
+    storage = build_storage()
+    for record in records:
+        restaurant = record['restaurant']
+        storage.upsert_restaurant(restaurant)
+        if record.get('reviews'):
+            storage.upsert_reviews(restaurant['_id'], record['reviews'])
+    storage.close()
+    return records
+
+def run(from_stage: str = 'crawl', urls: List[str] = None):
+    """Run the pipeline starting at `from_stage`."""
+    start = STAGES.index(from_stage)
+    records = read_checkpoint(STAGES[start - 1]) if start > 0 else None
+
+    for stage in STAGES[start:]:
+        logger.info(f"Running stage '{stage}'")
+        if stage == 'crawl':
+            records = crawl(urls or [])
+        elif stage == 'enrich':
+            records = enrich(records)
+        elif stage == 'validate':
+            records = validate(records)
+        elif stage == 'export':
+            records = export(records)
+        write_checkpoint(stage, records)
+
+def main():
+    configure_logging()
+    parser = argparse.ArgumentParser(description='Run the crawl pipeline.')
+    parser.add_argument('--from-stage', choices=STAGES, default='crawl', help='First stage to run')
+    parser.add_argument('--urls', help='File with one Google Maps place URL per line (crawl stage)')
+    args = parser.parse_args()
+
+    urls = []
+    if args.urls:
+        with open(args.urls, 'r', encoding='utf-8') as f:
+            urls = [line.strip() for line in f if line.strip()]
+    if args.from_stage == 'crawl' and not urls:
+        parser.error('--urls is required when running the crawl stage')
+
+    try:
+        run(args.from_stage, urls)
+    except Exception as e:
+        logger.error(f"Pipeline failed: {str(e)}")
+        sys.exit(1)
+
+if __name__ == "__main__":
+    main()
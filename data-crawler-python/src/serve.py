@@ -0,0 +1,341 @@
+"""
+Crawler daemon with a REST API.
+Runs the embedded crawler as a long-lived service that the smart-dine
+backend submits search jobs to. Each job searches Maps for a query,
+optionally around a point, and scrapes every result into the configured
+sinks on the shared browser pool. Jobs are kept in memory and are lost
+when the daemon restarts; the scraped places are not. Finished jobs are
+forgotten after CRAWLER_JOB_RETENTION seconds. With CRAWLER_WEBHOOK_URLS
+set, finished and failed jobs are also posted there.
+
+The API listens on localhost unless CRAWLER_API_TOKEN is set, in which
+case every request but /health needs an "Authorization: Bearer <token>"
+header.
+
+    POST   /jobs               {"query": "ramen", "lat": 37.77, "lng": -122.42, "max_results": 40, "zoom": 14,
+                                "min_rating": 4.0, "min_reviews": 50, "exclude_categories": ["Fast food"]}
+    GET    /jobs               all jobs
+    GET    /jobs/{id}          status, progress and counts
+    GET    /jobs/{id}/results  the places found so far with their outcome
+    DELETE /jobs/{id}          cancel a running job and forget it
+    GET    /health             the embedded crawler's health
+
+Usage:
+    python -m src.serve [--host 127.0.0.1] [--port 8080] [--concurrency 2]
+"""
+
+import argparse
+import hmac
+import json
+import logging
+import re
+import sys
+import threading
+import time
+import uuid
+from http.server import BaseHTTPRequestHandler, ThreadingHTTPServer
+from typing import Callable, Dict, List, Optional
+from urllib.parse import quote_plus
+
+from src.config.settings import settings
+from src.crawler.card_filter import CardFilter
+from src.crawler.errors import classify
+from src.crawler.grid import cell_search_url
+from src.crawler.shutdown import ShutdownSignal
+from src.embed import EmbeddedCrawler
+from src.crawler.webhooks import JOB_COMPLETED, JOB_FAILED, ErrorSummary, WebhookNotifier
+from src.main import build_webhooks, configure_logging, process_restaurant
+
+logger = logging.getLogger(__name__)
+
+QUEUED, RUNNING, DONE, FAILED, CANCELLED = 'queued', 'running', 'done', 'failed', 'cancelled'
+DEFAULT_ZOOM = 14
+LOOPBACK_HOSTS = ('127.0.0.1', '::1', 'localhost')
+
+def job_search_url(spec: Dict) -> str:
+    """Build the Maps search URL of a job, centred on its point when it has one."""
+    if spec.get('lat') is not None and spec.get('lng') is not None:
+        zoom = int(spec.get('zoom') or DEFAULT_ZOOM)
+        return cell_search_url(spec['query'], float(spec['lat']), float(spec['lng']), zoom)
+    return f"https://www.google.com/maps/search/{quote_plus(spec['query'])}"
+
+class CrawlJob:
+    """State of one submitted search job."""
+
+    def __init__(self, spec: Dict, on_finish: Optional[Callable[['CrawlJob'], None]] = None):
+        self.id = uuid.uuid4().hex[:12]
+        self.spec = spec
+        self.status = QUEUED
+        self.error: Optional[Dict] = None
+        self.created_at = time.time()
+        self.finished_at: Optional[float] = None
+        self.search_done = False
+        self.counts = {'found': 0, 'saved': 0, 'failed': 0, 'skipped': 0}
+        self.results: List[Dict] = []
+        self.cancelled = threading.Event()
+        self.lock = threading.Lock()
+        self.on_finish = on_finish
+
+    def finish(self, status: str, error: Optional[Dict] = None):
+        # Called with the lock held, so the callback runs on its own thread
+        if self.finished_at is None:
+            self.status, self.error, self.finished_at = status, error, time.time()
+            if self.on_finish:
+                threading.Thread(target=self.on_finish, args=(self,), name=f'job-{self.id}-finish',
+                                 daemon=True).start()
+
+    def to_dict(self) -> Dict:
+        with self.lock:
+            processed = self.counts['saved'] + self.counts['failed'] + self.counts['skipped']
+            return {
+                'id': self.id,
+                'status': self.status,
+                'spec': self.spec,
+                'counts': dict(self.counts),
+                'progress': {'search_done': self.search_done, 'processed': processed, 'found': self.counts['found']},
+                'error': self.error,
+                'created_at': self.created_at,
+                'finished_at': self.finished_at,
+            }
+
+class JobManager:
+    """Runs search jobs on the embedded crawler's browsers and scheduler."""
+
+    def __init__(self, crawler: EmbeddedCrawler, webhooks: Optional[WebhookNotifier] = None,
+                 retention: float = settings.job_retention):
+        self.crawler = crawler
+        self.webhooks = webhooks
+        self.retention = retention
+        self.jobs: Dict[str, CrawlJob] = {}
+        self._lock = threading.Lock()
+
+    def _prune(self):
+        # Called with the lock held
+        cutoff = time.time() - self.retention
+        for job_id in [i for i, job in self.jobs.items() if job.finished_at and job.finished_at < cutoff]:
+            del self.jobs[job_id]
+
+    def submit(self, spec: Dict) -> CrawlJob:
+        if not isinstance(spec.get('query'), str) or not spec['query'].strip():
+            raise ValueError("'query' is required")
+        spec = {**spec, 'max_results': int(spec.get('max_results') or settings.max_restaurants)}
+        if spec.get('min_rating') is not None:
+            spec['min_rating'] = float(spec['min_rating'])
+        if spec.get('min_reviews') is not None:
+            spec['min_reviews'] = int(spec['min_reviews'])
+        for key in ('include_categories', 'exclude_categories'):
+            if not isinstance(spec.get(key) or [], list):
+                raise ValueError(f"'{key}' must be a list")
+        job = CrawlJob(spec, on_finish=self._notify if self.webhooks else None)
+        with self._lock:
+            self._prune()
+            self.jobs[job.id] = job
+        threading.Thread(target=self._run, args=(job,), name=f'job-{job.id}', daemon=True).start()
+        logger.info(f"Accepted job {job.id}: {spec}")
+        return job
+
+    def get(self, job_id: str) -> Optional[CrawlJob]:
+        with self._lock:
+            self._prune()
+            return self.jobs.get(job_id)
+
+    def all(self) -> List[CrawlJob]:
+        with self._lock:
+            self._prune()
+            return list(self.jobs.values())
+
+    def cancel(self, job_id: str) -> Optional[CrawlJob]:
+        """Stop searching and starting places for a job and forget it; running places finish."""
+        with self._lock:
+            job = self.jobs.pop(job_id, None)
+        if job:
+            job.cancelled.set()
+            with job.lock:
+                job.finish(CANCELLED)
+            logger.info(f"Cancelled job {job_id}")
+        return job
+
+    def _run(self, job: CrawlJob):
+        with job.lock:
+            job.status = RUNNING
+        try:
+            with self.crawler.pool.browser() as scraper:
+                card_filter = CardFilter(job.spec.get('min_rating'), job.spec.get('min_reviews'),
+                                         job.spec.get('include_categories') or [],
+                                         job.spec.get('exclude_categories') or [])
+                for card in scraper.iter_search_cards(job_search_url(job.spec), job.spec['max_results'], card_filter):
+                    if job.cancelled.is_set():
+                        break
+                    # Share the card so the detail pass can reconcile against it
+                    key = card['cid'] or card['url']
+                    self.crawler.pool.cards[key] = card
+                    with job.lock:
+                        job.counts['found'] += 1
+                        job.results.append({'url': card['url'], 'cid': card['cid'], 'name': card.get('name'),
+                                            'status': QUEUED})
+                        index = len(job.results) - 1
+                    self.crawler.scheduler.submit(lambda url, index=index, key=key: self._place(job, index, key, url),
+                                                  card['url'])
+        except Exception as e:
+            error = classify(e, job_search_url(job.spec))
+            logger.error(f"Search of job {job.id} failed: {str(e)}")
+            with job.lock:
+                job.finish(FAILED, error.to_dict())
+            return
+        with job.lock:
+            job.search_done = True
+            self._maybe_done(job)
+
+    def _place(self, job: CrawlJob, index: int, key: str, url: str):
+        if job.cancelled.is_set():
+            outcome, error = 'skipped', None
+        else:
+            error = None
+            try:
+                with self.crawler.pool.browser() as scraper:
+                    ok = process_restaurant(scraper, self.crawler.storage, url,
+                                            media=self.crawler.media, website=self.crawler.website)
+                    if not ok and scraper.last_error:
+                        error = scraper.last_error.to_dict()
+            except Exception as e:
+                ok, error = False, classify(e, url).to_dict()
+            outcome = 'saved' if ok else 'failed'
+        # The card was only kept for this place's reconciliation
+        self.crawler.pool.cards.pop(key, None)
+        with job.lock:
+            job.counts[outcome] += 1
+            job.results[index].update(status=outcome, error=error)
+            self._maybe_done(job)
+
+    def _maybe_done(self, job: CrawlJob):
+        # Called with job.lock held
+        processed = job.counts['saved'] + job.counts['failed'] + job.counts['skipped']
+        if job.finished_at is None and job.search_done and processed >= job.counts['found']:
+            job.finish(DONE)
+            logger.info(f"Job {job.id} done: {job.counts}")
+
+    def _notify(self, job: CrawlJob):
+        errors = ErrorSummary()
+        with job.lock:
+            for result in job.results:
+                if result['status'] == 'failed':
+                    errors.add(result.get('error'))
+        state = job.to_dict()
+        self.webhooks.notify(JOB_FAILED if state['status'] == FAILED else JOB_COMPLETED, {
+            'job_id': job.id,
+            'status': state['status'],
+            'spec': state['spec'],
+            'counts': state['counts'],
+            'output': {'sinks': settings.sinks, 'results': f"/jobs/{job.id}/results"},
+            'error': state['error'],
+            'errors': errors.to_dict(),
+        })
+
+class ApiHandler(BaseHTTPRequestHandler):
+    manager: JobManager = None
+    token: Optional[str] = None
+
+    def _send(self, status: int, body):
+        payload = json.dumps(body, default=str).encode('utf-8')
+        self.send_response(status)
+        self.send_header('Content-Type', 'application/json')
+        self.send_header('Content-Length', str(len(payload)))
+        self.end_headers()
+        self.wfile.write(payload)
+
+    def _authorized(self) -> bool:
+        """Check the bearer token when one is configured, answering 401 if it is missing or wrong."""
+        if not self.token:
+            return True
+        header = self.headers.get('Authorization') or ''
+        if hmac.compare_digest(header.encode('utf-8'), f"Bearer {self.token}".encode('utf-8')):
+            return True
+        self._send(401, {'error': 'Unauthorized'})
+        return False
+
+    def _job(self, job_id: str) -> Optional[CrawlJob]:
+        job = self.manager.get(job_id)
+        if not job:
+            self._send(404, {'error': f"No job {job_id}"})
+        return job
+
+    def do_GET(self):
+        if self.path == '/health':
+            return self._send(200, self.manager.crawler.health())
+        if not self._authorized():
+            return
+        if self.path == '/jobs':
+            return self._send(200, [job.to_dict() for job in self.manager.all()])
+        match = re.fullmatch(r'/jobs/(\w+)(/results)?', self.path)
+        if not match:
+            return self._send(404, {'error': 'Not found'})
+        job = self._job(match.group(1))
+        if not job:
+            return
+        if match.group(2):
+            with job.lock:
+                return self._send(200, {'id': job.id, 'status': job.status, 'results': list(job.results)})
+        return self._send(200, job.to_dict())
+
+    def do_POST(self):
+        if not self._authorized():
+            return
+        if self.path != '/jobs':
+            return self._send(404, {'error': 'Not found'})
+        try:
+            length = int(self.headers.get('Content-Length') or 0)
+            spec = json.loads(self.rfile.read(length) or b'{}')
+            if not isinstance(spec, dict):
+                raise ValueError("Expected a JSON object")
+            job = self.manager.submit(spec)
+        except ValueError as e:
+            return self._send(400, {'error': str(e)})
+        return self._send(202, job.to_dict())
+
+    def do_DELETE(self):
+        if not self._authorized():
+            return
+        match = re.fullmatch(r'/jobs/(\w+)', self.path)
+        if not match:
+            return self._send(404, {'error': 'Not found'})
+        job = self.manager.cancel(match.group(1))
+        if not job:
+            return self._send(404, {'error': f"No job {match.group(1)}"})
+        return self._send(200, job.to_dict())
+
+    def log_message(self, format, *args):
+        logger.debug(f"{self.address_string()} {format % args}")
+
+def main():
+    configure_logging()
+    parser = argparse.ArgumentParser(description='Run the crawler as a service with a REST API.')
+    parser.add_argument('--host', default='127.0.0.1', help='Address to listen on')
+    parser.add_argument('--port', type=int, default=8080, help='Port to listen on')
+    parser.add_argument('--concurrency', type=int, default=settings.concurrency, help='Places processed at once')
+    args = parser.parse_args()
+
+    if args.host not in LOOPBACK_HOSTS and not settings.api_token:
+        logger.error(f"Set CRAWLER_API_TOKEN to serve the crawler API on {args.host}")
+        sys.exit(1)
+
+    # One browser searches while the others scrape places
+    crawler = EmbeddedCrawler(concurrency=args.concurrency, browsers=max(settings.max_browsers, args.concurrency + 1))
+    try:
+        crawler.start()
+        ApiHandler.manager = JobManager(crawler, webhooks=build_webhooks())
+        ApiHandler.token = settings.api_token
+        server = ThreadingHTTPServer((args.host, args.port), ApiHandler)
+        with ShutdownSignal() as shutdown:
+            # serve_forever() returns once shutdown() is called from another thread
+            shutdown.on_stop(lambda: threading.Thread(target=server.shutdown).start())
+            logger.info(f"Serving the crawler API on {args.host}:{args.port}")
+            server.serve_forever()
+        server.server_close()
+    except Exception as e:
+        logger.error(f"Crawler service failed: {str(e)}")
+        sys.exit(1)
+    finally:
+        crawler.stop()
+
+if __name__ == "__main__":
+    main()
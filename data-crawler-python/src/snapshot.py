@@ -0,0 +1,162 @@
+"""
+Time-boxed refresh of a fixed list of places.
+Skips search, refreshes the stalest places first with one browser per
+place up to CRAWLER_MAX_BROWSERS, stops starting new places when the time
+window closes and reports which places could not be refreshed in time.
+
+Usage:
+    python -m src.snapshot --file places.txt --minutes 30
+"""
+
+import argparse
+import json
+import logging
+import os
+import sys
+import threading
+import time
+from datetime import datetime
+from typing import Dict, List, Optional
+
+from src.catalog import PlacesCatalog
+from src.config.settings import settings
+from src.crawler.blocking import build_block_handler
+from src.crawler.browser_pool import BrowserPool
+from src.crawler.costs import RunCosts
+from src.crawler.google_maps_crawler import GoogleMapsScraper
+from src.crawler.scheduler import Scheduler
+from src.database.mongodb import MongoDBClient
+from src.main import (build_consent, build_fields, build_identity, build_locale, build_media_downloader,
+                      build_places_enricher, build_proxy_pool, build_selectors, build_storage, build_throttle,
+                      build_website_crawler, configure_logging, process_restaurant, scheduler_limits)
+from src.models.job_context import JobContext
+from src.storage.ids import build_id_strategy
+from src.worker import read_urls
+
+logger = logging.getLogger(__name__)
+
+def order_by_staleness(last_updated: Dict[str, Optional[datetime]]) -> List[str]:
+    """Return URLs never refreshed first, then oldest refresh first."""
+    return sorted(last_updated, key=lambda url: (last_updated[url] is not None, last_updated[url] or datetime.min))
+
+def refresh(urls: List[str], deadline: float, concurrency: int) -> Dict[str, List[str]]:
+    """Refresh `urls` in order until `deadline` (epoch seconds) and return them by outcome."""
+    storage = build_storage()
+    media = build_media_downloader()
+    website = build_website_crawler()
+    places = build_places_enricher()
+    job = JobContext(tenant=settings.tenant, proxy=settings.proxy)
+    proxy_pool = build_proxy_pool()
+    block_handler = build_block_handler(settings.block_handler, settings.captcha_solver_url)
+    throttle = build_throttle()
+    identity = build_identity()
+    locale = build_locale()
+    consent = build_consent()
+    fields = build_fields()
+    selectors = build_selectors()
+    concurrency, rate_limits = scheduler_limits(concurrency)
+    costs = RunCosts(
+        proxy_per_gb=settings.cost_proxy_per_gb,
+        captcha_per_solve=settings.cost_captcha_per_solve,
+        browser_per_hour=settings.cost_browser_per_hour
+    )
+    id_strategy = build_id_strategy(settings.id_strategy)
+    outcome = {'refreshed': [], 'failed': [], 'timed_out': []}
+    lock = threading.Lock()
+
+    def new_scraper() -> GoogleMapsScraper:
+        return GoogleMapsScraper(
+            debug=settings.headful,
+            devtools=settings.devtools,
+            job=job,
+            throttle=throttle,
+            costs=costs,
+            id_strategy=id_strategy,
+            proxy_pool=proxy_pool,
+            block_handler=block_handler,
+            stealth=settings.stealth,
+            identity=identity,
+            locale=locale,
+            consent=consent,
+            fields=fields,
+            selectors=selectors,
+            feed_snapshots=settings.feed_snapshots,
+            slow_mo=settings.slow_mo,
+            photo_size=settings.photo_size,
+            review_scroll_budget=settings.review_scroll_budget,
+            max_reviews=settings.max_reviews
+        )
+
+    with BrowserPool(new_scraper, size=concurrency, max_jobs=settings.browser_max_jobs,
+                     max_minutes=settings.browser_max_minutes) as pool, \
+            Scheduler(concurrency, rate_limits, throttle) as scheduler:
+
+        def refresh_url(url: str):
+            if time.time() >= deadline:
+                with lock:
+                    outcome['timed_out'].append(url)
+                return
+            with pool.browser() as scraper:
+                ok = process_restaurant(scraper, storage, url, media=media, website=website, places=places)
+            with lock:
+                outcome['refreshed' if ok else 'failed'].append(url)
+
+        for url in urls:
+            scheduler.submit(refresh_url, url)
+    storage.close()
+    os.makedirs(settings.output_dir, exist_ok=True)
+    costs.save(os.path.join(settings.output_dir, f"costs_{job.job_id}.json"),
+               extra={'job_id': job.job_id, 'tenant': job.tenant})
+    return outcome
+
+def main():
+    configure_logging()
+    parser = argparse.ArgumentParser(description='Refresh a fixed list of places within a time window.')
+    parser.add_argument('urls', nargs='*', help='Place URLs')
+    parser.add_argument('--file', help='File with one place URL per line')
+    parser.add_argument('--minutes', type=float, required=True, help='Time window for starting refreshes')
+    parser.add_argument('--report', help='JSON report path (default: output dir)')
+    args = parser.parse_args()
+
+    try:
+        started = time.time()
+        deadline = started + args.minutes * 60
+        urls = list(dict.fromkeys(read_urls(args)))
+        if not urls:
+            raise ValueError("No place URLs given")
+
+        mongodb = MongoDBClient(
+            mongodb_url=settings.MONGODB_URL,
+            db_name=settings.MONGODB_DB,
+            collection_restaurants=settings.MONGODB_COLLECTION_RESTAURANTS,
+            collection_reviews=settings.MONGODB_COLLECTION_REVIEWS
+        )
+        urls = order_by_staleness(PlacesCatalog(mongodb).last_updated(urls))
+        # One browser per place; the domain rate limits and the throttle keep this safe
+        concurrency = max(1, min(settings.max_browsers, len(urls)))
+        logger.info(f"Refreshing {len(urls)} places within {args.minutes} minutes using {concurrency} browsers")
+
+        outcome = refresh(urls, deadline, concurrency)
+        report = {
+            'window_minutes': args.minutes,
+            'elapsed_minutes': round((time.time() - started) / 60, 1),
+            'places': len(urls),
+            **{name: sorted(values) for name, values in outcome.items()},
+        }
+        path = args.report or os.path.join(settings.output_dir, f"snapshot_{datetime.now().strftime('%Y%m%d_%H%M%S')}.json")
+        with open(path, 'w', encoding='utf-8') as f:
+            json.dump(report, f, indent=2)
+
+        logger.info(f"Refreshed {len(outcome['refreshed'])} of {len(urls)} places, "
+                    f"{len(outcome['failed'])} failed, {len(outcome['timed_out'])} not started in time")
+        for url in outcome['timed_out']:
+            logger.warning(f"Not refreshed in time: {url}")
+        logger.info(f"Report written to {path}")
+        if outcome['failed'] or outcome['timed_out']:
+            sys.exit(2)
+    except Exception as e:
+        logger.error(f"Snapshot failed: {str(e)}")
+        sys.exit(1)
+
+if __name__ == "__main__":
+    main()
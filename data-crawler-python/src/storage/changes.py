@@ -0,0 +1,98 @@
+"""
+Change detection between two crawls of the same area.
+Compares places by ID and reports new places, places that disappeared
+or are now marked closed, and per-field changes of the places in both: rating and review count
+deltas, opening hours by day and a few contact fields.
+"""
+
+import json
+from pathlib import Path
+from typing import Dict, Iterable, List, Optional
+
+# Fields compared as plain values; the status itself changes with the time of day
+TRACKED_FIELDS = ['name', 'is_operational', 'phone', 'website', 'plus_code']
+
+def place_key(place: Dict) -> Optional[str]:
+    return place.get('_id') or place.get('cid') or place.get('url')
+
+def _review_count(place: Dict) -> Optional[int]:
+    count = place.get('total_reviews')
+    return count if count is not None else place.get('review_count')
+
+def _number_change(old, new) -> Optional[Dict]:
+    if old == new:
+        return None
+    delta = round(new - old, 2) if old is not None and new is not None else None
+    return {'old': old, 'new': new, 'delta': delta}
+
+def _hours_change(old: Dict, new: Dict) -> Optional[Dict]:
+    days = {day: {'old': old.get(day), 'new': new.get(day)}
+            for day in sorted(set(old) | set(new)) if old.get(day) != new.get(day)}
+    return days or None
+
+def place_changes(old: Dict, new: Dict) -> Dict:
+    """Return the changed fields of one place, e.g. {"rating": {"old": 4.5, "new": 4.6, "delta": 0.1}}."""
+    changes = {}
+    rating = _number_change(old.get('overall_rating'), new.get('overall_rating'))
+    if rating:
+        changes['rating'] = rating
+    review_count = _number_change(_review_count(old), _review_count(new))
+    if review_count:
+        changes['review_count'] = review_count
+    hours = _hours_change(old.get('opening_hours_raw') or {}, new.get('opening_hours_raw') or {})
+    if hours:
+        changes['hours'] = hours
+    for field in TRACKED_FIELDS:
+        if old.get(field) != new.get(field):
+            changes[field] = {'old': old.get(field), 'new': new.get(field)}
+    old_address = (old.get('location') or {}).get('address')
+    new_address = (new.get('location') or {}).get('address')
+    if old_address != new_address:
+        changes['address'] = {'old': old_address, 'new': new_address}
+    return changes
+
+def _summary(place: Dict) -> Dict:
+    return {'id': place_key(place), 'name': place.get('name'), 'url': place.get('url')}
+
+def diff_places(old_places: Iterable[Dict], new_places: Iterable[Dict]) -> Dict:
+    """Compare two crawls and return the new, removed, closed and changed places with counts."""
+    old = {place_key(p): p for p in old_places if place_key(p)}
+    new = {place_key(p): p for p in new_places if place_key(p)}
+    added = [_summary(new[key]) for key in new if key not in old]
+    removed = [_summary(old[key]) for key in old if key not in new]
+    closed, changed = [], []
+    for key in new:
+        if key in old:
+            if new[key].get('is_operational') is False and old[key].get('is_operational') is not False:
+                closed.append({**_summary(new[key]), 'status': new[key].get('status')})
+            changes = place_changes(old[key], new[key])
+            if changes:
+                changed.append({**_summary(new[key]), 'changes': changes})
+    return {
+        'counts': {'old': len(old), 'new': len(new), 'added': len(added), 'removed': len(removed),
+                   'closed': len(closed), 'changed': len(changed)},
+        'added': added,
+        'removed': removed,
+        'closed': closed,
+        'changed': changed,
+    }
+
+def load_places(path: str) -> List[Dict]:
+    """Load places from a JSON array, a JSON lines or GeoJSON file, or a directory of place JSON files."""
+    path = Path(path)
+    if path.is_dir():
+        places = []
+        for file in sorted(path.glob('*.json')):
+            with open(file, 'r', encoding='utf-8') as f:
+                places.append(json.load(f))
+        return places
+    with open(path, 'r', encoding='utf-8') as f:
+        if path.suffix == '.jsonl':
+            return [json.loads(line) for line in f if line.strip()]
+        data = json.load(f)
+    if isinstance(data, dict) and data.get('type') == 'FeatureCollection':
+        return [{**feature.get('properties', {}), '_id': feature.get('id')} for feature in data.get('features', [])]
+    # Exports may wrap the places, e.g. {"restaurants": [...]}
+    if isinstance(data, dict):
+        data = data.get('restaurants') or data.get('places') or []
+    return data
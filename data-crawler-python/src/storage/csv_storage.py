@@ -0,0 +1,83 @@
+"""
+CSV storage with a flattened restaurant schema.
+Nested fields are flattened so results open directly in a spreadsheet:
+location is split into columns, cuisines are joined, opening hours are
+serialized as JSON and reviews are omitted.
+"""
+
+import csv
+import json
+import logging
+from datetime import datetime
+from pathlib import Path
+from typing import List
+
+from .idempotency import idempotency_key
+
+logger = logging.getLogger(__name__)
+
+COLUMNS = [
+    '_id', 'cid', 'name', 'url', 'address', 'city', 'state', 'country', 'postal_code',
+    'lat', 'lng', 'phone', 'website', 'overall_rating', 'total_reviews',
+    'cuisine_type', 'price_level', 'price_min', 'price_max', 'price_currency', 'opening_hours',
+]
+
+def flatten_restaurant(restaurant: dict) -> dict:
+    """Flatten a restaurant document into a CSV row."""
+    location = restaurant.get('location') or {}
+    attributes = restaurant.get('attributes') or {}
+    coordinates = location.get('coordinates') or []
+    price = attributes.get('price_range') or {}
+    return {
+        '_id': restaurant.get('_id'),
+        'cid': restaurant.get('cid'),
+        'name': restaurant.get('name'),
+        'url': restaurant.get('url'),
+        'address': location.get('address'),
+        'city': location.get('city'),
+        'state': location.get('state'),
+        'country': location.get('country'),
+        'postal_code': location.get('postal_code'),
+        'lat': coordinates[1] if len(coordinates) == 2 else None,
+        'lng': coordinates[0] if len(coordinates) == 2 else None,
+        'phone': restaurant.get('phone'),
+        'website': restaurant.get('website'),
+        'overall_rating': restaurant.get('overall_rating'),
+        'total_reviews': restaurant.get('total_reviews'),
+        'cuisine_type': '; '.join(attributes.get('cuisine_type') or []),
+        'price_level': attributes.get('price_level'),
+        'price_min': price.get('min'),
+        'price_max': price.get('max'),
+        'price_currency': price.get('currency'),
+        'opening_hours': json.dumps(restaurant.get('opening_hours'), ensure_ascii=False) if restaurant.get('opening_hours') else None,
+    }
+
+class CsvStorage:
+    """Stream flattened restaurants to a CSV file."""
+
+    def __init__(self, base_dir: str = "data"):
+        """Create the CSV file for this run under base_dir."""
+        self.base_dir = Path(base_dir)
+        self.base_dir.mkdir(parents=True, exist_ok=True)
+        timestamp = datetime.now().strftime('%Y%m%d_%H%M%S')
+        self.restaurants_file = self.base_dir / f"restaurants_{timestamp}.csv"
+        with open(self.restaurants_file, 'w', encoding='utf-8', newline='') as f:
+            csv.DictWriter(f, fieldnames=COLUMNS).writeheader()
+        self._written = set()
+
+    def upsert_restaurant(self, restaurant_data: dict) -> str:
+        """Append a restaurant row to the CSV file."""
+        try:
+            key = idempotency_key(restaurant_data)
+            if key not in self._written:
+                with open(self.restaurants_file, 'a', encoding='utf-8', newline='') as f:
+                    csv.DictWriter(f, fieldnames=COLUMNS).writerow(flatten_restaurant(restaurant_data))
+                self._written.add(key)
+            return restaurant_data.get('_id') or restaurant_data.get('url')
+        except Exception as e:
+            logger.error(f"Error writing restaurant row: {str(e)}")
+            raise
+
+    def upsert_reviews(self, restaurant_id: str, reviews: List[dict]) -> None:
+        """Reviews are not part of the flattened schema."""
+        return None
@@ -0,0 +1,119 @@
+"""
+Deduplication of places across crawl runs.
+Keeps an index of every place seen so far, persisted to a JSON file next
+to the output. A new place is matched to a known one by CID, or, when the
+CID is missing, by normalized name within 50 m. A match reuses the known
+place's ID and is merged into the known fields, newest value winning, so
+every sink upserts one record per place; first_seen and last_seen record
+when the place was first and most recently crawled.
+"""
+
+import json
+import logging
+import os
+import threading
+from datetime import datetime
+from pathlib import Path
+from typing import Dict, Optional
+
+from ..catalog import normalize_name
+from ..enrichment.geo import haversine_m, lat_lng
+from .idempotency import extract_cid
+
+logger = logging.getLogger(__name__)
+
+MAX_MATCH_DISTANCE_M = 50
+# Merges between writes of the index
+SAVE_EVERY = 25
+
+def _empty(value) -> bool:
+    return value is None or value == '' or value == [] or value == {}
+
+def merge_newest(previous: Dict, latest: Dict) -> Dict:
+    """Merge two versions of a place; the latest value of a field wins unless it is empty."""
+    merged = dict(previous)
+    merged.update({key: value for key, value in latest.items() if not _empty(value)})
+    return merged
+
+class DedupeIndex:
+    """Places seen by earlier runs, matched by CID or by name and distance."""
+
+    def __init__(self, path: str, max_distance_m: float = MAX_MATCH_DISTANCE_M):
+        self.path = Path(path)
+        self.max_distance_m = max_distance_m
+        self.places: Dict[str, Dict] = {}
+        self._by_cid: Dict[str, str] = {}
+        self._by_name: Dict[str, list] = {}
+        self._unsaved = 0
+        # Places are saved on several scheduler threads
+        self._lock = threading.RLock()
+        if self.path.exists():
+            with open(self.path, 'r', encoding='utf-8') as f:
+                self.places = json.load(f)
+            for place_id, place in self.places.items():
+                self._add_keys(place_id, place)
+            logger.info(f"Loaded {len(self.places)} known places from {self.path}")
+
+    def _add_keys(self, place_id: str, place: Dict):
+        cid = place.get('cid') or extract_cid(place.get('url'))
+        if cid:
+            self._by_cid[str(cid)] = place_id
+        name = normalize_name(place.get('name') or '')
+        if name and place_id not in self._by_name.get(name, []):
+            self._by_name.setdefault(name, []).append(place_id)
+
+    def match(self, place: Dict) -> Optional[str]:
+        """Return the ID of the known place `place` is, if any."""
+        with self._lock:
+            cid = place.get('cid') or extract_cid(place.get('url'))
+            if cid:
+                return self._by_cid.get(str(cid))
+            point = lat_lng(place)
+            name = normalize_name(place.get('name') or '')
+            if not point or not name:
+                return None
+            for place_id in self._by_name.get(name, []):
+                known = lat_lng(self.places[place_id])
+                if known and haversine_m(*point, *known) < self.max_distance_m:
+                    return place_id
+            return None
+
+    def merge(self, place: Dict) -> Dict:
+        """Merge a freshly scraped place into its known version and return the document to save.
+
+        The returned document carries the known place's _id, or the new one
+        if the place has not been seen before, plus first_seen and last_seen.
+        """
+        now = datetime.now().isoformat()
+        with self._lock:
+            place_id = self.match(place)
+            previous = self.places.get(place_id) if place_id else None
+            if previous:
+                if place.get('_id') and place['_id'] != place_id:
+                    logger.info(f"Place {place.get('name')} ({place['_id']}) matches known place {place_id}")
+                merged = merge_newest(previous, place)
+                merged['_id'] = place_id
+                merged['first_seen'] = previous.get('first_seen') or now
+            else:
+                merged = dict(place)
+                place_id = merged.get('_id')
+                merged['first_seen'] = now
+            merged['last_seen'] = now
+            if place_id:
+                # Reviews are stored by the sinks, not in the index
+                self.places[place_id] = {key: value for key, value in merged.items() if key != 'reviews'}
+                self._add_keys(place_id, merged)
+                self._unsaved += 1
+                if self._unsaved >= SAVE_EVERY:
+                    self.save()
+            return merged
+
+    def save(self):
+        """Write the index atomically."""
+        with self._lock:
+            self.path.parent.mkdir(parents=True, exist_ok=True)
+            tmp_path = self.path.with_suffix('.tmp')
+            with open(tmp_path, 'w', encoding='utf-8') as f:
+                json.dump(self.places, f, ensure_ascii=False, default=str)
+            os.replace(tmp_path, self.path)
+            self._unsaved = 0
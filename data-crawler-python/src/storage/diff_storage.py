@@ -0,0 +1,63 @@
+"""
+Change detection sink.
+Keeps the places saved in this run and, when the sink closes, compares
+them with the places of the previous run and writes the new, removed and
+changed places to a changes file. The run's places then become the
+baseline of the next run. Removed places only mean something when every
+run covers the same area.
+"""
+
+import copy
+import json
+import logging
+import os
+from datetime import datetime
+from pathlib import Path
+from typing import Dict, List
+
+from .changes import diff_places, place_key
+
+logger = logging.getLogger(__name__)
+
+class DiffStorage:
+    """Compare the places of each run with the previous run's."""
+
+    def __init__(self, base_dir: str = "data"):
+        """Keep the baseline and changes files under base_dir/changes."""
+        self.changes_dir = Path(base_dir) / "changes"
+        self.changes_dir.mkdir(parents=True, exist_ok=True)
+        self.baseline_file = self.changes_dir / "baseline.json"
+        timestamp = datetime.now().strftime('%Y%m%d_%H%M%S')
+        self.changes_file = self.changes_dir / f"changes_{timestamp}.json"
+        self.places: Dict[str, Dict] = {}
+
+    def upsert_restaurant(self, restaurant_data: dict) -> str:
+        """Keep a restaurant for the comparison."""
+        place = copy.deepcopy(restaurant_data)
+        place.pop('reviews', None)
+        self.places[place_key(place)] = place
+        return place_key(place)
+
+    def upsert_reviews(self, restaurant_id: str, reviews: List[dict]) -> None:
+        """Reviews are not compared."""
+        return None
+
+    def close(self):
+        """Write the changes since the previous run and make this run the baseline."""
+        if not self.places:
+            logger.info("No places saved in this run, keeping the previous baseline")
+            return
+        previous = []
+        if self.baseline_file.exists():
+            with open(self.baseline_file, 'r', encoding='utf-8') as f:
+                previous = json.load(f)
+        places = list(self.places.values())
+        if previous:
+            changes = diff_places(previous, places)
+            with open(self.changes_file, 'w', encoding='utf-8') as f:
+                json.dump(changes, f, indent=2, ensure_ascii=False, default=str)
+            logger.info(f"Wrote changes since the previous run to {self.changes_file}: {changes['counts']}")
+        tmp_path = self.baseline_file.with_suffix('.tmp')
+        with open(tmp_path, 'w', encoding='utf-8') as f:
+            json.dump(places, f, ensure_ascii=False, default=str)
+        os.replace(tmp_path, self.baseline_file)
@@ -0,0 +1,105 @@
+"""
+Fan-out storage that writes every document to several sinks.
+A failure in one sink is logged and does not prevent the others from
+receiving the data. Writes are serialized, since place jobs save from
+several scheduler threads and the file, CSV and Parquet sinks keep
+unsynchronized buffers and ledgers. Redaction runs after the transform, so no sink can
+export a redacted field. With a dedupe index, places are merged with
+their versions from earlier runs before either.
+"""
+
+import copy
+import logging
+import threading
+from typing import Dict, List, Optional, Set
+
+from .dedupe import DedupeIndex
+from .redaction import RedactionPolicy
+from .transform import Transform
+
+logger = logging.getLogger(__name__)
+
+class FanOutStorage:
+    """Write restaurants and reviews to multiple storage backends."""
+
+    def __init__(self, sinks: Dict[str, object], transform: Optional[Transform] = None,
+                 redaction: Optional[RedactionPolicy] = None, dedupe: Optional[DedupeIndex] = None):
+        """Initialize with a mapping of sink name to storage backend, an optional transform, redaction policy
+        and dedupe index."""
+        self.sinks = sinks
+        self.transform = transform
+        self.redaction = redaction
+        self.dedupe = dedupe
+        self._lock = threading.Lock()
+
+    def _prepare(self, document: dict) -> dict:
+        if self.transform:
+            document = self.transform.apply(copy.deepcopy(document))
+        if self.redaction:
+            document = self.redaction.apply(copy.deepcopy(document))
+        return document
+
+    def upsert_restaurant(self, restaurant_data: dict) -> Dict[str, object]:
+        """Save restaurant data to every sink and return the successful results by sink name."""
+        if self.dedupe:
+            # Updated in place so the caller saves the reviews under the merged place's ID
+            merged = self.dedupe.merge(restaurant_data)
+            restaurant_data.clear()
+            restaurant_data.update(merged)
+        restaurant_data = self._prepare(restaurant_data)
+        results = {}
+        with self._lock:
+            for name, sink in self.sinks.items():
+                try:
+                    # Sinks may mutate the document, so each gets its own copy
+                    results[name] = sink.upsert_restaurant(copy.deepcopy(restaurant_data))
+                except Exception as e:
+                    logger.error(f"Sink '{name}' failed to save restaurant {restaurant_data.get('name')}: {str(e)}")
+        return results
+
+    def upsert_reviews(self, restaurant_id: str, reviews: List[dict]) -> Dict[str, object]:
+        """Save reviews to every sink and return the successful results by sink name."""
+        reviews = [self._prepare(review) for review in reviews]
+        results = {}
+        with self._lock:
+            for name, sink in self.sinks.items():
+                try:
+                    results[name] = sink.upsert_reviews(restaurant_id, copy.deepcopy(reviews))
+                except Exception as e:
+                    logger.error(f"Sink '{name}' failed to save reviews for {restaurant_id}: {str(e)}")
+        return results
+
+    def known_review_ids(self, url: str) -> Set[str]:
+        """Return the review IDs already stored for a place by the first sink that can tell."""
+        for name, sink in self.sinks.items():
+            if hasattr(sink, 'known_review_ids'):
+                try:
+                    return sink.known_review_ids(url)
+                except Exception as e:
+                    logger.error(f"Sink '{name}' failed to load known reviews for {url}: {str(e)}")
+        return set()
+
+    def popular_times_history(self, url: str) -> List[dict]:
+        """Return the stored popular times snapshots of a place from the first sink that keeps them."""
+        for name, sink in self.sinks.items():
+            if hasattr(sink, 'popular_times_history'):
+                try:
+                    return sink.popular_times_history(url)
+                except Exception as e:
+                    logger.error(f"Sink '{name}' failed to load popular times for {url}: {str(e)}")
+        return []
+
+    def close(self):
+        """Close every sink that holds buffered output and write the dedupe index."""
+        if self.dedupe:
+            try:
+                self.dedupe.save()
+            except Exception as e:
+                logger.error(f"Failed to save the dedupe index: {str(e)}")
+        with self._lock:
+            for name, sink in self.sinks.items():
+                if hasattr(sink, 'close'):
+                    try:
+                        sink.close()
+                    except Exception as e:
+                        logger.error(f"Sink '{name}' failed to close: {str(e)}")
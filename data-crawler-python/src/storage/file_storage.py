@@ -1,10 +1,14 @@
 import json
 import os
 import logging
-from datetime import datetime
+import re
+from collections import defaultdict
+from datetime import datetime, timedelta
 from typing import Dict, List, Optional
 from pathlib import Path
 
+from .idempotency import idempotency_key
+
 logger = logging.getLogger(__name__)
 
 class FileStorage:
@@ -15,7 +19,9 @@ class FileStorage:
         self.base_dir = Path(base_dir)
         self.restaurants_dir = self.base_dir / "restaurants"
         self.reviews_dir = self.base_dir / "reviews"
+        self.ledger_file = self.base_dir / "idempotency_keys.jsonl"
         self._create_directories()
+        self._written = self._load_ledger()
         
     def _create_directories(self):
         """Create necessary directories if they don't exist."""
@@ -23,6 +29,25 @@ class FileStorage:
         self.restaurants_dir.mkdir(exist_ok=True)
         self.reviews_dir.mkdir(exist_ok=True)
         
+    def _load_ledger(self) -> Dict[str, str]:
+        """Load previously written idempotency keys and their filenames."""
+        written = {}
+        if self.ledger_file.exists():
+            with open(self.ledger_file, 'r', encoding='utf-8') as f:
+                for line in f:
+                    line = line.strip()
+                    if not line:
+                        continue
+                    entry = json.loads(line)
+                    written[entry['key']] = entry['filename']
+        return written
+
+    def _record_key(self, key: str, filename: str):
+        """Append a written idempotency key to the ledger."""
+        with open(self.ledger_file, 'a', encoding='utf-8') as f:
+            f.write(json.dumps({'key': key, 'filename': filename}) + '\n')
+        self._written[key] = filename
+
     def _sanitize_filename(self, name: str) -> str:
         """Convert restaurant name to a valid filename."""
         return "".join(c for c in name if c.isalnum() or c in (' ', '-', '_')).rstrip()
@@ -30,6 +55,12 @@ class FileStorage:
     def upsert_restaurant(self, restaurant_data: dict) -> str:
         """Save restaurant data to a JSON file."""
         try:
+            # Skip documents already written by a previous attempt
+            key = idempotency_key(restaurant_data)
+            if key in self._written:
+                logger.info(f"Skipping duplicate write for key {key}")
+                return self._written[key]
+
             # Generate a filename from the restaurant name
             name = restaurant_data.get('name', 'unknown')
             sanitized_name = self._sanitize_filename(name)
@@ -50,6 +81,7 @@ class FileStorage:
                 with open(reviews_file, 'w', encoding='utf-8') as f:
                     json.dump(reviews, f, indent=2, ensure_ascii=False)
             
+            self._record_key(key, filename)
             logger.info(f"Saved restaurant data to {filename}")
             return filename
             
@@ -57,6 +89,27 @@ class FileStorage:
             logger.error(f"Error saving restaurant data: {str(e)}")
             raise
     
+    def upsert_reviews(self, restaurant_id: str, reviews: List[dict]) -> Optional[str]:
+        """Save the reviews of a restaurant to a JSON file."""
+        try:
+            key = idempotency_key({'_id': restaurant_id, 'reviews': reviews})
+            if key in self._written:
+                logger.info(f"Skipping duplicate write for key {key}")
+                return self._written[key]
+
+            timestamp = datetime.now().strftime('%Y%m%d_%H%M%S')
+            filename = f"{self._sanitize_filename(restaurant_id)}_{timestamp}_reviews.json"
+            with open(self.reviews_dir / filename, 'w', encoding='utf-8') as f:
+                json.dump(reviews, f, indent=2, ensure_ascii=False)
+
+            self._record_key(key, filename)
+            logger.info(f"Saved {len(reviews)} reviews to {filename}")
+            return filename
+
+        except Exception as e:
+            logger.error(f"Error saving reviews: {str(e)}")
+            raise
+
     def get_restaurant(self, filename: str) -> Optional[Dict]:
         """Get restaurant data from a file."""
         try:
@@ -86,4 +139,41 @@ class FileStorage:
             restaurant_data = self.get_restaurant(file_path.name)
             if restaurant_data:
                 restaurants.append(restaurant_data)
-        return restaurants 
\ No newline at end of file
+        return restaurants 
+
+    def prune(self, keep_days: Optional[int] = None, keep_runs: Optional[int] = None) -> int:
+        """Delete old timestamped output files and return how many were removed.
+
+        keep_days removes files written more than that many days ago;
+        keep_runs keeps only the newest N files for each restaurant.
+        """
+        removed = set()
+        cutoff = datetime.now() - timedelta(days=keep_days) if keep_days is not None else None
+
+        for directory in (self.restaurants_dir, self.reviews_dir):
+            runs = defaultdict(list)
+            for file_path in directory.glob('*.json'):
+                match = re.match(r'^(.*)_(\d{8}_\d{6})(_reviews)?\.json$', file_path.name)
+                if not match:
+                    continue
+                written_at = datetime.strptime(match.group(2), '%Y%m%d_%H%M%S')
+                runs[match.group(1)].append((written_at, file_path))
+
+            for files in runs.values():
+                files.sort(reverse=True)
+                for index, (written_at, file_path) in enumerate(files):
+                    expired = cutoff is not None and written_at < cutoff
+                    surplus = keep_runs is not None and index >= keep_runs
+                    if expired or surplus:
+                        file_path.unlink()
+                        removed.add(file_path.name)
+
+        if removed:
+            # Forget pruned files so a later crawl can write them again
+            self._written = {k: v for k, v in self._written.items() if v not in removed}
+            with open(self.ledger_file, 'w', encoding='utf-8') as f:
+                for key, filename in self._written.items():
+                    f.write(json.dumps({'key': key, 'filename': filename}) + '\n')
+
+        logger.info(f"Pruned {len(removed)} output files")
+        return len(removed)
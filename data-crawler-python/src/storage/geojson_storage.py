@@ -0,0 +1,73 @@
+"""
+GeoJSON storage for coverage maps.
+Every restaurant with coordinates becomes a Point feature whose properties
+are the rest of the document, so the file opens directly in Mapbox,
+Leaflet or QGIS. A place saved again replaces its feature, so each place
+appears once; the FeatureCollection is written when the sink closes.
+"""
+
+import copy
+import json
+import logging
+from datetime import datetime
+from pathlib import Path
+from typing import Dict, List, Optional
+
+from .changes import place_key
+
+logger = logging.getLogger(__name__)
+
+def to_feature(restaurant: dict) -> Optional[Dict]:
+    """Convert a restaurant into a GeoJSON Point feature, or None without coordinates."""
+    location = restaurant.get('location') or {}
+    coordinates = location.get('coordinates') or []
+    if len(coordinates) != 2:
+        return None
+    properties = copy.deepcopy(restaurant)
+    properties.pop('reviews', None)
+    properties_location = properties.get('location') or {}
+    properties_location.pop('coordinates', None)
+    properties_location.pop('type', None)
+    return {
+        'type': 'Feature',
+        'id': restaurant.get('_id'),
+        'geometry': {'type': 'Point', 'coordinates': [coordinates[0], coordinates[1]]},
+        'properties': properties,
+    }
+
+class GeoJsonStorage:
+    """Collect restaurants as GeoJSON features and write a FeatureCollection."""
+
+    def __init__(self, base_dir: str = "data"):
+        """Choose the output file for this run under base_dir."""
+        self.base_dir = Path(base_dir)
+        self.base_dir.mkdir(parents=True, exist_ok=True)
+        timestamp = datetime.now().strftime('%Y%m%d_%H%M%S')
+        self.restaurants_file = self.base_dir / f"restaurants_{timestamp}.geojson"
+        self.features: Dict[str, Dict] = {}
+        self.skipped = 0
+
+    def upsert_restaurant(self, restaurant_data: dict) -> Optional[str]:
+        """Add or replace the feature of a restaurant and return its place key."""
+        key = place_key(restaurant_data)
+        feature = to_feature(restaurant_data)
+        if not feature:
+            # Skipped on purpose, so the place still counts as saved
+            self.skipped += 1
+            logger.debug(f"Restaurant {restaurant_data.get('name')} has no coordinates, not added to GeoJSON")
+            return key
+        # Keyed by place, not content, so a re-crawl with changed fields replaces the feature
+        self.features[key] = feature
+        return key
+
+    def upsert_reviews(self, restaurant_id: str, reviews: List[dict]) -> None:
+        """Reviews are not part of the map output."""
+        return None
+
+    def close(self):
+        """Write the FeatureCollection."""
+        collection = {'type': 'FeatureCollection', 'features': list(self.features.values())}
+        with open(self.restaurants_file, 'w', encoding='utf-8') as f:
+            json.dump(collection, f, ensure_ascii=False, default=str)
+        logger.info(f"Wrote {len(self.features)} places to {self.restaurants_file}"
+                    f" ({self.skipped} without coordinates skipped)")
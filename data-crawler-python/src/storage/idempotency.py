@@ -0,0 +1,59 @@
+"""
+Idempotency helpers shared by storage backends.
+Keys combine the Google Maps CID with a hash of the document content so a
+retried job never writes the same place or review twice.
+"""
+
+import hashlib
+import json
+import re
+from typing import Optional
+
+# Fields that change between otherwise identical writes and must not
+# influence the content hash, at any depth, e.g. the job_id of every review
+# or the capture time of the popular times chart.
+VOLATILE_FIELDS = ('_id', 'idempotency_key', 'scraped_at', 'updated_at', 'job', 'job_id', 'layout_fingerprint',
+                   'captured_at', 'first_seen', 'last_seen')
+
+def extract_cid(url: Optional[str]) -> Optional[str]:
+    """Extract the decimal CID from a Google Maps place URL."""
+    if not url:
+        return None
+    match = re.search(r'!1s0x[0-9a-fA-F]+:0x([0-9a-fA-F]+)', url)
+    if match:
+        return str(int(match.group(1), 16))
+    match = re.search(r'[?&]cid=(\d+)', url)
+    if match:
+        return match.group(1)
+    return None
+
+def extract_place_id(url: Optional[str]) -> Optional[str]:
+    """Extract the Places API place_id (ChIJ...) from a Google Maps place URL."""
+    if not url:
+        return None
+    match = re.search(r'!19s(ChIJ[\w-]+)', url)
+    if match:
+        return match.group(1)
+    match = re.search(r'[?&]query_place_id=(ChIJ[\w-]+)', url)
+    if match:
+        return match.group(1)
+    return None
+
+def strip_volatile(value):
+    """Drop the volatile fields of a document and of the documents nested in it."""
+    if isinstance(value, dict):
+        return {k: strip_volatile(v) for k, v in value.items() if k not in VOLATILE_FIELDS}
+    if isinstance(value, list):
+        return [strip_volatile(v) for v in value]
+    return value
+
+def content_hash(document: dict) -> str:
+    """Return a stable SHA-256 hash of a document, ignoring volatile fields."""
+    stable = strip_volatile(document)
+    payload = json.dumps(stable, sort_keys=True, ensure_ascii=False, default=str)
+    return hashlib.sha256(payload.encode('utf-8')).hexdigest()
+
+def idempotency_key(document: dict) -> str:
+    """Build the idempotency key (CID + content hash) for a document."""
+    identity = extract_cid(document.get('url')) or document.get('_id') or 'unknown'
+    return f"{identity}:{content_hash(document)}"
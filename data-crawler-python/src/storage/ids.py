@@ -0,0 +1,73 @@
+"""
+Place ID strategies.
+The stable strategy keys a place by its Google CID, then its Places API
+place_id, then its normalized name plus a geohash of its coordinates, so
+the ID survives Google reformatting an address. The legacy strategy keeps
+the original name + postal code IDs for existing deployments.
+"""
+
+import re
+from typing import Dict, Optional
+
+from ..enrichment.geo import geohash, lat_lng
+from .idempotency import extract_cid, extract_place_id
+
+class IdStrategy:
+    """Interface for place ID strategies."""
+
+    name = 'base'
+
+    def place_id(self, place: Dict) -> Optional[str]:
+        """Return the ID of a parsed place, or None if it cannot be identified."""
+        raise NotImplementedError
+
+def _slug(name: str) -> str:
+    clean_name = re.sub(r'[^a-zA-Z0-9\s]', '', name.lower()).strip()
+    return re.sub(r'\s+', '_', clean_name)
+
+class LegacyIdStrategy(IdStrategy):
+    """Name + postal code, as produced by earlier crawler versions."""
+
+    name = 'legacy'
+
+    def place_id(self, place: Dict) -> Optional[str]:
+        if not place.get('name'):
+            return None
+        clean_name = _slug(place['name'])
+        postal_code = (place.get('location') or {}).get('postal_code')
+        if postal_code:
+            return f"{clean_name}_{re.sub(r'[^a-zA-Z0-9]', '', postal_code)}"
+        return clean_name
+
+class StableIdStrategy(IdStrategy):
+    """CID, then place_id, then normalized name + geohash."""
+
+    name = 'stable'
+
+    def __init__(self, geohash_precision: int = 7):
+        self.geohash_precision = geohash_precision
+
+    def place_id(self, place: Dict) -> Optional[str]:
+        cid = place.get('cid') or extract_cid(place.get('url'))
+        if cid:
+            return f"cid_{cid}"
+        google_place_id = place.get('place_id') or extract_place_id(place.get('url'))
+        if google_place_id:
+            return f"pid_{google_place_id}"
+        if not place.get('name'):
+            return None
+        point = lat_lng(place)
+        if point:
+            return f"geo_{_slug(place['name'])}_{geohash(*point, self.geohash_precision)}"
+        return LegacyIdStrategy().place_id(place)
+
+ID_STRATEGIES = {
+    LegacyIdStrategy.name: LegacyIdStrategy,
+    StableIdStrategy.name: StableIdStrategy,
+}
+
+def build_id_strategy(name: str) -> IdStrategy:
+    """Create an ID strategy by name: stable or legacy."""
+    if name not in ID_STRATEGIES:
+        raise ValueError(f"Unknown ID strategy: {name}")
+    return ID_STRATEGIES[name]()
@@ -0,0 +1,60 @@
+"""
+Line-delimited JSON storage.
+Each restaurant and review is appended to the run's output file as soon
+as it is saved, so large crawls are never buffered in memory.
+"""
+
+import json
+import logging
+from datetime import datetime
+from pathlib import Path
+from typing import List, Optional
+
+from .idempotency import idempotency_key
+
+logger = logging.getLogger(__name__)
+
+class JsonlStorage:
+    """Stream restaurants and reviews to JSON lines files."""
+
+    def __init__(self, base_dir: str = "data"):
+        """Open the output files for this run under base_dir."""
+        self.base_dir = Path(base_dir)
+        self.base_dir.mkdir(parents=True, exist_ok=True)
+        timestamp = datetime.now().strftime('%Y%m%d_%H%M%S')
+        self.restaurants_file = self.base_dir / f"restaurants_{timestamp}.jsonl"
+        self.reviews_file = self.base_dir / f"reviews_{timestamp}.jsonl"
+        self._written = set()
+
+    def _append(self, path: Path, document: dict) -> bool:
+        """Append a document unless it was already written in this run."""
+        key = idempotency_key(document)
+        if key in self._written:
+            logger.info(f"Skipping duplicate write for key {key}")
+            return False
+        with open(path, 'a', encoding='utf-8') as f:
+            f.write(json.dumps(document, ensure_ascii=False, default=str) + '\n')
+        self._written.add(key)
+        return True
+
+    def upsert_restaurant(self, restaurant_data: dict) -> str:
+        """Append a restaurant to the restaurants file."""
+        try:
+            self._append(self.restaurants_file, restaurant_data)
+            return restaurant_data.get('_id') or restaurant_data.get('url')
+        except Exception as e:
+            logger.error(f"Error writing restaurant data: {str(e)}")
+            raise
+
+    def upsert_reviews(self, restaurant_id: str, reviews: List[dict]) -> Optional[int]:
+        """Append reviews to the reviews file and return how many were written."""
+        try:
+            written = 0
+            for review in reviews:
+                if self._append(self.reviews_file, {**review, 'restaurant_id': restaurant_id}):
+                    written += 1
+            logger.info(f"Wrote {written} reviews to {self.reviews_file.name}")
+            return written
+        except Exception as e:
+            logger.error(f"Error writing reviews: {str(e)}")
+            raise
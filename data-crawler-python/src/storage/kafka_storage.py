@@ -0,0 +1,70 @@
+"""
+Kafka publishing of scraped places.
+Each place is published as soon as it is saved, keyed by its CID, so
+downstream enrichment services can consume crawl results in real time.
+Reviews go to their own topic when one is configured.
+"""
+
+import json
+import logging
+from typing import List, Optional
+
+from kafka import KafkaProducer
+
+from .idempotency import idempotency_key
+
+logger = logging.getLogger(__name__)
+
+class KafkaStorage:
+    """Publish places and reviews to Kafka topics."""
+
+    def __init__(self, bootstrap_servers: str, places_topic: str, reviews_topic: Optional[str] = None):
+        """Create the producer for the given brokers and topics."""
+        self.places_topic = places_topic
+        self.reviews_topic = reviews_topic
+        self.producer = KafkaProducer(
+            bootstrap_servers=bootstrap_servers.split(','),
+            key_serializer=lambda k: k.encode('utf-8'),
+            value_serializer=lambda v: json.dumps(v, ensure_ascii=False, default=str).encode('utf-8'),
+            acks='all'
+        )
+
+    def upsert_restaurant(self, restaurant_data: dict) -> str:
+        """Publish a place keyed by its CID."""
+        try:
+            key = restaurant_data.get('cid') or restaurant_data.get('_id')
+            # Consumers use the idempotency header to drop replays of retried jobs
+            self.producer.send(
+                self.places_topic,
+                key=key,
+                value=restaurant_data,
+                headers=[('idempotency_key', idempotency_key(restaurant_data).encode('utf-8'))]
+            )
+            logger.info(f"Published place {key} to {self.places_topic}")
+            return key
+        except Exception as e:
+            logger.error(f"Error publishing place: {str(e)}")
+            raise
+
+    def upsert_reviews(self, restaurant_id: str, reviews: List[dict]) -> int:
+        """Publish each review to the reviews topic, if configured."""
+        if not self.reviews_topic:
+            return 0
+        try:
+            for review in reviews:
+                self.producer.send(
+                    self.reviews_topic,
+                    key=review.get('_id') or restaurant_id,
+                    value={**review, 'restaurant_id': restaurant_id},
+                    headers=[('idempotency_key', idempotency_key(review).encode('utf-8'))]
+                )
+            logger.info(f"Published {len(reviews)} reviews to {self.reviews_topic}")
+            return len(reviews)
+        except Exception as e:
+            logger.error(f"Error publishing reviews: {str(e)}")
+            raise
+
+    def close(self):
+        """Flush pending messages and close the producer."""
+        self.producer.flush()
+        self.producer.close()
@@ -0,0 +1,103 @@
+"""
+Media pipeline for review photos and place thumbnails.
+Images are downloaded concurrently and stored under their SHA-256 so the
+same photo is kept once however many places or reviews reference it. The
+local path (or S3 URI) and hash are recorded next to the photo URL.
+"""
+
+import hashlib
+import logging
+import mimetypes
+import os
+from concurrent.futures import ThreadPoolExecutor
+from pathlib import Path
+from typing import Dict, List, Optional
+
+import requests
+
+logger = logging.getLogger(__name__)
+
+class MediaStore:
+    """Interface for content-addressed media stores."""
+
+    def put(self, digest: str, extension: str, content: bytes) -> str:
+        """Store the content under its digest and return where it is."""
+        raise NotImplementedError
+
+def _content_key(digest: str, extension: str) -> str:
+    return f"{digest[:2]}/{digest[2:4]}/{digest}{extension}"
+
+class LocalMediaStore(MediaStore):
+    """Store media files under base_dir/ab/cd/<sha256>.<ext>."""
+
+    def __init__(self, base_dir: str):
+        self.base_dir = Path(base_dir)
+
+    def put(self, digest: str, extension: str, content: bytes) -> str:
+        path = self.base_dir / _content_key(digest, extension)
+        if not path.exists():
+            path.parent.mkdir(parents=True, exist_ok=True)
+            tmp_path = path.with_suffix(path.suffix + '.tmp')
+            tmp_path.write_bytes(content)
+            os.replace(tmp_path, path)
+        return str(path)
+
+class S3MediaStore(MediaStore):
+    """Store media objects in an S3 bucket under prefix/ab/cd/<sha256>.<ext>."""
+
+    def __init__(self, bucket: str, prefix: str = 'media'):
+        import boto3
+        self.s3 = boto3.client('s3')
+        self.bucket = bucket
+        self.prefix = prefix.strip('/')
+
+    def put(self, digest: str, extension: str, content: bytes) -> str:
+        key = f"{self.prefix}/{_content_key(digest, extension)}" if self.prefix else _content_key(digest, extension)
+        self.s3.put_object(Bucket=self.bucket, Key=key, Body=content)
+        return f"s3://{self.bucket}/{key}"
+
+class MediaDownloader:
+    """Download the photos of a place and its reviews into a media store."""
+
+    def __init__(self, store: MediaStore, workers: int = 8, timeout: float = 20):
+        self.store = store
+        self.workers = workers
+        self.timeout = timeout
+
+    def download(self, url: str) -> Optional[Dict]:
+        """Fetch one image and return {url, sha256, path, bytes}, or None on failure."""
+        try:
+            response = requests.get(url, timeout=self.timeout)
+            response.raise_for_status()
+            content = response.content
+            digest = hashlib.sha256(content).hexdigest()
+            content_type = response.headers.get('Content-Type', '').split(';')[0]
+            extension = mimetypes.guess_extension(content_type) or '.jpg'
+            return {'url': url, 'sha256': digest, 'path': self.store.put(digest, extension, content), 'bytes': len(content)}
+        except Exception as e:
+            logger.error(f"Could not download photo {url}: {str(e)}")
+            return None
+
+    def fetch(self, restaurant: Dict, reviews: List[Dict]) -> int:
+        """Download the place thumbnail and review photos, recording them as `thumbnail_media`
+        and `photo_media`; returns the number of images stored."""
+        urls = set()
+        if restaurant.get('thumbnail'):
+            urls.add(restaurant['thumbnail'])
+        for review in reviews:
+            urls.update(review.get('photos') or [])
+        if not urls:
+            return 0
+
+        with ThreadPoolExecutor(max_workers=self.workers) as executor:
+            results = dict(zip(urls, executor.map(self.download, urls)))
+
+        if results.get(restaurant.get('thumbnail')):
+            restaurant['thumbnail_media'] = results[restaurant['thumbnail']]
+        for review in reviews:
+            media = [results[url] for url in review.get('photos') or [] if results.get(url)]
+            if media:
+                review['photo_media'] = media
+        stored = sum(1 for result in results.values() if result)
+        logger.info(f"Stored {stored} of {len(urls)} photos for {restaurant.get('name')}")
+        return stored
@@ -0,0 +1,132 @@
+"""
+Parquet storage for analytics pipelines.
+Restaurants are written with a stable column schema, and their reviews
+are nested as a list column. Rows are buffered and flushed in row groups
+once complete, i.e. once their reviews were attached; place jobs run
+concurrently, so other restaurants arrive between a restaurant and its
+reviews. Call close() at the end of the run to flush the remainder.
+"""
+
+import logging
+from datetime import datetime
+from pathlib import Path
+from typing import Dict, List, Set
+
+import pyarrow as pa
+import pyarrow.parquet as pq
+
+from .csv_storage import flatten_restaurant
+
+logger = logging.getLogger(__name__)
+
+ROW_GROUP_SIZE = 500
+# Places saved without reviews never get their reviews attached; once this many
+# later places arrived, no running job can still be saving reviews for them
+REVIEW_WINDOW = 500
+
+REVIEW_TYPE = pa.struct([
+    ('id', pa.string()),
+    ('text', pa.string()),
+    ('date', pa.string()),
+    ('rating', pa.float64()),
+    ('reviewer_name', pa.string()),
+])
+
+SCHEMA = pa.schema([
+    ('_id', pa.string()),
+    ('cid', pa.string()),
+    ('name', pa.string()),
+    ('url', pa.string()),
+    ('address', pa.string()),
+    ('city', pa.string()),
+    ('state', pa.string()),
+    ('country', pa.string()),
+    ('postal_code', pa.string()),
+    ('lat', pa.float64()),
+    ('lng', pa.float64()),
+    ('phone', pa.string()),
+    ('website', pa.string()),
+    ('overall_rating', pa.float64()),
+    ('total_reviews', pa.int64()),
+    ('cuisine_type', pa.list_(pa.string())),
+    ('price_level', pa.int64()),
+    ('price_min', pa.float64()),
+    ('price_max', pa.float64()),
+    ('price_currency', pa.string()),
+    ('reviews', pa.list_(REVIEW_TYPE)),
+])
+
+def _review_row(review: dict) -> dict:
+    return {
+        'id': review.get('_id'),
+        'text': review.get('text'),
+        'date': review.get('date'),
+        'rating': float(review['rating']) if review.get('rating') is not None else None,
+        'reviewer_name': (review.get('reviewer') or {}).get('name'),
+    }
+
+class ParquetStorage:
+    """Write restaurants with nested reviews to a Parquet file."""
+
+    def __init__(self, base_dir: str = "data"):
+        """Create the Parquet file for this run under base_dir."""
+        self.base_dir = Path(base_dir)
+        self.base_dir.mkdir(parents=True, exist_ok=True)
+        timestamp = datetime.now().strftime('%Y%m%d_%H%M%S')
+        self.restaurants_file = self.base_dir / f"restaurants_{timestamp}.parquet"
+        self.writer = pq.ParquetWriter(str(self.restaurants_file), SCHEMA)
+        self.pending: Dict[str, dict] = {}
+        # Arrival number of every buffered row, and the rows whose reviews were attached
+        self.arrivals: Dict[str, int] = {}
+        self.complete: Set[str] = set()
+        self.arrived = 0
+
+    def upsert_restaurant(self, restaurant_data: dict) -> str:
+        """Buffer a restaurant row until its reviews arrive."""
+        row = flatten_restaurant(restaurant_data)
+        row.pop('opening_hours')
+        row['cuisine_type'] = (restaurant_data.get('attributes') or {}).get('cuisine_type') or []
+        row['reviews'] = []
+        restaurant_id = row['_id'] or row['url']
+        self.arrived += 1
+        self.pending[restaurant_id] = row
+        self.arrivals[restaurant_id] = self.arrived
+        self.complete.discard(restaurant_id)
+        for other, arrival in self.arrivals.items():
+            if self.arrived - arrival >= REVIEW_WINDOW:
+                self.complete.add(other)
+        self._flush_complete()
+        return restaurant_id
+
+    def upsert_reviews(self, restaurant_id: str, reviews: List[dict]) -> int:
+        """Attach reviews to a buffered restaurant row and mark it complete."""
+        row = self.pending.get(restaurant_id)
+        if row is None:
+            logger.warning(f"Reviews for {restaurant_id} arrived after its row was flushed, dropping them")
+            return 0
+        row['reviews'].extend(_review_row(review) for review in reviews)
+        self.complete.add(restaurant_id)
+        self._flush_complete()
+        return len(reviews)
+
+    def _flush_complete(self):
+        if len(self.complete) >= ROW_GROUP_SIZE:
+            self.flush(complete_only=True)
+
+    def flush(self, complete_only: bool = False):
+        """Write buffered rows as a row group; only the complete ones with `complete_only`."""
+        ids = [i for i in self.pending if i in self.complete] if complete_only else list(self.pending)
+        if not ids:
+            return
+        table = pa.Table.from_pylist([self.pending[i] for i in ids], schema=SCHEMA)
+        self.writer.write_table(table)
+        logger.info(f"Wrote {len(ids)} rows to {self.restaurants_file.name}")
+        for i in ids:
+            del self.pending[i]
+            del self.arrivals[i]
+            self.complete.discard(i)
+
+    def close(self):
+        """Flush remaining rows and finalize the file."""
+        self.flush()
+        self.writer.close()
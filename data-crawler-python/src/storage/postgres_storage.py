@@ -0,0 +1,133 @@
+"""
+PostgreSQL storage.
+Places are upserted keyed on their Google Maps CID (falling back to the
+generated _id when the URL has none), and reviews live in a child table,
+so repeated crawls update existing rows instead of adding new ones.
+"""
+
+import json
+import logging
+from typing import Dict, List
+
+import psycopg2
+from psycopg2.extras import Json, execute_values
+
+from .csv_storage import flatten_restaurant
+from .idempotency import idempotency_key
+
+logger = logging.getLogger(__name__)
+
+SCHEMA_SQL = """
+CREATE TABLE IF NOT EXISTS places (
+    place_key       TEXT PRIMARY KEY,
+    cid             TEXT,
+    name            TEXT,
+    url             TEXT,
+    address         TEXT,
+    city            TEXT,
+    lat             DOUBLE PRECISION,
+    lng             DOUBLE PRECISION,
+    phone           TEXT,
+    website         TEXT,
+    overall_rating  DOUBLE PRECISION,
+    total_reviews   INTEGER,
+    idempotency_key TEXT,
+    data            JSONB NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE TABLE IF NOT EXISTS reviews (
+    review_id       TEXT PRIMARY KEY,
+    place_key       TEXT NOT NULL REFERENCES places (place_key) ON DELETE CASCADE,
+    rating          DOUBLE PRECISION,
+    text            TEXT,
+    date            TEXT,
+    reviewer_name   TEXT,
+    data            JSONB NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS reviews_place_key_idx ON reviews (place_key);
+"""
+
+UPSERT_PLACE_SQL = """
+INSERT INTO places (place_key, cid, name, url, address, city, lat, lng, phone, website,
+                    overall_rating, total_reviews, idempotency_key, data)
+VALUES (%(place_key)s, %(cid)s, %(name)s, %(url)s, %(address)s, %(city)s, %(lat)s, %(lng)s,
+        %(phone)s, %(website)s, %(overall_rating)s, %(total_reviews)s, %(idempotency_key)s, %(data)s)
+ON CONFLICT (place_key) DO UPDATE SET
+    cid = EXCLUDED.cid, name = EXCLUDED.name, url = EXCLUDED.url, address = EXCLUDED.address,
+    city = EXCLUDED.city, lat = EXCLUDED.lat, lng = EXCLUDED.lng, phone = EXCLUDED.phone,
+    website = EXCLUDED.website, overall_rating = EXCLUDED.overall_rating,
+    total_reviews = EXCLUDED.total_reviews, idempotency_key = EXCLUDED.idempotency_key,
+    data = EXCLUDED.data, updated_at = now()
+WHERE places.idempotency_key IS DISTINCT FROM EXCLUDED.idempotency_key
+"""
+
+UPSERT_REVIEWS_SQL = """
+INSERT INTO reviews (review_id, place_key, rating, text, date, reviewer_name, data)
+VALUES %s
+ON CONFLICT (review_id) DO UPDATE SET
+    rating = EXCLUDED.rating, text = EXCLUDED.text, date = EXCLUDED.date,
+    reviewer_name = EXCLUDED.reviewer_name, data = EXCLUDED.data, updated_at = now()
+"""
+
+class PostgresStorage:
+    """Upsert places and reviews into PostgreSQL."""
+
+    def __init__(self, dsn: str):
+        """Connect and create the tables if they don't exist."""
+        self.conn = psycopg2.connect(dsn)
+        with self.conn, self.conn.cursor() as cur:
+            cur.execute(SCHEMA_SQL)
+        # Reviews are saved by restaurant _id, which may differ from the place key
+        self._place_keys: Dict[str, str] = {}
+
+    def upsert_restaurant(self, restaurant_data: dict) -> str:
+        """Insert or update a place row keyed on its CID."""
+        try:
+            row = flatten_restaurant(restaurant_data)
+            place_key = row['cid'] or row['_id']
+            if not place_key:
+                raise ValueError("Restaurant data must include a CID or _id")
+            row.update({
+                'place_key': place_key,
+                'idempotency_key': idempotency_key(restaurant_data),
+                'data': Json(restaurant_data, dumps=lambda o: json.dumps(o, default=str)),
+            })
+            with self.conn, self.conn.cursor() as cur:
+                cur.execute(UPSERT_PLACE_SQL, row)
+            if row['_id']:
+                self._place_keys[row['_id']] = place_key
+            logger.info(f"Upserted place {place_key}")
+            return place_key
+        except Exception as e:
+            logger.error(f"Error upserting place: {str(e)}")
+            raise
+
+    def upsert_reviews(self, restaurant_id: str, reviews: List[dict]) -> int:
+        """Insert or update the reviews of a place."""
+        try:
+            place_key = self._place_keys.get(restaurant_id, restaurant_id)
+            rows = [
+                (
+                    review['_id'],
+                    place_key,
+                    review.get('rating'),
+                    review.get('text'),
+                    review.get('date'),
+                    (review.get('reviewer') or {}).get('name'),
+                    Json(review, dumps=lambda o: json.dumps(o, default=str)),
+                )
+                for review in reviews if review.get('_id')
+            ]
+            if rows:
+                with self.conn, self.conn.cursor() as cur:
+                    execute_values(cur, UPSERT_REVIEWS_SQL, rows)
+            logger.info(f"Upserted {len(rows)} reviews for place {place_key}")
+            return len(rows)
+        except Exception as e:
+            logger.error(f"Error upserting reviews: {str(e)}")
+            raise
+
+    def close(self):
+        """Close the database connection."""
+        self.conn.close()
@@ -0,0 +1,50 @@
+"""
+Field-level redaction enforced before documents reach any sink.
+Fields are addressed with dotted paths like the transform stage; a path
+that crosses a list applies to every item, e.g. "reviews.reviewer.url".
+
+Example configuration file:
+    {
+        "fields": ["extraction_conflicts"],
+        "tenants": {"acme": ["reviewer.url", "reviewer.name"]}
+    }
+"""
+
+import json
+import logging
+from typing import Dict, List, Optional
+
+logger = logging.getLogger(__name__)
+
+def _redact(value, parts: List[str]) -> int:
+    if isinstance(value, list):
+        return sum(_redact(item, parts) for item in value)
+    if not isinstance(value, dict) or parts[0] not in value:
+        return 0
+    if len(parts) == 1:
+        del value[parts[0]]
+        return 1
+    return _redact(value[parts[0]], parts[1:])
+
+class RedactionPolicy:
+    """Remove fields that must never be exported, globally or for one tenant."""
+
+    def __init__(self, fields: Optional[List[str]] = None, tenants: Optional[Dict[str, List[str]]] = None,
+                 tenant: Optional[str] = None):
+        self.fields = list(fields or [])
+        if tenant and tenants and tenant in tenants:
+            self.fields.extend(tenants[tenant])
+
+    @classmethod
+    def from_file(cls, path: str, tenant: Optional[str] = None, extra_fields: Optional[List[str]] = None) -> 'RedactionPolicy':
+        """Load a policy from a JSON configuration file, keeping the rules of `tenant`."""
+        with open(path, 'r', encoding='utf-8') as f:
+            config = json.load(f)
+        return cls(fields=config.get('fields', []) + list(extra_fields or []),
+                   tenants=config.get('tenants'), tenant=tenant)
+
+    def apply(self, document: dict) -> dict:
+        """Remove the redacted fields from a document in place and return it."""
+        for path in self.fields:
+            _redact(document, path.split('.'))
+        return document
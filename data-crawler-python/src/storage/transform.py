@@ -0,0 +1,90 @@
+"""
+Transform stage applied to documents before they reach the sinks.
+Fields are addressed with dotted paths, e.g. "location.postal_code".
+
+Example configuration file:
+    {
+        "rename": {"overall_rating": "rating"},
+        "drop": ["extraction_conflicts", "reviewer.url"],
+        "convert": {"distance_km": "km_to_miles"},
+        "category_map": {"Restaurant": "General"}
+    }
+"""
+
+import json
+import logging
+from typing import Callable, Dict, Optional
+
+logger = logging.getLogger(__name__)
+
+CONVERTERS: Dict[str, Callable] = {
+    'km_to_miles': lambda v: v * 0.621371,
+    'miles_to_km': lambda v: v / 0.621371,
+    'rating_to_percent': lambda v: v * 20,
+    'to_string': str,
+}
+
+_MISSING = object()
+
+def _get(document: dict, path: str):
+    current = document
+    for part in path.split('.'):
+        if not isinstance(current, dict) or part not in current:
+            return _MISSING
+        current = current[part]
+    return current
+
+def _pop(document: dict, path: str):
+    parts = path.split('.')
+    parent = _get(document, '.'.join(parts[:-1])) if len(parts) > 1 else document
+    if not isinstance(parent, dict):
+        return _MISSING
+    return parent.pop(parts[-1], _MISSING)
+
+def _set(document: dict, path: str, value):
+    parts = path.split('.')
+    current = document
+    for part in parts[:-1]:
+        current = current.setdefault(part, {})
+    current[parts[-1]] = value
+
+class Transform:
+    """Rename, convert, map and drop document fields."""
+
+    def __init__(self, rename: Optional[Dict[str, str]] = None, drop: Optional[list] = None,
+                 convert: Optional[Dict[str, str]] = None, category_map: Optional[Dict[str, str]] = None):
+        self.rename = rename or {}
+        self.drop = drop or []
+        self.convert = convert or {}
+        self.category_map = category_map or {}
+        for path, name in self.convert.items():
+            if name not in CONVERTERS:
+                raise ValueError(f"Unknown converter '{name}' for field '{path}'")
+
+    @classmethod
+    def from_file(cls, path: str) -> 'Transform':
+        """Load a transform from a JSON configuration file."""
+        with open(path, 'r', encoding='utf-8') as f:
+            return cls(**json.load(f))
+
+    def apply(self, document: dict) -> dict:
+        """Apply the transform to a document in place and return it."""
+        for path, name in self.convert.items():
+            value = _get(document, path)
+            if value is not _MISSING and value is not None:
+                _set(document, path, CONVERTERS[name](value))
+
+        if self.category_map:
+            cuisines = _get(document, 'attributes.cuisine_type')
+            if isinstance(cuisines, list):
+                document['attributes']['cuisine_type'] = [self.category_map.get(c, c) for c in cuisines]
+
+        for path in self.drop:
+            _pop(document, path)
+
+        for source, target in self.rename.items():
+            value = _pop(document, source)
+            if value is not _MISSING:
+                _set(document, target, value)
+
+        return document
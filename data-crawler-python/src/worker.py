@@ -0,0 +1,215 @@
+"""
+Worker pool consuming the persistent place job queue.
+
+Usage:
+    python -m src.worker enqueue URL [URL ...]
+    python -m src.worker enqueue --file urls.txt
+    python -m src.worker run --concurrency 3 [--max-browsers 3] [--max-reviews 500] [--drain]
+    python -m src.worker status
+"""
+
+import argparse
+import json
+import logging
+import os
+import sys
+import threading
+import time
+from typing import List, Optional
+
+from src.config.settings import settings
+from src.crawler.blocking import build_block_handler
+from src.crawler.browser_pool import BrowserPool
+from src.crawler.costs import RunCosts
+from src.crawler.errors import classify
+from src.crawler.google_maps_crawler import GoogleMapsScraper
+from src.crawler.job_queue import RedisJobQueue
+from src.crawler.progress import ProgressReporter
+from src.crawler.scheduler import Scheduler
+from src.crawler.shutdown import ShutdownSignal
+from src.crawler.tracing import extracted, setup_tracing, shutdown_tracing
+from src.main import (apply_retention, build_consent, build_fields, build_identity, build_locale,
+                      build_media_downloader, build_places_enricher, build_proxy_pool, build_selectors, build_storage,
+                      build_throttle, build_website_crawler, configure_logging, process_restaurant, scheduler_limits)
+from src.models.job_context import JobContext
+from src.storage.ids import build_id_strategy
+
+logger = logging.getLogger(__name__)
+
+def build_queue() -> RedisJobQueue:
+    """Create the job queue from settings."""
+    return RedisJobQueue(settings.redis_url, name=settings.queue_name, max_attempts=settings.job_max_attempts,
+                         lease_seconds=settings.job_lease)
+
+def read_urls(args) -> List[str]:
+    urls = list(args.urls)
+    if args.file:
+        with open(args.file, 'r', encoding='utf-8') as f:
+            urls.extend(line.strip() for line in f if line.strip() and not line.startswith('#'))
+    return urls
+
+def run_workers(job_queue: RedisJobQueue, concurrency: int, max_browsers: int, drain: bool,
+                max_reviews: Optional[int] = None):
+    """Process queued places with `concurrency` threads sharing one browser pool."""
+    if max_browsers < concurrency:
+        raise ValueError(f"--max-browsers must be at least the concurrency of {concurrency}")
+    job_queue.recover()
+    storage = build_storage()
+    media = build_media_downloader()
+    website = build_website_crawler()
+    places = build_places_enricher()
+    job = JobContext(tenant=settings.tenant, proxy=settings.proxy)
+    proxy_pool = build_proxy_pool()
+    block_handler = build_block_handler(settings.block_handler, settings.captcha_solver_url)
+    progress = ProgressReporter(job, interval=settings.progress_interval)
+    id_strategy = build_id_strategy(settings.id_strategy)
+    throttle = build_throttle()
+    identity = build_identity()
+    locale = build_locale()
+    consent = build_consent()
+    fields = build_fields()
+    selectors = build_selectors()
+    concurrency, rate_limits = scheduler_limits(concurrency)
+    costs = RunCosts(
+        proxy_per_gb=settings.cost_proxy_per_gb,
+        captcha_per_solve=settings.cost_captcha_per_solve,
+        browser_per_hour=settings.cost_browser_per_hour
+    )
+    stop = threading.Event()
+    # Jobs this process is running, kept leased by the heartbeat thread
+    running_jobs = set()
+    running_lock = threading.Lock()
+
+    def heartbeat():
+        while not stop.wait(job_queue.lease_seconds / 3):
+            with running_lock:
+                job_ids = list(running_jobs)
+            for job_id in job_ids:
+                if not job_queue.heartbeat(job_id):
+                    logger.warning(f"Lease of job {job_id} expired, it was requeued")
+            # Take over the jobs of workers that died in other processes
+            job_queue.recover()
+
+    def new_scraper() -> GoogleMapsScraper:
+        return GoogleMapsScraper(
+            debug=settings.headful,
+            devtools=settings.devtools,
+            job=job,
+            progress=progress,
+            throttle=throttle,
+            costs=costs,
+            id_strategy=id_strategy,
+            record_dir=settings.record_dir,
+            replay_from=settings.replay_file,
+            fixture_dir=settings.fixture_dir,
+            replay_fixtures=settings.replay_fixtures,
+            proxy_pool=proxy_pool,
+            block_handler=block_handler,
+            stealth=settings.stealth,
+            identity=identity,
+            locale=locale,
+            consent=consent,
+            fields=fields,
+            selectors=selectors,
+            feed_snapshots=settings.feed_snapshots,
+            photo_size=settings.photo_size,
+            feed_stable_window=settings.feed_stable_window,
+            feed_max_seconds=settings.feed_max_seconds,
+            slow_mo=settings.slow_mo,
+            failure_dir=settings.failure_dir,
+            review_scroll_budget=settings.review_scroll_budget,
+            max_reviews=max_reviews
+        )
+
+    with BrowserPool(new_scraper, size=max_browsers, max_jobs=settings.browser_max_jobs,
+                     max_minutes=settings.browser_max_minutes) as pool, \
+            Scheduler(concurrency, rate_limits, throttle) as scheduler:
+
+        def work():
+            while not stop.is_set():
+                claimed = job_queue.claim()
+                if not claimed:
+                    if drain:
+                        return
+                    continue
+                error = None
+                with running_lock:
+                    running_jobs.add(claimed['id'])
+                try:
+                    with extracted(claimed['trace']), scheduler.slot(claimed['url']), pool.browser() as scraper:
+                        ok = process_restaurant(scraper, storage, claimed['url'], media=media, website=website,
+                                                places=places)
+                        error = scraper.last_error
+                except Exception as e:
+                    ok = False
+                    error = classify(e, claimed['url'])
+                    logger.error(f"Worker error on {claimed['url']}: {str(e)}")
+                finally:
+                    with running_lock:
+                        running_jobs.discard(claimed['id'])
+                if ok:
+                    job_queue.complete(claimed['id'])
+                elif error:
+                    job_queue.fail(claimed['id'], f"{error} on attempt {claimed['attempts']}",
+                                   kind=error.kind, retryable=error.retryable)
+                else:
+                    job_queue.fail(claimed['id'], f"place not saved on attempt {claimed['attempts']}")
+                progress.emit('queue', **job_queue.counts())
+
+        threads = [threading.Thread(target=work, name=f'worker-{i}', daemon=True) for i in range(concurrency)]
+        for thread in threads:
+            thread.start()
+        threading.Thread(target=heartbeat, name='heartbeat', daemon=True).start()
+        # SIGINT/SIGTERM stop claiming jobs; jobs still running after the grace
+        # period stay on the running list and are requeued once their lease expires
+        with ShutdownSignal(stop):
+            for thread in threads:
+                while thread.is_alive() and not stop.is_set():
+                    thread.join(timeout=1)
+            deadline = time.time() + settings.shutdown_grace
+            for thread in threads:
+                thread.join(timeout=max(0, deadline - time.time()))
+            running = sum(1 for thread in threads if thread.is_alive())
+            if running:
+                logger.warning(f"Grace period over, abandoning {running} running jobs")
+    storage.close()
+    apply_retention(storage)
+    os.makedirs(settings.output_dir, exist_ok=True)
+    costs.save(os.path.join(settings.output_dir, f"costs_{job.job_id}.json"),
+               extra={'job_id': job.job_id, 'tenant': job.tenant})
+
+def main():
+    configure_logging()
+    parser = argparse.ArgumentParser(description='Persistent place job queue.')
+    commands = parser.add_subparsers(dest='command', required=True)
+    enqueue = commands.add_parser('enqueue', help='Add place URLs to the queue')
+    enqueue.add_argument('urls', nargs='*', help='Place URLs')
+    enqueue.add_argument('--file', help='File with one place URL per line')
+    enqueue.add_argument('--force', action='store_true', help='Requeue places that already have a job')
+    run = commands.add_parser('run', help='Start workers')
+    run.add_argument('--concurrency', type=int, default=settings.concurrency, help='Places processed at once')
+    run.add_argument('--max-browsers', type=int, default=settings.max_browsers, help='Browsers kept in the pool')
+    run.add_argument('--drain', action='store_true', help='Exit once the queue is empty')
+    run.add_argument('--max-reviews', type=int, default=settings.max_reviews, help='Reviews to load per place')
+    commands.add_parser('status', help='Show queue counts and dead letters')
+    args = parser.parse_args()
+
+    try:
+        if settings.tracing:
+            setup_tracing()
+        job_queue = build_queue()
+        if args.command == 'enqueue':
+            added = [job_id for job_id in (job_queue.enqueue(url, args.force) for url in read_urls(args)) if job_id]
+            logger.info(f"Enqueued {len(added)} jobs")
+        elif args.command == 'run':
+            run_workers(job_queue, args.concurrency, args.max_browsers, args.drain, args.max_reviews)
+        else:
+            print(json.dumps({'counts': job_queue.counts(), 'dead_letters': job_queue.dead_letters()}, indent=2))
+    except Exception as e:
+        logger.error(f"Worker command failed: {str(e)}")
+        sys.exit(1)
+    finally:
+        shutdown_tracing()
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,64 @@
+"""
+Cross-run deduplication: places are matched by CID, or by name within
+50 m, and merged newest value first into one record per place.
+"""
+
+from src.storage.dedupe import DedupeIndex
+from src.storage.idempotency import idempotency_key
+
+CID_URL = 'https://www.google.com/maps/place/Cafe/data=!4m2!3m1!1s0x808f7e:0x1a2b'
+
+def place(**fields) -> dict:
+    return {
+        '_id': 'cafe_94110',
+        'name': 'Cafe',
+        'url': CID_URL,
+        'location': {'type': 'Point', 'coordinates': [-122.4194, 37.7749]},
+        **fields,
+    }
+
+def test_new_place_keeps_its_id(tmp_path):
+    index = DedupeIndex(str(tmp_path / 'index.json'))
+    merged = index.merge(place())
+    assert merged['_id'] == 'cafe_94110'
+    assert merged['first_seen'] == merged['last_seen']
+
+def test_cid_match_reuses_the_known_id_and_merges_newest(tmp_path):
+    index = DedupeIndex(str(tmp_path / 'index.json'))
+    first = index.merge(place(phone='+1 415 555 0100', overall_rating=4.4))
+    merged = index.merge(place(_id='cafe_renamed', name='Cafe & Bar', phone=None, overall_rating=4.6))
+    assert merged['_id'] == 'cafe_94110'
+    assert merged['name'] == 'Cafe & Bar'
+    assert merged['overall_rating'] == 4.6
+    # An empty value does not erase a known one
+    assert merged['phone'] == '+1 415 555 0100'
+    assert merged['first_seen'] == first['first_seen']
+
+def test_fuzzy_match_by_name_within_distance(tmp_path):
+    index = DedupeIndex(str(tmp_path / 'index.json'))
+    index.merge(place(url='https://www.google.com/maps/place/Cafe'))
+    nearby = place(_id='cafe_2', name='CAFE!', url='https://www.google.com/maps/place/Cafe+SF',
+                   location={'type': 'Point', 'coordinates': [-122.4195, 37.7750]})
+    assert index.match(nearby) == 'cafe_94110'
+
+def test_fuzzy_match_rejects_distant_branches(tmp_path):
+    index = DedupeIndex(str(tmp_path / 'index.json'))
+    index.merge(place(url='https://www.google.com/maps/place/Cafe'))
+    branch = place(_id='cafe_2', url='https://www.google.com/maps/place/Cafe+Oakland',
+                   location={'type': 'Point', 'coordinates': [-122.2711, 37.8044]})
+    assert index.match(branch) is None
+    assert index.merge(branch)['_id'] == 'cafe_2'
+
+def test_index_survives_a_restart(tmp_path):
+    path = str(tmp_path / 'index.json')
+    index = DedupeIndex(path)
+    index.merge(place())
+    index.save()
+    assert DedupeIndex(path).match(place(_id='other')) == 'cafe_94110'
+
+def test_recrawl_of_an_unchanged_place_keeps_its_idempotency_key(tmp_path):
+    index = DedupeIndex(str(tmp_path / 'index.json'))
+    first = index.merge(place(overall_rating=4.5))
+    again = index.merge(place(overall_rating=4.5))
+    assert again['last_seen'] >= first['last_seen']
+    assert idempotency_key(again) == idempotency_key(first)
@@ -0,0 +1,59 @@
+"""
+Idempotent saves of the file sink: a retried job must not write a place or
+its reviews again when only the run metadata changed.
+"""
+
+from src.storage.file_storage import FileStorage
+
+def restaurant(job_id: str, scraped_at: str) -> dict:
+    return {
+        '_id': 'cafe_94110',
+        'name': 'Cafe',
+        'url': 'https://www.google.com/maps/place/Cafe/data=!4m2!3m1!1s0x808f7e:0x1a2b',
+        'overall_rating': 4.5,
+        'scraped_at': scraped_at,
+        'job': {'job_id': job_id},
+    }
+
+def reviews(job_id: str, scraped_at: str) -> list:
+    return [
+        {'_id': 'cafe_94110_review_1', 'text': 'Great coffee', 'rating': 5, 'job_id': job_id, 'scraped_at': scraped_at},
+        {'_id': 'cafe_94110_review_2', 'text': 'Slow service', 'rating': 3, 'job_id': job_id, 'scraped_at': scraped_at},
+    ]
+
+def test_retried_restaurant_save_is_skipped(tmp_path):
+    storage = FileStorage(str(tmp_path))
+    first = storage.upsert_restaurant(restaurant('job-1', '2024-05-01T10:00:00'))
+    retried = storage.upsert_restaurant(restaurant('job-2', '2024-05-01T10:05:00'))
+    assert retried == first
+    assert len(list((tmp_path / 'restaurants').glob('*.json'))) == 1
+
+def test_retried_reviews_save_is_skipped(tmp_path):
+    storage = FileStorage(str(tmp_path))
+    first = storage.upsert_reviews('cafe_94110', reviews('job-1', '2024-05-01T10:00:00'))
+    retried = storage.upsert_reviews('cafe_94110', reviews('job-2', '2024-05-01T10:05:00'))
+    assert retried == first
+    assert len(list((tmp_path / 'reviews').glob('*.json'))) == 1
+
+def test_retried_save_survives_a_restart(tmp_path):
+    first = FileStorage(str(tmp_path)).upsert_reviews('cafe_94110', reviews('job-1', '2024-05-01T10:00:00'))
+    retried = FileStorage(str(tmp_path)).upsert_reviews('cafe_94110', reviews('job-2', '2024-05-01T10:05:00'))
+    assert retried == first
+
+def test_recrawl_with_a_new_popular_times_capture_is_skipped(tmp_path):
+    storage = FileStorage(str(tmp_path))
+    histogram = {'Monday': [{'hour': 12, 'busyness': 80}]}
+    first = restaurant('job-1', '2024-05-01T10:00:00')
+    first['popular_times'] = {'captured_at': '2024-05-01T10:00:00+00:00', 'histogram': histogram}
+    recrawl = restaurant('job-2', '2024-05-08T10:00:00')
+    recrawl['popular_times'] = {'captured_at': '2024-05-08T10:00:00+00:00', 'histogram': histogram}
+    saved = storage.upsert_restaurant(first)
+    assert storage.upsert_restaurant(recrawl) == saved
+
+def test_changed_reviews_are_saved(tmp_path):
+    storage = FileStorage(str(tmp_path))
+    changed = reviews('job-2', '2024-05-01T10:05:00')
+    changed[1]['text'] = 'Slow service, but worth it'
+    storage.upsert_reviews('cafe_94110', reviews('job-1', '2024-05-01T10:00:00'))
+    storage.upsert_reviews('cafe_94110', changed)
+    assert len(storage._written) == 2
@@ -0,0 +1,52 @@
+"""
+Regression tests replaying saved Maps pages.
+Capture fixtures with CRAWLER_FIXTURE_DIR=tests/testdata on a normal run;
+every saved place and search is then re-extracted from its HTML and compared
+field by field with its expected.json. Fixtures marked synthetic in their
+meta.json are hand-written pages, see tests/testdata/README.md.
+Set CRAWLER_UPDATE_FIXTURES=true to rewrite expected.json from the current
+extraction instead.
+"""
+
+import json
+import os
+from pathlib import Path
+
+import pytest
+
+from src.crawler.fixtures import list_fixtures, save_expected
+from src.crawler.google_maps_crawler import GoogleMapsScraper
+from src.storage.idempotency import strip_volatile
+
+FIXTURE_DIR = Path(__file__).parent / "testdata"
+FIXTURES = [f for f in list_fixtures(str(FIXTURE_DIR)) if 'expected' in f]
+UPDATE = os.getenv('CRAWLER_UPDATE_FIXTURES', 'false').lower() == 'true'
+
+def normalize(value):
+    """Serialize like the saved expectations and drop the fields that differ between runs of the same page."""
+    return strip_volatile(json.loads(json.dumps(value, default=str)))
+
+@pytest.fixture(scope="module")
+def scraper():
+    with GoogleMapsScraper(replay_fixtures=str(FIXTURE_DIR), feed_stable_window=0) as scraper:
+        yield scraper
+
+@pytest.mark.skipif(not FIXTURES, reason="no fixtures captured in tests/testdata")
+@pytest.mark.parametrize("fixture", FIXTURES, ids=[Path(f['path']).name for f in FIXTURES])
+def test_fixture_extraction(scraper, fixture):
+    expected = fixture['expected']
+    if 'search' in fixture['kinds'] and 'place' not in fixture['kinds']:
+        cards = list(scraper.iter_search_cards(fixture['url'], max_results=len(expected['cards'])))
+        if UPDATE:
+            save_expected(str(FIXTURE_DIR), fixture['url'], {'cards': cards})
+            return
+        assert normalize(cards) == normalize(expected['cards'])
+        return
+
+    result = scraper.get_account(fixture['url'])
+    if UPDATE:
+        save_expected(str(FIXTURE_DIR), fixture['url'], {'restaurant': result['restaurant']})
+        return
+    actual, expected = normalize(result['restaurant']), normalize(expected['restaurant'])
+    for field in sorted(set(actual) | set(expected)):
+        assert actual.get(field) == expected.get(field), f"{field} differs"
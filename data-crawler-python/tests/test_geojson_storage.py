@@ -0,0 +1,25 @@
+"""
+GeoJSON sink: places without coordinates are left off the map on purpose
+and must still count as saved, so a GeoJSON-only crawl does not report
+and retry them as failures.
+"""
+
+import json
+
+from src.storage.fanout import FanOutStorage
+from src.storage.geojson_storage import GeoJsonStorage
+
+def test_places_without_coordinates_count_as_saved(tmp_path):
+    sink = GeoJsonStorage(base_dir=str(tmp_path))
+    storage = FanOutStorage({'geojson': sink})
+
+    mapped = storage.upsert_restaurant({'_id': 'a', 'name': 'A', 'location': {'coordinates': [-122.41, 37.76]}})
+    unmapped = storage.upsert_restaurant({'_id': 'b', 'name': 'B', 'location': {}})
+
+    assert mapped == {'geojson': 'a'}
+    assert unmapped == {'geojson': 'b'}
+    assert any(unmapped.values())
+
+    sink.close()
+    collection = json.loads(sink.restaurants_file.read_text(encoding='utf-8'))
+    assert [feature['id'] for feature in collection['features']] == ['a']
@@ -0,0 +1,51 @@
+"""
+Opening hours parsing: Maps' hours text by day becomes intervals in
+minutes since midnight, with overnight intervals running past 1440.
+"""
+
+from src.enrichment.hours import MINUTES_PER_DAY, day_index, is_open, parse_day, parse_opening_hours, parse_range
+
+def test_day_index_accepts_abbreviations():
+    assert day_index("Monday") == 0
+    assert day_index("Tue") == 1
+    assert day_index("sunday") == 6
+    assert day_index("Holiday") is None
+
+def test_range_with_meridiems():
+    assert parse_range("11:30 AM–2 PM") == {'open': 690, 'close': 840, 'overnight': False}
+
+def test_range_shares_the_closing_meridiem():
+    assert parse_range("5–10 PM") == {'open': 1020, 'close': 1320, 'overnight': False}
+    # Opening at 11 PM would be after closing, so it is 11 AM
+    assert parse_range("11–3 PM") == {'open': 660, 'close': 900, 'overnight': False}
+
+def test_24_hour_range_over_midnight():
+    assert parse_range("17:00 to 01:00") == {'open': 1020, 'close': 1500, 'overnight': True}
+
+def test_range_until_midnight_is_not_overnight():
+    assert parse_range("6 PM–12 AM") == {'open': 1080, 'close': MINUTES_PER_DAY, 'overnight': False}
+
+def test_split_day_has_two_intervals():
+    intervals = parse_day(2, "11 AM–3 PM, 5–10 PM")
+    assert [(i['open_time'], i['close_time']) for i in intervals] == [('11:00', '15:00'), ('17:00', '22:00')]
+    assert all(i['day'] == 2 and i['raw'] == "11 AM–3 PM, 5–10 PM" for i in intervals)
+
+def test_closed_and_all_day():
+    assert parse_day(1, "Closed") == [{'day': 1, 'closed': True, 'raw': 'Closed'}]
+    all_day = parse_day(5, "Open 24 hours")
+    assert len(all_day) == 1
+    assert (all_day[0]['open'], all_day[0]['close'], all_day[0]['open_24h']) == (0, MINUTES_PER_DAY, True)
+
+def test_narrow_spaces_are_normalized():
+    assert parse_range("11\u202fAM\u00a0–\u00a010\u202fPM") == {'open': 660, 'close': 1320, 'overnight': False}
+
+def test_rows_are_ordered_by_weekday():
+    hours = parse_opening_hours({'Sunday': '10 AM–9 PM', 'Monday': '11 AM–10 PM', 'Holiday': 'Closed'})
+    assert [h['day'] for h in hours] == [0, 6]
+
+def test_is_open_counts_the_previous_nights_interval():
+    hours = parse_opening_hours({'Friday': '6 PM–2 AM'})
+    assert is_open(hours, 4, 19 * 60) is True
+    assert is_open(hours, 5, 60) is True
+    assert is_open(hours, 5, 3 * 60) is False
+    assert is_open([], 0, 0) is None
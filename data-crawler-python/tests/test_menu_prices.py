@@ -0,0 +1,81 @@
+"""
+Menu price statistics on the crawl path: the items scraped from a place's
+Menu tab end up as the saved restaurant's `menu_prices`.
+"""
+
+from src.config.settings import settings
+from src.crawler.fields import FieldSelection
+from src.main import process_restaurant
+from src.models.job_context import JobContext
+
+URL = 'https://www.google.com/maps/place/Mission+Street+Noodle+House/data=!4m6!3m5!1s0x808f7e3d9e8b3b9f:0x1a2b3c4d5e6f7081'
+
+MENU = {
+    'items': [
+        {'section': 'Noodles', 'name': 'Beef pho', 'price': '$14.50', 'description': None},
+        {'section': 'Noodles', 'name': 'Dan dan noodles', 'price': '$1,016.00', 'description': 'Chef\'s table'},
+        {'section': 'Starters', 'name': 'Spring rolls', 'price': '$8', 'description': None},
+    ],
+    'images': [],
+}
+
+class MenuScraper:
+    def __init__(self):
+        self.job = JobContext()
+        self.fields = FieldSelection()
+        self.last_error = None
+
+    def get_account(self, url):
+        return {'restaurant': {'_id': 'mission-street-noodle-house', 'name': 'Mission Street Noodle House',
+                               'url': url, 'review_count': 0}, 'reviews': []}
+
+    def get_menu(self, url):
+        return {'items': [dict(item) for item in MENU['items']], 'images': list(MENU['images'])}
+
+    def capture_failure(self, url, error):
+        pass
+
+class MemoryStorage:
+    sinks = {'memory': None}
+
+    def __init__(self):
+        self.restaurants = []
+
+    def known_review_ids(self, url):
+        return set()
+
+    def popular_times_history(self, url):
+        return []
+
+    def upsert_restaurant(self, restaurant):
+        self.restaurants.append(restaurant)
+        return {'memory': restaurant['_id']}
+
+    def upsert_reviews(self, restaurant_id, reviews):
+        return {'memory': len(reviews)}
+
+def test_scraped_menu_is_saved_with_price_statistics(monkeypatch):
+    monkeypatch.setattr(settings, 'scrape_menus', True)
+    monkeypatch.setattr(settings, 'incremental_reviews', False)
+    storage = MemoryStorage()
+
+    assert process_restaurant(MenuScraper(), storage, URL)
+
+    saved = storage.restaurants[0]
+    assert [item['name'] for item in saved['menu']] == ['Beef pho', 'Dan dan noodles', 'Spring rolls']
+    assert saved['menu_prices'] == {
+        'min': 8.0,
+        'median': 14.5,
+        'max': 1016.0,
+        'per_person': 21.75,
+        'currency': 'USD',
+        'item_count': 3,
+    }
+
+def test_menu_is_not_scraped_unless_enabled(monkeypatch):
+    monkeypatch.setattr(settings, 'scrape_menus', False)
+    monkeypatch.setattr(settings, 'incremental_reviews', False)
+    storage = MemoryStorage()
+
+    assert process_restaurant(MenuScraper(), storage, URL)
+    assert 'menu_prices' not in storage.restaurants[0]
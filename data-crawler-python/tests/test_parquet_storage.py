@@ -0,0 +1,45 @@
+"""
+Parquet sink buffering: place jobs run concurrently, so restaurants and
+reviews of different places interleave; every place's reviews must still
+land in its row.
+"""
+
+import pyarrow.parquet as pq
+
+from src.storage import parquet_storage
+from src.storage.parquet_storage import ParquetStorage
+
+def place(name):
+    return {'_id': name, 'name': name.title(), 'url': f'https://www.google.com/maps/place/{name}'}
+
+def review(restaurant_id, number):
+    return {'_id': f'{restaurant_id}_review_{number}', 'text': 'Great noodles', 'rating': 5}
+
+def rows(storage):
+    storage.close()
+    return {row['_id']: row for row in pq.read_table(str(storage.restaurants_file)).to_pylist()}
+
+def test_interleaved_jobs_keep_their_reviews(tmp_path, monkeypatch):
+    monkeypatch.setattr(parquet_storage, 'ROW_GROUP_SIZE', 1)
+    storage = ParquetStorage(base_dir=str(tmp_path))
+
+    storage.upsert_restaurant(place('a'))
+    storage.upsert_restaurant(place('b'))
+    assert storage.upsert_reviews('a', [review('a', 1), review('a', 2)]) == 2
+    assert storage.upsert_reviews('b', [review('b', 1)]) == 1
+
+    saved = rows(storage)
+    assert [r['id'] for r in saved['a']['reviews']] == ['a_review_1', 'a_review_2']
+    assert [r['id'] for r in saved['b']['reviews']] == ['b_review_1']
+
+def test_places_without_reviews_are_flushed_once_out_of_the_review_window(tmp_path, monkeypatch):
+    monkeypatch.setattr(parquet_storage, 'ROW_GROUP_SIZE', 2)
+    monkeypatch.setattr(parquet_storage, 'REVIEW_WINDOW', 2)
+    storage = ParquetStorage(base_dir=str(tmp_path))
+
+    for name in ['a', 'b', 'c', 'd']:
+        storage.upsert_restaurant(place(name))
+
+    # a and b can no longer get reviews and were written, c and d are still buffered
+    assert list(storage.pending) == ['c', 'd']
+    assert set(rows(storage)) == {'a', 'b', 'c', 'd'}
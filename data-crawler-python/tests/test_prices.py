@@ -0,0 +1,68 @@
+"""
+Price range normalization: amounts are read whatever their locale's
+decimal mark and bucketed into 1-4 levels by their value in US dollars.
+"""
+
+import pytest
+
+from src.enrichment.menu_prices import parse_price
+from src.enrichment.prices import is_price_text, normalize_price_range, parse_amount
+
+@pytest.mark.parametrize("text, amount", [
+    ("12", 12.0),
+    ("12.50", 12.5),
+    ("12,50", 12.5),
+    ("1,5", 1.5),
+    ("1,200", 1200.0),
+    ("1.000", 1000.0),
+    ("1 000", 1000.0),
+    ("1.000,50", 1000.5),
+    ("1,000.50", 1000.5),
+    ("12.5000", None),
+    ("", None),
+])
+def test_parse_amount(text, amount):
+    assert parse_amount(text) == amount
+
+def test_euro_amount_with_decimal_comma():
+    price = normalize_price_range("€ 1.000,50")
+    assert (price['min'], price['max'], price['currency']) == (1000.5, 1000.5, 'EUR')
+
+def test_euro_cents_are_not_read_as_hundreds():
+    price = normalize_price_range("€12,50")
+    assert price['min'] == 12.5
+    assert price['level'] == 1
+
+def test_yen_range_with_thousands_separators():
+    price = normalize_price_range("¥1,000–2,000")
+    assert (price['min'], price['max'], price['currency']) == (1000.0, 2000.0, 'JPY')
+    assert price['level'] == 1
+
+def test_dollar_range_uses_the_country_for_the_currency():
+    assert normalize_price_range("$20–30")['currency'] == 'USD'
+    assert normalize_price_range("$20–30", country='Canada')['currency'] == 'CAD'
+
+def test_symbol_count_is_the_level():
+    price = normalize_price_range("$$$")
+    assert price == {'level': 3, 'min': None, 'max': None, 'currency': 'USD', 'raw': '$$$'}
+
+def test_open_ended_range_has_no_maximum():
+    price = normalize_price_range("£50+")
+    assert (price['min'], price['max'], price['level']) == (50.0, None, 4)
+
+def test_text_without_price():
+    assert normalize_price_range("Italian") is None
+    assert normalize_price_range(None) is None
+    assert not is_price_text("Italian")
+    assert is_price_text("€10–20")
+
+@pytest.mark.parametrize("text, price", [
+    ("$12.50", 12.5),
+    ("12,50 €", 12.5),
+    ("¥1,200", 1200.0),
+    ("€1.234,50", 1234.5),
+    (9, 9.0),
+    ("Market price", None),
+])
+def test_menu_prices_share_the_amount_parser(text, price):
+    assert parse_price(text) == price
@@ -0,0 +1,21 @@
+"""
+Sparse search rephrasing: the alternate phrasings search the area of the
+original search URL.
+"""
+
+from src.crawler.rephrase import phrasing_url, search_area
+
+def test_area_named_in_the_query():
+    url = 'https://www.google.com/maps/search/ramen+in+Oakland,+CA/@37.80,-122.27,14z'
+    assert search_area(url) == 'Oakland, CA'
+    assert search_area('https://www.google.com/maps/search/tacos%20near%20Mission%20District') == 'Mission District'
+
+def test_area_from_the_viewport():
+    assert search_area('https://www.google.com/maps/search/restaurants/@48.8566,2.3522,15z') == '48.8566,2.3522'
+
+def test_no_area():
+    assert search_area('https://www.google.com/maps/search/restaurants') is None
+    assert search_area(None) is None
+
+def test_phrasing_url_names_the_area():
+    assert phrasing_url('food in {area}', 'Oakland, CA') == 'https://www.google.com/maps/search/food+in+Oakland%2C+CA'
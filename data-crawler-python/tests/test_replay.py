@@ -0,0 +1,99 @@
+"""
+Browser session record and replay: a session recorded from a driver is
+answered call for call by the replay driver, including the elements it
+returned and the exceptions it raised, without the original driver.
+"""
+
+import json
+
+import pytest
+from selenium.common.exceptions import NoSuchElementException
+
+from src.crawler.replay import ReplayMissError, record, replay
+
+class FakeElement:
+    def __init__(self, text, href=None, children=None):
+        self.text = text
+        self.href = href
+        self.children = children or {}
+
+    def get_attribute(self, name):
+        return self.href if name == 'href' else None
+
+    def find_element(self, by, value):
+        if value not in self.children:
+            raise NoSuchElementException(f"no {value}")
+        return self.children[value]
+
+    def find_elements(self, by, value):
+        return [self.children[value]] if value in self.children else []
+
+class FakeDriver:
+    def __init__(self):
+        self.current_url = None
+        self.title = 'Google Maps'
+        self.quit_called = False
+        rating = FakeElement('4.6')
+        self.cards = [
+            FakeElement('Mission Street Noodle House', 'https://www.google.com/maps/place/a',
+                        {'span.MW4etd': rating}),
+            FakeElement('Pho 24th Street', 'https://www.google.com/maps/place/b'),
+        ]
+
+    def get(self, url):
+        self.current_url = url
+
+    def find_elements(self, by, value):
+        return list(self.cards)
+
+    def execute_script(self, script, *args):
+        if args:
+            return args[0].text
+        return 2
+
+    def quit(self):
+        self.quit_called = True
+
+def run_session(driver):
+    """Make the calls a search scrape makes and return what it saw."""
+    driver.get('https://www.google.com/maps/search/noodles')
+    seen = {'title': driver.title, 'count': driver.execute_script('return document.links.length')}
+    cards = driver.find_elements('css selector', 'div[role="feed"] > div')
+    seen['cards'] = []
+    for card in cards:
+        try:
+            rating = card.find_element('css selector', 'span.MW4etd').text
+        except NoSuchElementException:
+            rating = None
+        seen['cards'].append({'name': card.text, 'url': card.get_attribute('href'), 'rating': rating,
+                              'script_text': driver.execute_script('return arguments[0].innerText', card)})
+    driver.quit()
+    return seen
+
+def test_replay_answers_the_recorded_session(tmp_path):
+    cassette = tmp_path / 'session.json'
+    live = FakeDriver()
+    recorded = run_session(record(live, str(cassette)))
+    assert live.quit_called
+    assert json.loads(cassette.read_text(encoding='utf-8'))['entries']
+
+    assert run_session(replay(str(cassette))) == recorded
+    assert recorded['cards'][0]['rating'] == '4.6'
+    assert recorded['cards'][1]['rating'] is None
+
+def test_replay_reraises_recorded_exceptions(tmp_path):
+    cassette = tmp_path / 'session.json'
+    run_session(record(FakeDriver(), str(cassette)))
+
+    driver = replay(str(cassette))
+    driver.get('https://www.google.com/maps/search/noodles')
+    second = driver.find_elements('css selector', 'div[role="feed"] > div')[1]
+    with pytest.raises(NoSuchElementException):
+        second.find_element('css selector', 'span.MW4etd')
+
+def test_unrecorded_calls_fail_loudly(tmp_path):
+    cassette = tmp_path / 'session.json'
+    run_session(record(FakeDriver(), str(cassette)))
+
+    with pytest.raises(ReplayMissError):
+        replay(str(cassette)).get('https://www.google.com/maps/search/ramen')
@@ -0,0 +1,49 @@
+"""
+Output retention: CRAWLER_KEEP_DAYS and CRAWLER_KEEP_RUNS prune the
+failure artifacts of old crawl jobs as well as the file sink's output,
+whichever sinks are configured.
+"""
+
+import os
+from datetime import datetime, timedelta, timezone
+
+from src.config.settings import settings
+from src.crawler.artifacts import prune_failures
+from src.main import apply_retention
+from src.storage.fanout import FanOutStorage
+
+def failure(directory, job_id, days_ago, name='cid_1'):
+    captured_at = datetime.now(timezone.utc) - timedelta(days=days_ago)
+    folder = directory / job_id / f"{captured_at.strftime('%Y%m%dT%H%M%S')}_{name}"
+    folder.mkdir(parents=True)
+    (folder / 'page.html').write_text('<html></html>', encoding='utf-8')
+    (folder / 'failure.json').write_text('{}', encoding='utf-8')
+    return folder
+
+def test_keep_days_removes_old_failures(tmp_path):
+    old = failure(tmp_path, 'job-old', days_ago=10)
+    recent = failure(tmp_path, 'job-new', days_ago=1)
+
+    assert prune_failures(str(tmp_path), keep_days=7) == 1
+    assert not old.exists()
+    assert not (tmp_path / 'job-old').exists()
+    assert recent.exists()
+
+def test_keep_runs_keeps_the_newest_jobs(tmp_path):
+    failure(tmp_path, 'job-1', days_ago=3)
+    failure(tmp_path, 'job-2', days_ago=2, name='cid_2')
+    failure(tmp_path, 'job-2', days_ago=2.5, name='cid_3')
+    failure(tmp_path, 'job-3', days_ago=1)
+
+    assert prune_failures(str(tmp_path), keep_runs=2) == 1
+    assert sorted(os.listdir(tmp_path)) == ['job-2', 'job-3']
+    assert len(os.listdir(tmp_path / 'job-2')) == 2
+
+def test_failures_are_pruned_without_a_file_sink(tmp_path, monkeypatch):
+    monkeypatch.setattr(settings, 'keep_days', 7)
+    monkeypatch.setattr(settings, 'keep_runs', None)
+    monkeypatch.setattr(settings, 'failure_dir', str(tmp_path))
+    old = failure(tmp_path, 'job-old', days_ago=30)
+
+    apply_retention(FanOutStorage({}))
+    assert not old.exists()
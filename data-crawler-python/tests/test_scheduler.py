@@ -0,0 +1,73 @@
+"""
+Scheduler lifecycle: finished jobs are not kept around, and after a stop,
+jobs still running past the grace period must not hold up the exit of
+the process.
+"""
+
+import subprocess
+import sys
+import threading
+import time
+from pathlib import Path
+
+from src.crawler.scheduler import Scheduler
+
+ROOT = Path(__file__).parent.parent
+
+def test_jobs_run_and_close_waits_for_them():
+    done = []
+    with Scheduler(concurrency=2) as scheduler:
+        for url in ['https://www.google.com/maps/place/a', 'https://www.google.com/maps/place/b']:
+            scheduler.submit(done.append, url)
+    assert sorted(done) == ['https://www.google.com/maps/place/a', 'https://www.google.com/maps/place/b']
+
+def test_stop_cancels_queued_jobs():
+    release, started, done = threading.Event(), threading.Event(), []
+    def job(url):
+        started.set()
+        release.wait(5)
+        done.append(url)
+    with Scheduler(concurrency=1) as scheduler:
+        scheduler.submit(job, 'https://www.google.com/maps/place/running')
+        queued = scheduler.submit(job, 'https://www.google.com/maps/place/queued')
+        started.wait(5)
+        scheduler.stop(grace=5)
+        release.set()
+    assert queued.cancelled()
+    assert done == ['https://www.google.com/maps/place/running']
+    assert scheduler.submit(job, 'https://www.google.com/maps/place/late') is None
+
+def test_close_after_stop_is_bounded_by_the_grace_period():
+    scheduler = Scheduler(concurrency=1)
+    scheduler.submit(lambda url: threading.Event().wait(), 'https://www.google.com/maps/place/stuck')
+    time.sleep(0.1)
+    started = time.time()
+    scheduler.stop(grace=0.5)
+    scheduler.close()
+    assert time.time() - started < 3
+
+def test_process_exits_with_a_stuck_job():
+    script = (
+        "import threading, time\n"
+        "from src.crawler.scheduler import Scheduler\n"
+        "scheduler = Scheduler(concurrency=1)\n"
+        "scheduler.submit(lambda url: threading.Event().wait(), 'https://www.google.com/maps/place/stuck')\n"
+        "time.sleep(0.1)\n"
+        "scheduler.stop(grace=0.5)\n"
+        "scheduler.close()\n"
+    )
+    started = time.time()
+    result = subprocess.run([sys.executable, '-c', script], cwd=ROOT, timeout=30)
+    assert result.returncode == 0
+    assert time.time() - started < 10
+
+def test_finished_jobs_are_forgotten():
+    release = threading.Event()
+    with Scheduler(concurrency=1) as scheduler:
+        for i in range(5):
+            scheduler.submit(lambda url: None, f'https://www.google.com/maps/place/{i}')
+        blocked = scheduler.submit(lambda url: release.wait(5), 'https://www.google.com/maps/place/blocked')
+        time.sleep(0.2)
+        assert scheduler.futures == [blocked]
+        release.set()
+    assert scheduler.futures == []
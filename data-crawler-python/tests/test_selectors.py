@@ -0,0 +1,37 @@
+"""
+Selector registry: fields fall back through their selectors in order, and
+a field none of them matches is reported as that field missing.
+"""
+
+import pytest
+
+from src.crawler.errors import SelectorMissingError, classify
+from src.crawler.selectors import SelectorRegistry
+
+class Root:
+    """Driver stand-in whose CSS lookups find the selectors it was given."""
+
+    def __init__(self, present):
+        self.present = present
+
+    def find_elements(self, by, selector):
+        return [selector] if selector in self.present else []
+
+def registry():
+    return SelectorRegistry({'place_name': ['h1.new', 'h1.old'], 'phone': ['button.phone']})
+
+def test_falls_back_to_later_selectors():
+    selectors = registry()
+    assert selectors.find_element(Root({'h1.old'}), 'place_name') == 'h1.old'
+    metrics = selectors.metrics()['place_name']
+    assert (metrics['found'], metrics['fallback']) == (1, 1)
+
+def test_missing_field_names_the_field_and_page():
+    selectors = registry()
+    url = 'https://www.google.com/maps/place/Cafe'
+    with pytest.raises(SelectorMissingError) as raised:
+        selectors.find_element(Root(set()), 'phone', url)
+    error = classify(raised.value, url)
+    assert error.to_dict() == {'type': 'selector_missing', 'message': "No element for 'phone'", 'retryable': False,
+                               'url': url, 'field': 'phone'}
+    assert selectors.metrics()['phone']['success_rate'] == 0